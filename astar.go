@@ -1,5 +1,9 @@
 package pathing
 
+import (
+	"math"
+)
+
 // AStar implements an A* search pathfinding algorithm.
 // You must use NewAStar() function to obtain an instance of this type.
 //
@@ -12,6 +16,56 @@ type AStar struct {
 	frontier *minheap[astarCoord]
 	costmap  *coordMap
 	pathmap  *coordMap
+
+	// These are allocated lazily by BuildLongPath.
+	longCostmap *coordMap
+	longPathmap *coordMap
+
+	moveCosts
+
+	clearance *ClearanceMap
+	agentSize uint8
+
+	maxExpanded int
+
+	state astarSearch
+}
+
+// astarSearch is a resumable A* search state.
+// See AStar.Start and AStar.Step.
+type astarSearch struct {
+	grid      *Grid
+	layer     GridLayer
+	clearance *ClearanceMap
+
+	origin GridCoord
+	start  GridCoord
+	goal   GridCoord
+
+	// goals are used for the multi-goal searches (if multiGoal is set).
+	// The goal field is ignored in this case.
+	// They're stored by value to avoid the heap allocations.
+	goals     pathGoals
+	multiGoal bool
+
+	maxWeight int32
+	costmap   *coordMap
+	pathmap   *coordMap
+
+	// The search never leaves this grid area.
+	// It's the entire grid unless the search is bounded (see buildAreaPath).
+	areaX    uint
+	areaY    uint
+	areaCols uint
+	areaRows uint
+
+	shortestDist  int
+	fallbackCoord GridCoord
+	fallbackCost  int32
+	numExpanded   int
+
+	found bool
+	done  bool
 }
 
 type AStarConfig struct {
@@ -22,8 +76,65 @@ type AStarConfig struct {
 	// if the grids you're going operate on are small.
 	NumCols uint
 	NumRows uint
+
+	// Diagonal enables the 8-directional movement mode.
+	// The constructed paths may contain diagonal steps (like DirDownRight).
+	// An octile distance heuristic is used in this mode.
+	//
+	// A diagonal step is only possible if both adjacent axial cells are passable.
+	// See CornerCutting option to relax this rule.
+	Diagonal bool
+
+	// CornerCutting allows a diagonal step when only one of the
+	// adjacent axial cells is passable.
+	// Squeezing between two impassable cells is never allowed.
+	//
+	// This option is only meaningful in the Diagonal mode.
+	CornerCutting bool
+
+	// DiagonalCost is a diagonal step cost multiplier.
+	// The cell traversal cost is multiplied by this value
+	// when that cell is entered diagonally.
+	//
+	// If left unset (0), a default value of 1.4 (roughly a sqrt(2)) is used.
+	// Values below 1 are treated as 1.
+	// The multiplier precision is limited to a single decimal digit.
+	//
+	// This option is only meaningful in the Diagonal mode.
+	DiagonalCost float64
+
+	// Clearance enables the agent size aware pathfinding.
+	// When BuildPath is called with the same Grid and GridLayer
+	// that were used to create this map, only the cells
+	// where the AgentSize square fits are visited.
+	// For any other Grid and GridLayer combination, this option is ignored.
+	//
+	// It's the caller's responsibility to keep the map up-to-date.
+	// See ClearanceMap.Update.
+	Clearance *ClearanceMap
+
+	// AgentSize is a side of the square (in cells) the agent occupies.
+	// The path coordinates are the agent's top-left cell positions.
+	// Only the top-left cell cost is used to compute the path cost.
+	//
+	// An agent bigger than 1 cell can never cut the corners
+	// in the Diagonal mode: both adjacent axial cells should fit the agent.
+	//
+	// If left unset (0), a value of 1 is used.
+	// This option is only meaningful if Clearance is set.
+	AgentSize uint8
+
+	// MaxExpandedNodes is a hard limit for the number of the expanded nodes per search.
+	// When it's reached, the search stops and a partial result is returned.
+	// This is useful to put an upper bound on the BuildLongPath costs.
+	//
+	// If left unset (0), the number of nodes is not limited.
+	MaxExpandedNodes int
 }
 
+// astarCostScale is a cost multiplier used in the diagonal mode.
+const astarCostScale = 10
+
 type astarCoord struct {
 	Coord  GridCoord
 	Weight int32
@@ -52,6 +163,19 @@ func NewAStar(config AStarConfig) *AStar {
 		frontier: newMinheap[astarCoord](32),
 		pathmap:  newCoordMap(coordMapCols, coordMapRows),
 		costmap:  newCoordMap(coordMapCols, coordMapRows),
+
+		moveCosts: makeMoveCosts(config.Diagonal, config.DiagonalCost, config.CornerCutting),
+
+		maxExpanded: math.MaxInt,
+	}
+
+	if config.MaxExpandedNodes > 0 {
+		astar.maxExpanded = config.MaxExpandedNodes
+	}
+
+	if config.Clearance != nil && config.AgentSize > 1 {
+		astar.clearance = config.Clearance
+		astar.agentSize = config.AgentSize
 	}
 
 	return astar
@@ -73,51 +197,196 @@ func (astar *AStar) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildPat
 	localStart := from.Sub(origin)
 	localGoal := to.Sub(origin)
 
-	frontier := astar.frontier
-	frontier.Reset()
+	astar.pathmap.Reset()
+	astar.costmap.Reset()
 
-	pathmap := astar.pathmap
-	pathmap.Reset()
+	var finish GridCoord
+	var cost int32
+	var found bool
+	if astar.numNeighbors == 4 && astar.clearance.boundTo(g, l) == nil && !g.wideTags {
+		finish, cost, found = astar.searchPlain(g, l, origin, localStart, localGoal)
+	} else {
+		finish, cost, found = astar.search(g, l, origin, localStart, localGoal, nil, int32(gridPathLimit(astar.numNeighbors)), astar.costmap, astar.pathmap)
+	}
+	result.Steps = constructPath(localStart, finish, astar.pathmap)
+	result.Finish = finish.Add(origin)
+	result.Cost = astar.unscaleCost(uint32(cost))
+	result.Partial = !found
 
-	costmap := astar.costmap
-	costmap.Reset()
+	return result
+}
 
+// BuildLongPath is like BuildPath, but it's not limited by the GridPath max length.
+// It will search the entire grid if needed.
+//
+// The path steps are written to dst; its previous contents are discarded.
+// Re-use the same dst object to avoid the extra allocations.
+//
+// The first BuildLongPath call (or a call with a bigger grid) allocates
+// the working space that is big enough to hold the entire grid.
+func (astar *AStar) BuildLongPath(g *Grid, from, to GridCoord, l GridLayer, dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	if from == to {
+		dst.Reset()
+		result.Finish = to
+		return result
+	}
+
+	astar.Start(g, from, to, l)
+	astar.Step(math.MaxInt)
+	return astar.Result(dst)
+}
+
+// Start begins a time-sliced search between the two coordinates.
+// It's like BuildLongPath, but the work is done by the Step calls.
+// This way a big search can be spread across several frames.
+//
+// The working space is shared with the other path building methods.
+// Calling any of them before the time-sliced search is done
+// would discard its state.
+func (astar *AStar) Start(g *Grid, from, to GridCoord, l GridLayer) {
+	astar.longCostmap = resizeCoordMap(astar.longCostmap, int(g.numCols), int(g.numRows))
+	astar.longPathmap = resizeCoordMap(astar.longPathmap, int(g.numCols), int(g.numRows))
+	astar.longPathmap.Reset()
+	astar.longCostmap.Reset()
+
+	astar.startSearch(g, l, GridCoord{}, from, to, nil, math.MaxInt32, astar.longCostmap, astar.longPathmap)
+}
+
+// Step continues the time-sliced search started by Start.
+// It expands at most maxNodes nodes.
+// It reports whether the search is done.
+//
+// Use Result to get the constructed path.
+func (astar *AStar) Step(maxNodes int) bool {
+	return astar.step(maxNodes)
+}
+
+// Result returns the time-sliced search result.
+// The path steps are written to dst, see BuildLongPath.
+//
+// If the search is not done yet, a partial path to the
+// most promising coordinate found so far is returned.
+func (astar *AStar) Result(dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	s := &astar.state
+	if s.pathmap == nil {
+		// The search was never started.
+		dst.Reset()
+		result.Partial = true
+		return result
+	}
+	constructLongPath(s.start, s.fallbackCoord, s.pathmap, dst)
+	result.Finish = s.fallbackCoord
+	result.Cost = astar.unscaleCost(uint32(s.fallbackCost))
+	result.Partial = !s.found
+	return result
+}
+
+// buildAreaPath is like BuildLongPath, but the search never leaves
+// the given grid area: x0 and y0 are inclusive, x1 and y1 are exclusive.
+// The working space is sized to that area.
+//
+// It's used by HPAStar to refine the abstract graph edges.
+func (astar *AStar) buildAreaPath(g *Grid, from, to GridCoord, l GridLayer, x0, y0, x1, y1 int, dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	if from == to {
+		dst.Reset()
+		result.Finish = to
+		return result
+	}
+
+	numCols := x1 - x0
+	numRows := y1 - y0
+	astar.longCostmap = resizeCoordMap(astar.longCostmap, numCols, numRows)
+	astar.longPathmap = resizeCoordMap(astar.longPathmap, numCols, numRows)
+	astar.longPathmap.Reset()
+	astar.longCostmap.Reset()
+
+	origin := GridCoord{X: x0, Y: y0}
+	astar.startSearch(g, l, origin, from.Sub(origin), to.Sub(origin), nil, math.MaxInt32, astar.longCostmap, astar.longPathmap)
+	s := &astar.state
+	s.areaX = uint(x0)
+	s.areaY = uint(y0)
+	s.areaCols = uint(numCols)
+	s.areaRows = uint(numRows)
+	astar.step(math.MaxInt)
+
+	constructLongPath(s.start, s.fallbackCoord, s.pathmap, dst)
+	result.Finish = s.fallbackCoord.Add(origin)
+	result.Cost = astar.unscaleCost(uint32(s.fallbackCost))
+	result.Partial = !s.found
+	return result
+}
+
+// search runs the A* algorithm using the local coordinates.
+// The origin is used to translate them into the grid coordinates.
+//
+// If goals are not nil, the search ends at any of them and localGoal is ignored.
+//
+// It returns the coord where the path ends, its cost and
+// whether it's the goal (otherwise it's a fallback coord).
+func (astar *AStar) search(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord, goals *pathGoals, maxWeight int32, costmap, pathmap *coordMap) (GridCoord, int32, bool) {
+	astar.startSearch(g, l, origin, localStart, localGoal, goals, maxWeight, costmap, pathmap)
+	astar.step(math.MaxInt)
+	s := &astar.state
+	return s.fallbackCoord, s.fallbackCost, s.found
+}
+
+// searchPlain is a specialized version of search for the most common case:
+// a 4-directional bounded search to a single goal without the clearance checks
+// over a default mode grid (see GridConfig.WideTags).
+//
+// See GreedyBFS.searchPlain for the rationale.
+// Keep this loop in sync with step.
+func (astar *AStar) searchPlain(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord) (GridCoord, int32, bool) {
+	frontier := astar.frontier
+	frontier.Reset()
 	frontier.Push(0, astarCoord{Coord: localStart})
 
+	costmap := astar.costmap
+	pathmap := astar.pathmap
+	maxExpanded := astar.maxExpanded
+
 	shortestDist := 0xffffffff
-	var fallbackCoord GridCoord
-	var fallbackCost int
-	foundPath := false
+	fallbackCoord := localStart
+	var fallbackCost int32
+	found := false
+	numExpanded := 0
 	for !frontier.IsEmpty() {
 		current := frontier.Pop()
 
+		if current.Weight > gridPathMaxLen {
+			// The path to this node doesn't fit into the GridPath.
+			break
+		}
 		if current.Coord == localGoal {
-			result.Steps = constructPath(localStart, localGoal, pathmap)
-			result.Finish = to
-			result.Cost = int(current.Cost)
-			foundPath = true
+			fallbackCoord = localGoal
+			fallbackCost = current.Cost
+			found = true
 			break
 		}
-		if current.Weight > gridPathMaxLen {
+		if numExpanded >= maxExpanded {
 			break
 		}
+		numExpanded++
 
 		dist := localGoal.Dist(current.Coord)
 		if dist < shortestDist {
 			shortestDist = dist
 			fallbackCoord = current.Coord
-			fallbackCost = int(current.Cost)
+			fallbackCost = current.Cost
 		}
 
 		currentCost, _ := costmap.Get(costmap.packCoord(current.Coord))
-		for dir, offset := range &neighborOffsets {
+		for dir, offset := range &axialNeighborOffsets {
 			next := current.Coord.Add(offset)
 			cx := uint(next.X) + uint(origin.X)
 			cy := uint(next.Y) + uint(origin.Y)
 			if cx >= g.numCols || cy >= g.numRows {
 				continue
 			}
-			nextCellCost := g.getCellCost(cx, cy, l)
+			nextCellCost := g.getTagCost(cx, cy, l)
 			if nextCellCost == 0 {
 				continue
 			}
@@ -129,6 +398,160 @@ func (astar *AStar) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildPat
 			}
 			costmap.Set(k, newNextCost)
 			priority := newNextCost + uint32(localGoal.Dist(next))
+			nextWeighted := astarCoord{
+				Coord:  next,
+				Cost:   int32(newNextCost),
+				Weight: current.Weight + 1,
+			}
+			frontier.Push(int(priority), nextWeighted)
+			pathmap.Set(k, uint32(dir))
+		}
+	}
+
+	return fallbackCoord, fallbackCost, found
+}
+
+func (astar *AStar) startSearch(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord, goals *pathGoals, maxWeight int32, costmap, pathmap *coordMap) {
+	astar.frontier.Reset()
+	astar.frontier.Push(0, astarCoord{Coord: localStart})
+
+	astar.state = astarSearch{
+		grid:      g,
+		layer:     l,
+		clearance: astar.clearance.boundTo(g, l),
+
+		origin: origin,
+		start:  localStart,
+		goal:   localGoal,
+
+		maxWeight: maxWeight,
+		costmap:   costmap,
+		pathmap:   pathmap,
+
+		areaCols: g.numCols,
+		areaRows: g.numRows,
+
+		shortestDist:  0xffffffff,
+		fallbackCoord: localStart,
+	}
+	if goals != nil {
+		astar.state.goals = *goals
+		astar.state.multiGoal = true
+	}
+}
+
+// step runs the search started by startSearch.
+// It pops at most maxNodes nodes from the frontier.
+func (astar *AStar) step(maxNodes int) bool {
+	s := &astar.state
+	if s.done {
+		return true
+	}
+
+	frontier := astar.frontier
+	g := s.grid
+	l := s.layer
+	clearance := s.clearance
+	origin := s.origin
+	localGoal := s.goal
+	goals := &s.goals
+	multiGoal := s.multiGoal
+	costmap := s.costmap
+	pathmap := s.pathmap
+	wide := g.wideTags
+	areaX := s.areaX
+	areaY := s.areaY
+	areaCols := s.areaCols
+	areaRows := s.areaRows
+
+	// The hot loop state is kept in the local variables;
+	// it's saved back to the search state when the loop ends.
+	shortestDist := s.shortestDist
+	fallbackCoord := s.fallbackCoord
+	fallbackCost := s.fallbackCost
+	numExpanded := s.numExpanded
+
+	for ; maxNodes > 0; maxNodes-- {
+		if frontier.IsEmpty() {
+			s.done = true
+			break
+		}
+		current := frontier.Pop()
+
+		if current.Weight > s.maxWeight {
+			// The path to this node is too long.
+			// For the bounded searches, it wouldn't fit into the GridPath.
+			s.done = true
+			break
+		}
+		if !multiGoal && current.Coord == localGoal || multiGoal && goals.reached(current.Coord.Add(origin)) {
+			fallbackCoord = current.Coord
+			fallbackCost = current.Cost
+			s.found = true
+			s.done = true
+			break
+		}
+		if numExpanded >= astar.maxExpanded {
+			s.done = true
+			break
+		}
+		numExpanded++
+
+		var dist int
+		if !multiGoal {
+			dist = localGoal.Dist(current.Coord)
+		} else {
+			// For the predicate-based search, the heuristic is always 0.
+			// The start coord will be selected as a fallback in this case.
+			dist = astar.goalsHeuristic(goals, current.Coord.Add(origin))
+		}
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackCoord = current.Coord
+			fallbackCost = current.Cost
+		}
+
+		currentCost, _ := costmap.Get(costmap.packCoord(current.Coord))
+		for dir, offset := range neighborOffsets[:astar.numNeighbors] {
+			next := current.Coord.Add(offset)
+			cx := uint(next.X) + uint(origin.X)
+			cy := uint(next.Y) + uint(origin.Y)
+			if cx-areaX >= areaCols || cy-areaY >= areaRows {
+				continue
+			}
+			nextCellCost := g.getTagCost(cx, cy, l)
+			if nextCellCost == 0 || wide && g.isBlockedCell(cx, cy) {
+				continue
+			}
+			if clearance != nil && !clearance.fits(cx, cy, astar.agentSize) {
+				continue
+			}
+			stepCost := astar.axialCost
+			if dir >= int(DirDownRight) {
+				x := uint(current.Coord.X) + uint(origin.X)
+				y := uint(current.Coord.Y) + uint(origin.Y)
+				if !g.canMoveDiagonally(x, y, offset, l, astar.cornerCutting) {
+					continue
+				}
+				if clearance != nil && !clearance.fitsDiagonally(x, y, offset, astar.agentSize) {
+					continue
+				}
+				stepCost = astar.diagonalCost
+			}
+			newNextCost := currentCost + uint32(nextCellCost)*stepCost
+			k := costmap.packCoord(next)
+			oldNextCost, ok := costmap.Get(k)
+			if ok && newNextCost >= oldNextCost {
+				continue
+			}
+			costmap.Set(k, newNextCost)
+			var h int
+			if !multiGoal {
+				h = astar.heuristic(localGoal, next)
+			} else {
+				h = astar.goalsHeuristic(goals, next.Add(origin))
+			}
+			priority := newNextCost + uint32(h)
 			nextWeighted := astarCoord{
 				Coord:  next,
 				Cost:   int32(newNextCost),
@@ -139,12 +562,107 @@ func (astar *AStar) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildPat
 		}
 	}
 
-	if !foundPath {
-		result.Steps = constructPath(localStart, fallbackCoord, pathmap)
-		result.Finish = fallbackCoord.Add(origin)
-		result.Cost = fallbackCost
-		result.Partial = true
+	s.shortestDist = shortestDist
+	s.fallbackCoord = fallbackCoord
+	s.fallbackCost = fallbackCost
+	s.numExpanded = numExpanded
+
+	return s.done
+}
+
+// BuildHexPath is like BuildPath, but it works with a HexGrid.
+// The from and to are the offset coordinates.
+//
+// The hex steps are all equal, so the Diagonal-related options are ignored.
+// The Clearance option is ignored too.
+func (astar *AStar) BuildHexPath(g *HexGrid, from, to GridCoord, l GridLayer) BuildHexPathResult {
+	var result BuildHexPathResult
+	if from == to {
+		result.Finish = to
+		return result
+	}
+
+	// The coord maps are indexed by the local offset coordinates.
+	// A hex step changes the column and row by at most 1,
+	// so the same search box works here.
+	origin := findPathOrigin(from)
+
+	costmap := astar.costmap
+	pathmap := astar.pathmap
+	costmap.Reset()
+	pathmap.Reset()
+
+	frontier := astar.frontier
+	frontier.Reset()
+
+	frontier.Push(0, astarCoord{Coord: from})
+
+	goal := OffsetToHex(to)
+	shortestDist := 0xffffffff
+	fallbackCoord := from
+	var fallbackCost int32
+	found := false
+	numExpanded := 0
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+
+		if current.Weight > gridPathDiagMaxLen {
+			// The path to this node doesn't fit into the GridPath.
+			break
+		}
+		if current.Coord == to {
+			fallbackCoord = to
+			fallbackCost = current.Cost
+			found = true
+			break
+		}
+		if numExpanded >= astar.maxExpanded {
+			break
+		}
+		numExpanded++
+
+		currentHex := OffsetToHex(current.Coord)
+		dist := goal.Dist(currentHex)
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackCoord = current.Coord
+			fallbackCost = current.Cost
+		}
+
+		currentCost, _ := costmap.Get(costmap.packCoord(current.Coord.Sub(origin)))
+		for dir, offset := range hexNeighborOffsets {
+			nextHex := currentHex.Add(offset)
+			next := nextHex.Offset()
+			local := next.Sub(origin)
+			if uint(local.X) >= uint(costmap.numCols) || uint(local.Y) >= uint(costmap.numRows) {
+				continue
+			}
+			nextCellCost := g.getCellCost(next, l)
+			if nextCellCost == 0 {
+				continue
+			}
+			newNextCost := currentCost + uint32(nextCellCost)
+			k := costmap.packCoord(local)
+			oldNextCost, ok := costmap.Get(k)
+			if ok && newNextCost >= oldNextCost {
+				continue
+			}
+			costmap.Set(k, newNextCost)
+			priority := newNextCost + uint32(goal.Dist(nextHex))
+			nextWeighted := astarCoord{
+				Coord:  next,
+				Cost:   int32(newNextCost),
+				Weight: current.Weight + 1,
+			}
+			frontier.Push(int(priority), nextWeighted)
+			pathmap.Set(k, uint32(dir))
+		}
 	}
 
+	result.Steps = constructHexPath(from, fallbackCoord, origin, pathmap)
+	result.Finish = fallbackCoord
+	result.Cost = int(fallbackCost)
+	result.Partial = !found
+
 	return result
 }
@@ -706,6 +706,15 @@ var astarTests = []pathfindTestCase{
 		partial: true,
 	},
 
+	{
+		// The goal is 57 steps away, the path to it doesn't fit into the GridPath.
+		name: "distlimit_goal",
+		path: []string{
+			"A                                                        B",
+		},
+		partial: true,
+	},
+
 	{
 		name: "distlimit2",
 		path: []string{
@@ -790,3 +799,210 @@ var astarTests = []pathfindTestCase{
 		cost: 29,
 	},
 }
+
+func TestAStarDiagonal(t *testing.T) {
+	for i := range astarDiagonalTests {
+		runPathfindTest(t, astarDiagonalTests[i], func(cols, rows uint) pathBuilder {
+			return pathing.NewAStar(pathing.AStarConfig{
+				NumCols:  cols,
+				NumRows:  rows,
+				Diagonal: true,
+			})
+		})
+	}
+}
+
+func TestAStarCornerCutting(t *testing.T) {
+	for i := range astarCornerCuttingTests {
+		runPathfindTest(t, astarCornerCuttingTests[i], func(cols, rows uint) pathBuilder {
+			return pathing.NewAStar(pathing.AStarConfig{
+				NumCols:       cols,
+				NumRows:       rows,
+				Diagonal:      true,
+				CornerCutting: true,
+			})
+		})
+	}
+}
+
+func TestAStarDiagonalCost(t *testing.T) {
+	m := []string{
+		"..........",
+		"...A......",
+		"..........",
+		"..........",
+		".....B....",
+		"..........",
+	}
+	parsed := testParseGrid(t, m)
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	tests := []struct {
+		diagonalCost float64
+		wantLen      int
+		wantCost     int
+	}{
+		{0, 3, 4},
+		{1, 3, 3},
+		{1.5, 3, 4},
+		{2.5, 5, 5},
+		{3, 5, 5},
+	}
+
+	for _, test := range tests {
+		astar := pathing.NewAStar(pathing.AStarConfig{
+			Diagonal:     true,
+			DiagonalCost: test.diagonalCost,
+		})
+		result := astar.BuildPath(parsed.grid, parsed.start, parsed.dest, l)
+		if result.Steps.Len() != test.wantLen {
+			t.Fatalf("DiagonalCost=%v: path len mismatch:\nhave: %d\nwant: %d", test.diagonalCost, result.Steps.Len(), test.wantLen)
+		}
+		if result.Cost != test.wantCost {
+			t.Fatalf("DiagonalCost=%v: path cost mismatch:\nhave: %d\nwant: %d", test.diagonalCost, result.Cost, test.wantCost)
+		}
+	}
+}
+
+var astarDiagonalTests = []pathfindTestCase{
+	{
+		name: "trivial_diagonal",
+		path: []string{
+			"..........",
+			"...A......",
+			"... ......",
+			".... .....",
+			".....$....",
+			"..........",
+		},
+		cost: 4,
+	},
+
+	{
+		name: "straight",
+		path: []string{
+			"..........",
+			"...A   $..",
+			"..........",
+		},
+		cost: 4,
+	},
+
+	{
+		name: "no_corner_cutting1",
+		path: []string{
+			"........",
+			"...A   .",
+			"..xxxx .",
+			"....x. .",
+			"....x$..",
+			"........",
+		},
+		cost: 6,
+	},
+
+	{
+		name: "no_corner_cutting2",
+		path: []string{
+			"A.x.....",
+			" x......",
+			"     ...",
+			"..... $.",
+		},
+		cost: 8,
+	},
+
+	{
+		name: "zigzag",
+		path: []string{
+			"A       ",
+			"xxxxxxx ",
+			"        ",
+			" xxxxxxx",
+			"   $....",
+		},
+		cost: 21,
+	},
+
+	{
+		name: "withcosts",
+		path: []string{
+			"..........",
+			".A..wwww..",
+			".. .wwww..",
+			"... wwww.$",
+			"....     .",
+		},
+		cost: 10,
+	},
+
+	{
+		name: "distlimit",
+		path: []string{
+			"A                                     .................................B",
+		},
+		cost:    37,
+		partial: true,
+	},
+
+	{
+		// The goal is 38 steps away, the path to it doesn't fit into the GridPath.
+		name: "distlimit_goal",
+		path: []string{
+			"A                                     B",
+		},
+		cost:    37,
+		partial: true,
+	},
+}
+
+var astarCornerCuttingTests = []pathfindTestCase{
+	{
+		name: "corner_cutting1",
+		path: []string{
+			"........",
+			"...A  ..",
+			"..xxxx .",
+			"....x. .",
+			"....x$..",
+			"........",
+		},
+		cost: 6,
+	},
+
+	{
+		name: "corner_cutting2",
+		path: []string{
+			"A.x.....",
+			" x......",
+			".  .....",
+			"...   $.",
+		},
+		cost: 8,
+	},
+
+	{
+		name: "corner_cutting3",
+		path: []string{
+			"A      .",
+			"xxxxxxx ",
+			".      .",
+			" xxxxxxx",
+			".  $....",
+		},
+		cost: 19,
+	},
+
+	{
+		name: "no_squeezing",
+		path: []string{
+			"....x...",
+			"...Ax...",
+			".. x....",
+			".. x.B..",
+			"...x....",
+		},
+		cost:    2,
+		partial: true,
+	},
+}
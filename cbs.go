@@ -0,0 +1,365 @@
+package pathing
+
+// CBS implements a Conflict-Based Search multi-agent pathfinding algorithm.
+// You must use NewCBS() function to obtain an instance of this type.
+//
+// It finds the optimal (the minimal sum of costs) collision-free paths for a group of agents.
+// Two agents can't occupy the same cell during the same tick (a vertex conflict)
+// and they can't swap their cells during one tick (an edge conflict).
+//
+// The high-level search resolves the conflicts by adding the constraints
+// to the conflicting agents; the low-level search is a space-time A*
+// that uses the true distance to the goal as a heuristic.
+// The plain AStar is not used here: its search states are cells, not (cell, tick) pairs,
+// so it can't make an agent wait or respect a constraint that only holds during one tick.
+//
+// For the same reason, the paths are returned as LongGridPath objects instead of GridPath:
+// they contain the wait steps and they can be longer than the GridPath max length
+// (see CBSConfig.MaxTicks).
+//
+// The worst case is exponential, so it's intended for
+// the small groups of agents (up to ~20). Use MaxNodes to limit the work.
+//
+// Just like a flow field, the solver is bound to a specific Grid and GridLayer pair.
+// Only the 4-directional movement is supported.
+type CBS struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols  int
+	numRows  int
+	maxNodes int
+
+	// fields are the heuristic sources, one per agent.
+	fields []*FlowField
+
+	// The low-level search.
+	search *spaceTimeAStar
+	rules  cbsRules
+}
+
+// cbsRules make the agent follow the constraints of the high-level search node.
+type cbsRules struct {
+	vertexConstraints map[cbsVertex]struct{}
+	edgeConstraints   map[cbsEdge]struct{}
+
+	// minGoalTick is the first tick the agent can stay at its goal.
+	minGoalTick int
+}
+
+type CBSConfig struct {
+	// MaxTicks is the max path duration (in ticks) for every agent.
+	// A single path step takes exactly 1 tick, a wait action takes 1 tick too.
+	//
+	// The low-level search needs 9 bytes per space-time state.
+	// The states cover the cells that can be reached in MaxTicks steps,
+	// so there are min(W, 2*MaxTicks+1) * min(H, 2*MaxTicks+1) * (MaxTicks+1)
+	// states, where W and H are the grid dimensions.
+	// For instance, it's ~9.7 MB for the default value on a big grid.
+	//
+	// If left unset (0), a default value of 64 is used.
+	MaxTicks int
+
+	// MaxNodes is a max number of the high-level search nodes
+	// to expand before giving up.
+	//
+	// If left unset (0), a default value of 1000 is used.
+	MaxNodes int
+}
+
+// CBSAgent describes the agent's pathfinding task.
+type CBSAgent struct {
+	From GridCoord
+	To   GridCoord
+}
+
+// CBSResult is a Solve() method return value.
+type CBSResult struct {
+	// Paths are indexed by the agent index.
+	// A DirNone path step means "wait for 1 tick".
+	// GridPath can't store such steps, see the CBS type comment.
+	// After the path ends, the agent stays at its goal.
+	//
+	// The paths are nil if the solution was not found.
+	Paths []LongGridPath
+
+	// Cost is a sum of the path costs.
+	// The path cost is a sum of the entered cell costs;
+	// every wait action costs 1.
+	Cost int
+
+	// NumNodes is a number of the expanded high-level search nodes.
+	NumNodes int
+
+	// Solved reports whether the solution was found.
+	// It can be false if any of the agents can't reach its goal
+	// in MaxTicks or if the MaxNodes budget was exhausted.
+	Solved bool
+}
+
+type cbsVertex struct {
+	coord GridCoord
+	tick  int
+}
+
+type cbsEdge struct {
+	from GridCoord
+	to   GridCoord
+	tick int
+}
+
+// cbsConstraint forbids the agent to make a move that ends at the tick.
+// For the vertex constraints, from and to are identical:
+// the agent can't be at that cell at all, even if it's waiting there.
+type cbsConstraint struct {
+	agent int
+	from  GridCoord
+	to    GridCoord
+	tick  int
+}
+
+type cbsNode struct {
+	parent     *cbsNode
+	constraint cbsConstraint
+
+	// paths are the agent positions for every tick.
+	// The unchanged paths are shared with the parent node.
+	paths [][]GridCoord
+	costs []int
+	cost  int
+}
+
+// NewCBS creates a solver for the given grid and layer.
+func NewCBS(g *Grid, l GridLayer, config CBSConfig) *CBS {
+	if config.MaxTicks <= 0 {
+		config.MaxTicks = 64
+	}
+	if config.MaxNodes <= 0 {
+		config.MaxNodes = 1000
+	}
+
+	// The agent can't get further than MaxTicks steps away,
+	// so the search box can be smaller than the grid.
+	boxSide := 2*config.MaxTicks + 1
+	boxCols := int(g.numCols)
+	if boxCols > boxSide {
+		boxCols = boxSide
+	}
+	boxRows := int(g.numRows)
+	if boxRows > boxSide {
+		boxRows = boxSide
+	}
+
+	return &CBS{
+		grid:     g,
+		layer:    l,
+		numCols:  int(g.numCols),
+		numRows:  int(g.numRows),
+		maxNodes: config.MaxNodes,
+
+		search: newSpaceTimeAStar(boxCols, boxRows, config.MaxTicks),
+		rules: cbsRules{
+			vertexConstraints: make(map[cbsVertex]struct{}),
+			edgeConstraints:   make(map[cbsEdge]struct{}),
+		},
+	}
+}
+
+// Solve finds the collision-free paths for the agents.
+// All agents start moving at the same time (tick 0).
+//
+// The agents should have distinct start and goal positions.
+func (s *CBS) Solve(agents []CBSAgent) CBSResult {
+	var result CBSResult
+
+	for len(s.fields) < len(agents) {
+		s.fields = append(s.fields, NewFlowField(s.grid, s.layer, FlowFieldConfig{}))
+	}
+	for i, a := range agents {
+		s.fields[i].Build(a.To)
+	}
+
+	root := &cbsNode{
+		paths: make([][]GridCoord, len(agents)),
+		costs: make([]int, len(agents)),
+	}
+	for i := range agents {
+		path, cost, ok := s.findPath(root, i, agents[i])
+		if !ok {
+			return result
+		}
+		root.paths[i] = path
+		root.costs[i] = cost
+		root.cost += cost
+	}
+
+	open := newMinheap[*cbsNode](32)
+	open.Push(root.cost, root)
+	for !open.IsEmpty() {
+		if result.NumNodes >= s.maxNodes {
+			return result
+		}
+		node := open.Pop()
+		result.NumNodes++
+
+		c1, c2, ok := s.findConflict(node.paths)
+		if !ok {
+			result.Paths = make([]LongGridPath, len(agents))
+			for i, path := range node.paths {
+				result.Paths[i] = positionsToPath(path)
+			}
+			result.Cost = node.cost
+			result.Solved = true
+			return result
+		}
+
+		for _, constraint := range [2]cbsConstraint{c1, c2} {
+			child := &cbsNode{
+				parent:     node,
+				constraint: constraint,
+				paths:      make([][]GridCoord, len(agents)),
+				costs:      make([]int, len(agents)),
+			}
+			copy(child.paths, node.paths)
+			copy(child.costs, node.costs)
+			i := constraint.agent
+			path, cost, ok := s.findPath(child, i, agents[i])
+			if !ok {
+				continue
+			}
+			child.paths[i] = path
+			child.costs[i] = cost
+			child.cost = node.cost - node.costs[i] + cost
+			open.Push(child.cost, child)
+		}
+	}
+
+	return result
+}
+
+// findConflict returns the first conflict as a pair of constraints.
+func (s *CBS) findConflict(paths [][]GridCoord) (cbsConstraint, cbsConstraint, bool) {
+	maxLen := 0
+	for _, path := range paths {
+		if len(path) > maxLen {
+			maxLen = len(path)
+		}
+	}
+	for t := 0; t < maxLen; t++ {
+		for i := range paths {
+			a := cbsPositionAt(paths[i], t)
+			for j := i + 1; j < len(paths); j++ {
+				b := cbsPositionAt(paths[j], t)
+				if a == b {
+					return cbsConstraint{agent: i, from: a, to: a, tick: t},
+						cbsConstraint{agent: j, from: a, to: a, tick: t},
+						true
+				}
+				if t == 0 {
+					continue
+				}
+				prevA := cbsPositionAt(paths[i], t-1)
+				prevB := cbsPositionAt(paths[j], t-1)
+				if prevA == b && prevB == a {
+					return cbsConstraint{agent: i, from: prevA, to: a, tick: t},
+						cbsConstraint{agent: j, from: prevB, to: b, tick: t},
+						true
+				}
+			}
+		}
+	}
+	return cbsConstraint{}, cbsConstraint{}, false
+}
+
+// findPath runs the low-level search for the agent i
+// using the constraints of the node.
+func (s *CBS) findPath(node *cbsNode, i int, agent CBSAgent) ([]GridCoord, int, bool) {
+	rules := &s.rules
+	for k := range rules.vertexConstraints {
+		delete(rules.vertexConstraints, k)
+	}
+	for k := range rules.edgeConstraints {
+		delete(rules.edgeConstraints, k)
+	}
+	// The agent can't stay at its goal until all goal
+	// constraints are in the past.
+	rules.minGoalTick = 0
+	for n := node; n.parent != nil; n = n.parent {
+		c := n.constraint
+		if c.agent != i {
+			continue
+		}
+		if c.from == c.to {
+			rules.vertexConstraints[cbsVertex{coord: c.to, tick: c.tick}] = struct{}{}
+			if c.to == agent.To && c.tick >= rules.minGoalTick {
+				rules.minGoalTick = c.tick + 1
+			}
+		} else {
+			rules.edgeConstraints[cbsEdge{from: c.from, to: c.to, tick: c.tick}] = struct{}{}
+		}
+	}
+
+	field := s.fields[i]
+	if !s.contains(agent.From) {
+		return nil, 0, false
+	}
+	if _, ok := field.Distance(agent.From); !ok {
+		return nil, 0, false
+	}
+
+	finish, ok := s.search.search(s.grid, s.layer, agent.From, agent.To, field, rules, false)
+	if !ok {
+		return nil, 0, false
+	}
+	return s.constructPositions(finish), int(finish.Cost), true
+}
+
+func (s *CBS) constructPositions(finish spaceTimeCoord) []GridCoord {
+	n := int(finish.Tick)
+	positions := make([]GridCoord, n+1)
+	pos := finish.Coord
+	for t := n; t > 0; t-- {
+		positions[t] = pos
+		pos = pos.reversedMove(s.search.actionAt(pos, t))
+	}
+	positions[0] = pos
+	return positions
+}
+
+func (s *CBS) contains(c GridCoord) bool {
+	return uint(c.X) < uint(s.numCols) && uint(c.Y) < uint(s.numRows)
+}
+
+func (r *cbsRules) canEnter(from, to GridCoord, tick int) bool {
+	if _, ok := r.vertexConstraints[cbsVertex{coord: to, tick: tick}]; ok {
+		return false
+	}
+	if from != to {
+		if _, ok := r.edgeConstraints[cbsEdge{from: from, to: to, tick: tick}]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *cbsRules) canFinish(goal GridCoord, tick int) bool {
+	return tick >= r.minGoalTick
+}
+
+// cbsPositionAt returns the agent position at the given tick.
+// After the path ends, the agent stays at its last position.
+func cbsPositionAt(path []GridCoord, tick int) GridCoord {
+	if tick < len(path) {
+		return path[tick]
+	}
+	return path[len(path)-1]
+}
+
+func positionsToPath(positions []GridCoord) LongGridPath {
+	var path LongGridPath
+	for i := 1; i < len(positions); i++ {
+		delta := positions[i].Sub(positions[i-1])
+		path.Push(directionFromDelta(delta.X, delta.Y))
+	}
+	return path
+}
@@ -0,0 +1,329 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestCBS(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"xxxxx.x",
+		".......",
+		"xxxxxxx",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	agents := []pathing.CBSAgent{
+		{From: pathing.GridCoord{X: 0, Y: 1}, To: pathing.GridCoord{X: 6, Y: 1}},
+		{From: pathing.GridCoord{X: 6, Y: 1}, To: pathing.GridCoord{X: 0, Y: 1}},
+	}
+
+	solver := pathing.NewCBS(g, l, pathing.CBSConfig{})
+	result := solver.Solve(agents)
+	if !result.Solved {
+		t.Fatalf("no solution found after %d nodes", result.NumNodes)
+	}
+	checkCBSResult(t, g, l, agents, result)
+	// One of the agents has to step aside into the pocket and back (2 moves)
+	// and wait there until the other agent passes by (3 ticks).
+	if result.Cost != 17 {
+		t.Fatalf("unexpected cost: have %d, want 17 (%s, %s)", result.Cost, result.Paths[0].String(), result.Paths[1].String())
+	}
+
+	// Not enough nodes to resolve the conflict.
+	solver = pathing.NewCBS(g, l, pathing.CBSConfig{MaxNodes: 1})
+	result = solver.Solve(agents)
+	if result.Solved || result.Paths != nil || result.NumNodes != 1 {
+		t.Fatalf("expected the budget to be exhausted, got solved=%v nodes=%d", result.Solved, result.NumNodes)
+	}
+
+	// Without the pocket, there is no solution.
+	g.SetCellTile(pathing.GridCoord{X: 5, Y: 0}, 1)
+	solver = pathing.NewCBS(g, l, pathing.CBSConfig{MaxTicks: 16})
+	result = solver.Solve(agents)
+	if result.Solved {
+		t.Fatalf("found a solution for an unsolvable instance")
+	}
+}
+
+func TestCBSBigGrid(t *testing.T) {
+	// The low-level search covers only the cells
+	// around the agent that are reachable in MaxTicks.
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 100, WorldHeight: 32 * 100})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	agents := []pathing.CBSAgent{
+		{From: pathing.GridCoord{X: 95, Y: 99}, To: pathing.GridCoord{X: 99, Y: 99}},
+		{From: pathing.GridCoord{X: 99, Y: 99}, To: pathing.GridCoord{X: 95, Y: 99}},
+		{From: pathing.GridCoord{X: 0, Y: 0}, To: pathing.GridCoord{X: 3, Y: 0}},
+	}
+	solver := pathing.NewCBS(g, l, pathing.CBSConfig{MaxTicks: 16})
+	result := solver.Solve(agents)
+	if !result.Solved {
+		t.Fatalf("no solution found after %d nodes", result.NumNodes)
+	}
+	checkCBSResult(t, g, l, agents, result)
+	// One of the swapping agents needs to make a detour (2 extra moves).
+	if result.Cost != 13 {
+		t.Fatalf("unexpected cost: have %d, want 13", result.Cost)
+	}
+}
+
+func TestCBSRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	for i := 0; i < 100; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 10)
+		numAgents := rng.Intn(5) + 1
+		var agents []pathing.CBSAgent
+		used := make(map[pathing.GridCoord]bool)
+		randomCell := func() (pathing.GridCoord, bool) {
+			for attempt := 0; attempt < 20; attempt++ {
+				c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				if !used[c] && g.GetCellCost(c, l) != 0 {
+					used[c] = true
+					return c, true
+				}
+			}
+			return pathing.GridCoord{}, false
+		}
+		for j := 0; j < numAgents; j++ {
+			from, ok1 := randomCell()
+			to, ok2 := randomCell()
+			if ok1 && ok2 {
+				agents = append(agents, pathing.CBSAgent{From: from, To: to})
+			}
+		}
+
+		solver := pathing.NewCBS(g, l, pathing.CBSConfig{MaxTicks: 32, MaxNodes: 200})
+		result := solver.Solve(agents)
+		if !result.Solved {
+			continue
+		}
+		checkCBSResult(t, g, l, agents, result)
+
+		// The solution can't be cheaper than the individual optimal paths.
+		astar := pathing.NewAStar(pathing.AStarConfig{})
+		lowerBound := 0
+		for _, a := range agents {
+			lowerBound += astar.BuildPath(g, a.From, a.To, l).Cost
+		}
+		if result.Cost < lowerBound {
+			t.Fatalf("test%d: the cost %d is less than the lower bound %d", i, result.Cost, lowerBound)
+		}
+	}
+}
+
+func TestCBSBruteForce(t *testing.T) {
+	// The tiny instances can be solved by searching
+	// the joint state space of all agents.
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	numSolved := 0
+	numExhausted := 0
+	for i := 0; i < 300; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 3)
+		var cells []pathing.GridCoord
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				if c := (pathing.GridCoord{X: x, Y: y}); g.GetCellCost(c, l) != 0 {
+					cells = append(cells, c)
+				}
+			}
+		}
+		numAgents := rng.Intn(2) + 2
+		if len(cells) < numAgents {
+			continue
+		}
+		starts := rng.Perm(len(cells))
+		goals := rng.Perm(len(cells))
+		agents := make([]pathing.CBSAgent, numAgents)
+		for j := range agents {
+			agents[j] = pathing.CBSAgent{From: cells[starts[j]], To: cells[goals[j]]}
+		}
+
+		wantCost, wantSolved := cbsBruteForce(g, l, agents)
+		solver := pathing.NewCBS(g, l, pathing.CBSConfig{MaxNodes: 2000})
+		result := solver.Solve(agents)
+		if !result.Solved && wantSolved && result.NumNodes == 2000 {
+			// The agents that need to reorder in a tight space
+			// can make the CBS constraint tree too big.
+			numExhausted++
+			continue
+		}
+		if result.Solved != wantSolved {
+			t.Fatalf("test%d: %v: solved mismatch after %d nodes:\nhave: %v\nwant: %v", i, agents, result.NumNodes, result.Solved, wantSolved)
+		}
+		if !result.Solved {
+			continue
+		}
+		numSolved++
+		checkCBSResult(t, g, l, agents, result)
+		if result.Cost != wantCost {
+			t.Fatalf("test%d: %v: cost mismatch:\nhave: %d\nwant: %d", i, agents, result.Cost, wantCost)
+		}
+	}
+	if numSolved < 10*numExhausted {
+		t.Fatalf("too many instances exhausted the budget: %d solved, %d exhausted", numSolved, numExhausted)
+	}
+}
+
+// cbsBruteForce finds the optimal sum of costs using
+// the Dijkstra algorithm over the joint agent states.
+//
+// Every agent state is its position and a "finished" flag:
+// a finished agent stays at its goal forever for free.
+func cbsBruteForce(g *pathing.Grid, l pathing.GridLayer, agents []pathing.CBSAgent) (int, bool) {
+	type agentState struct {
+		pos      pathing.GridCoord
+		finished bool
+	}
+	type jointState [3]agentState
+
+	var start jointState
+	for i, a := range agents {
+		start[i].pos = a.From
+	}
+	dists := map[jointState]int{start: 0}
+	buckets := [][]jointState{{start}}
+	options := make([][]agentState, len(agents))
+	stepCosts := make([][]int, len(agents))
+	for cost := 0; cost < len(buckets); cost++ {
+		// The finishing transitions are free, so the bucket can grow while it's processed.
+		for k := 0; k < len(buckets[cost]); k++ {
+			current := buckets[cost][k]
+			if dists[current] != cost {
+				continue // Stale entry
+			}
+			allFinished := true
+			for i, a := range agents {
+				s := current[i]
+				options[i] = options[i][:0]
+				stepCosts[i] = stepCosts[i][:0]
+				if s.finished {
+					options[i] = append(options[i], s)
+					stepCosts[i] = append(stepCosts[i], 0)
+					continue
+				}
+				allFinished = false
+				if s.pos == a.To {
+					options[i] = append(options[i], agentState{pos: s.pos, finished: true})
+					stepCosts[i] = append(stepCosts[i], 0)
+				}
+				options[i] = append(options[i], s)
+				stepCosts[i] = append(stepCosts[i], 1)
+				for _, d := range []pathing.Direction{pathing.DirRight, pathing.DirDown, pathing.DirLeft, pathing.DirUp} {
+					next := s.pos.Move(d)
+					if cellCost := g.GetCellCost(next, l); cellCost != 0 {
+						options[i] = append(options[i], agentState{pos: next})
+						stepCosts[i] = append(stepCosts[i], int(cellCost))
+					}
+				}
+			}
+			if allFinished {
+				return cost, true
+			}
+
+			// Try every combination of the agent options.
+			var choice [3]int
+			for {
+				var next jointState
+				nextCost := cost
+				valid := true
+				for i := range agents {
+					next[i] = options[i][choice[i]]
+					nextCost += stepCosts[i][choice[i]]
+					for j := 0; j < i; j++ {
+						if next[i].pos == next[j].pos {
+							valid = false
+						}
+						if next[i].pos == current[j].pos && next[j].pos == current[i].pos && next[i].pos != current[i].pos {
+							valid = false
+						}
+					}
+				}
+				if old, ok := dists[next]; valid && (!ok || nextCost < old) {
+					dists[next] = nextCost
+					for len(buckets) <= nextCost {
+						buckets = append(buckets, nil)
+					}
+					buckets[nextCost] = append(buckets[nextCost], next)
+				}
+
+				k := 0
+				for k < len(agents) {
+					choice[k]++
+					if choice[k] < len(options[k]) {
+						break
+					}
+					choice[k] = 0
+					k++
+				}
+				if k == len(agents) {
+					break
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+func checkCBSResult(t *testing.T, g *pathing.Grid, l pathing.GridLayer, agents []pathing.CBSAgent, result pathing.CBSResult) {
+	t.Helper()
+
+	maxLen := 0
+	totalCost := 0
+	for i, a := range agents {
+		path := result.Paths[i]
+		if path.Len() > maxLen {
+			maxLen = path.Len()
+		}
+		pos := a.From
+		for path.HasNext() {
+			d := path.Next()
+			if d == pathing.DirNone {
+				totalCost++
+				continue
+			}
+			pos = pos.Move(d)
+			cost := g.GetCellCost(pos, l)
+			if cost == 0 {
+				t.Fatalf("agent%d: the path %s goes through %v", i, path.String(), pos)
+			}
+			totalCost += int(cost)
+		}
+		if pos != a.To {
+			t.Fatalf("agent%d: the path %s ends at %v instead of %v", i, path.String(), pos, a.To)
+		}
+	}
+	if totalCost != result.Cost {
+		t.Fatalf("the paths cost is %d, reported %d", totalCost, result.Cost)
+	}
+
+	positions := make([]pathing.GridCoord, len(agents))
+	for i, a := range agents {
+		positions[i] = a.From
+		result.Paths[i].Rewind()
+	}
+	prev := make([]pathing.GridCoord, len(agents))
+	for tick := 1; tick <= maxLen; tick++ {
+		copy(prev, positions)
+		for i := range agents {
+			if result.Paths[i].HasNext() {
+				positions[i] = positions[i].Move(result.Paths[i].Next())
+			}
+		}
+		for i := range agents {
+			for j := i + 1; j < len(agents); j++ {
+				if positions[i] == positions[j] {
+					t.Fatalf("tick%d: agent%d and agent%d are at %v", tick, i, j, positions[i])
+				}
+				if positions[i] == prev[j] && positions[j] == prev[i] {
+					t.Fatalf("tick%d: agent%d and agent%d swapped their cells", tick, i, j)
+				}
+			}
+		}
+	}
+}
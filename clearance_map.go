@@ -0,0 +1,170 @@
+package pathing
+
+// ClearanceMap stores the true clearance value of every grid cell.
+// You must use NewClearanceMap() function to obtain an instance of this type.
+//
+// A cell clearance is the size of the biggest passable square
+// that has this cell as its top-left corner.
+// An impassable cell has a clearance of 0.
+// The values are capped at 255.
+//
+// The map is bound to a specific Grid and GridLayer pair.
+// It needs 1 byte per Grid cell.
+//
+// The map is used to find paths for the agents that occupy
+// more than one cell, see AStarConfig.Clearance.
+//
+// When the grid is changed (e.g. SetCellTile or SetCellIsBlocked are called),
+// use NotifyCellChanged and Update methods to recompute the affected values.
+type ClearanceMap struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols int
+	numRows int
+
+	values []uint8
+
+	changed []GridCoord
+}
+
+const maxClearance = 0xff
+
+// NewClearanceMap creates a clearance map for the given grid and layer.
+func NewClearanceMap(g *Grid, l GridLayer) *ClearanceMap {
+	m := &ClearanceMap{
+		grid:    g,
+		layer:   l,
+		numCols: int(g.numCols),
+		numRows: int(g.numRows),
+		values:  make([]uint8, g.numCols*g.numRows),
+	}
+	m.Rebuild()
+	return m
+}
+
+// Rebuild recomputes the clearance values of the entire grid.
+// It's an O(n) operation, where n is a number of grid cells.
+//
+// Note that the grid size is not expected to change.
+func (m *ClearanceMap) Rebuild() {
+	m.changed = m.changed[:0]
+	// The cells are traversed from the bottom-right corner,
+	// so the right and bottom neighbors are always computed first.
+	for y := m.numRows - 1; y >= 0; y-- {
+		for x := m.numCols - 1; x >= 0; x-- {
+			m.values[y*m.numCols+x] = m.compute(x, y)
+		}
+	}
+}
+
+// Clearance returns the clearance value of the given cell.
+// An out-of-bounds cell has a clearance of 0.
+func (m *ClearanceMap) Clearance(c GridCoord) int {
+	if uint(c.X) >= uint(m.numCols) || uint(c.Y) >= uint(m.numRows) {
+		return 0
+	}
+	return int(m.values[c.Y*m.numCols+c.X])
+}
+
+// NotifyCellChanged marks the cell as changed.
+// The clearance values are not recomputed until Update is called.
+//
+// Call this method after the cell tile or its blocked bit are changed.
+func (m *ClearanceMap) NotifyCellChanged(c GridCoord) {
+	if uint(c.X) >= uint(m.numCols) || uint(c.Y) >= uint(m.numRows) {
+		return
+	}
+	m.changed = append(m.changed, c)
+}
+
+// Update recomputes the clearance values affected by the changed cells.
+//
+// Only the cells above and to the left of the changed cells can be affected
+// and the recomputation stops as soon as the values stay the same,
+// so it's usually much cheaper than a Rebuild.
+func (m *ClearanceMap) Update() {
+	for _, c := range m.changed {
+		m.updateCell(c)
+	}
+	m.changed = m.changed[:0]
+}
+
+func (m *ClearanceMap) updateCell(c GridCoord) {
+	// [lo, hi] is a range of cells that need to be recomputed in the current row.
+	// A cell to the left of the changed cell needs to be recomputed too,
+	// even if it's outside of that range.
+	lo, hi := c.X, c.X
+	for y := c.Y; y >= 0; y-- {
+		changedLo, changedHi := -1, -1
+		leftChanged := false
+		for x := hi; x >= 0; x-- {
+			if x < lo && !leftChanged {
+				break
+			}
+			i := y*m.numCols + x
+			v := m.compute(x, y)
+			leftChanged = v != m.values[i]
+			if !leftChanged {
+				continue
+			}
+			m.values[i] = v
+			if changedHi == -1 {
+				changedHi = x
+			}
+			changedLo = x
+		}
+		if changedHi == -1 {
+			break
+		}
+		// The cells of the row above depend on the
+		// cells below them and below-right to them.
+		lo, hi = changedLo-1, changedHi
+	}
+}
+
+func (m *ClearanceMap) compute(x, y int) uint8 {
+	if m.grid.getCellCost(uint(x), uint(y), m.layer) == 0 {
+		return 0
+	}
+	v := m.get(x+1, y)
+	if down := m.get(x, y+1); down < v {
+		v = down
+	}
+	if downRight := m.get(x+1, y+1); downRight < v {
+		v = downRight
+	}
+	if v == maxClearance {
+		return maxClearance
+	}
+	return v + 1
+}
+
+func (m *ClearanceMap) get(x, y int) uint8 {
+	if x >= m.numCols || y >= m.numRows {
+		return 0
+	}
+	return m.values[y*m.numCols+x]
+}
+
+// fits reports whether an agent of the given size can stand at the (x, y) cell.
+// The coordinates are expected to be in bounds.
+func (m *ClearanceMap) fits(x, y uint, size uint8) bool {
+	return m.values[y*uint(m.numCols)+x] >= size
+}
+
+// fitsDiagonally reports whether an agent of the given size
+// can make a diagonal step from the (x, y) cell.
+// Both adjacent axial cells should fit the agent.
+func (m *ClearanceMap) fitsDiagonally(x, y uint, offset GridCoord, size uint8) bool {
+	return m.fits(x+uint(offset.X), y, size) && m.fits(x, y+uint(offset.Y), size)
+}
+
+// boundTo returns the map itself if it was created for the given grid and layer.
+// Otherwise (or if the map is nil) it returns nil.
+func (m *ClearanceMap) boundTo(g *Grid, l GridLayer) *ClearanceMap {
+	if m == nil || m.grid != g || m.layer != l {
+		return nil
+	}
+	return m
+}
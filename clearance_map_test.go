@@ -0,0 +1,204 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkClearanceMapUpdate(b *testing.B) {
+	parseResult := testParseGrid(b, []string{
+		"................................",
+		"................................",
+		"........x.......................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	m := pathing.NewClearanceMap(g, l)
+	c := pathing.GridCoord{X: 20, Y: 12}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.SetCellIsBlocked(c, i%2 == 0)
+		m.NotifyCellChanged(c)
+		m.Update()
+	}
+}
+
+func TestClearanceMap(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		".....",
+		"..x..",
+		".....",
+		"....x",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	m := pathing.NewClearanceMap(g, l)
+	want := [][]int{
+		{2, 1, 1, 2, 1},
+		{2, 1, 0, 2, 1},
+		{2, 2, 2, 1, 1},
+		{1, 1, 1, 1, 0},
+	}
+	for y, row := range want {
+		for x, v := range row {
+			c := pathing.GridCoord{X: x, Y: y}
+			if have := m.Clearance(c); have != v {
+				t.Fatalf("%v clearance mismatch:\nhave: %d\nwant: %d", c, have, v)
+			}
+		}
+	}
+
+	outside := []pathing.GridCoord{{X: -1}, {Y: -1}, {X: 5}, {Y: 4}}
+	for _, c := range outside {
+		if have := m.Clearance(c); have != 0 {
+			t.Fatalf("%v clearance mismatch:\nhave: %d\nwant: 0", c, have)
+		}
+	}
+}
+
+func TestClearanceMapUpdate(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 200; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 40)
+		m := pathing.NewClearanceMap(g, l)
+		for j := 0; j < 10; j++ {
+			numChanges := rng.Intn(4) + 1
+			for k := 0; k < numChanges; k++ {
+				c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				g.SetCellIsBlocked(c, !g.GetCellIsBlocked(c))
+				m.NotifyCellChanged(c)
+			}
+			m.Update()
+
+			want := pathing.NewClearanceMap(g, l)
+			for y := 0; y < numRows; y++ {
+				for x := 0; x < numCols; x++ {
+					c := pathing.GridCoord{X: x, Y: y}
+					if m.Clearance(c) != want.Clearance(c) {
+						t.Fatalf("test%d: update %d: %v clearance mismatch:\nhave: %d\nwant: %d",
+							i, j, c, m.Clearance(c), want.Clearance(c))
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestClearancePathfinding(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"....A.....",
+		"..........",
+		"xxx.xxx..x",
+		"xxx.xxx..x",
+		"....B.....",
+		"..........",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	m := pathing.NewClearanceMap(g, l)
+
+	type pathBuilder interface {
+		BuildPath(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer) pathing.BuildPathResult
+	}
+	tests := []struct {
+		name    string
+		impl    pathBuilder
+		l       pathing.GridLayer
+		cost    int
+		minSize int
+	}{
+		{
+			name:    "astar",
+			impl:    pathing.NewAStar(pathing.AStarConfig{Clearance: m}),
+			l:       l,
+			cost:    6,
+			minSize: 1,
+		},
+		{
+			name:    "astar_size2",
+			impl:    pathing.NewAStar(pathing.AStarConfig{Clearance: m, AgentSize: 2}),
+			l:       l,
+			cost:    10,
+			minSize: 2,
+		},
+		{
+			name:    "astar_diagonal_size2",
+			impl:    pathing.NewAStar(pathing.AStarConfig{Clearance: m, AgentSize: 2, Diagonal: true}),
+			l:       l,
+			cost:    10,
+			minSize: 2,
+		},
+		{
+			name:    "astar_other_layer",
+			impl:    pathing.NewAStar(pathing.AStarConfig{Clearance: m, AgentSize: 2}),
+			l:       pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 1}),
+			cost:    6,
+			minSize: 1,
+		},
+		{
+			name:    "greedy_bfs_size2",
+			impl:    pathing.NewGreedyBFS(pathing.GreedyBFSConfig{Clearance: m, AgentSize: 2}),
+			l:       l,
+			cost:    10,
+			minSize: 2,
+		},
+		{
+			name:    "greedy_bfs_diagonal_size2",
+			impl:    pathing.NewGreedyBFS(pathing.GreedyBFSConfig{Clearance: m, AgentSize: 2, Diagonal: true}),
+			l:       l,
+			cost:    10,
+			minSize: 2,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			result := test.impl.BuildPath(g, parseResult.start, parseResult.dest, test.l)
+			if result.Partial {
+				t.Fatalf("expected a full path")
+			}
+			if result.Cost != test.cost {
+				t.Fatalf("cost mismatch:\nhave: %d\nwant: %d", result.Cost, test.cost)
+			}
+			pos := parseResult.start
+			for result.Steps.HasNext() {
+				pos = pos.Move(result.Steps.Next())
+				if m.Clearance(pos) < test.minSize {
+					t.Fatalf("%v is too narrow: %d", pos, m.Clearance(pos))
+				}
+			}
+			if pos != parseResult.dest {
+				t.Fatalf("path leads to %v instead of %v", pos, parseResult.dest)
+			}
+		})
+	}
+
+	// Close the wide passage: there is no way through for a big agent.
+	g.SetCellIsBlocked(pathing.GridCoord{X: 8, Y: 3}, true)
+	m.NotifyCellChanged(pathing.GridCoord{X: 8, Y: 3})
+	m.Update()
+	astar := pathing.NewAStar(pathing.AStarConfig{Clearance: m, AgentSize: 2})
+	result := astar.BuildPath(g, parseResult.start, parseResult.dest, l)
+	if !result.Partial {
+		t.Fatalf("expected a partial path")
+	}
+}
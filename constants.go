@@ -4,5 +4,8 @@ const (
 	gridPathBytes  = (16 - 2)
 	gridPathMaxLen = gridPathBytes * 4
 
+	// The paths that contain the diagonal steps use 3 bits per step.
+	gridPathDiagMaxLen = (gridPathBytes * 8) / 3
+
 	gridMapSide = (gridPathMaxLen * 2) + 2
 )
@@ -0,0 +1,227 @@
+package pathing
+
+// CooperativeAStar implements a windowed hierarchical cooperative A* (WHCA*)
+// pathfinding algorithm.
+// You must use NewCooperativeAStar() function to obtain an instance of this type.
+//
+// It plans a path in space-time: every search state is a (cell, tick) pair.
+// The cells reserved by the other agents (see ReservationTable) are avoided,
+// and the agent can wait in place if it needs to let somebody pass.
+// The head-on collisions (two agents swapping their cells) are avoided too.
+//
+// The space-time search is limited by the window size (in ticks).
+// The rest of the path is estimated using the true distance to the goal,
+// so the agents don't get lost behind the obstacles.
+//
+// A typical usage is to plan the agents one by one, reserving their paths
+// in the table, and then repeat the planning before the window ends.
+// Only the 4-directional movement is supported.
+//
+// The true distance heuristic is computed once per goal and then cached.
+// When the grid changes, use NotifyCellChanged to keep it up-to-date.
+//
+// Once created, you should re-use it to build paths.
+// Do not throw the instance away after building the path once.
+type CooperativeAStar struct {
+	window int
+
+	search *spaceTimeAStar
+	rules  cooperativeRules
+
+	// heuristics are the true distance heuristic sources, one per goal.
+	// They're bound to the grid and layer pair; the cache is
+	// dropped if BuildPath is called for another Grid or GridLayer.
+	heuristics    []cooperativeHeuristic
+	maxHeuristics int
+	grid          *Grid
+	layer         GridLayer
+	useCounter    uint64
+}
+
+// cooperativeRules make the agent avoid the reserved cells.
+type cooperativeRules struct {
+	table  *ReservationTable
+	agent  int
+	tick   int
+	window int
+}
+
+type cooperativeHeuristic struct {
+	field    *FlowField
+	goal     GridCoord
+	lastUsed uint64
+}
+
+type CooperativeAStarConfig struct {
+	// Window is a number of ticks the space-time search covers.
+	// The bigger windows make the agents more cooperative,
+	// but they need more memory and time to plan.
+	// The memory usage grows cubically.
+	//
+	// If left unset (0), a default value of 16 is used.
+	Window int
+
+	// HeuristicCacheSize is a max number of goals the true distance
+	// heuristic is kept for. When the cache is full,
+	// the least recently used goal is evicted.
+	// Every cached goal needs 5 bytes per grid cell (see FlowField).
+	//
+	// If left unset (0), a default value of 8 is used.
+	HeuristicCacheSize int
+}
+
+// NewCooperativeAStar creates a ready-to-use CooperativeAStar object.
+func NewCooperativeAStar(config CooperativeAStarConfig) *CooperativeAStar {
+	if config.Window <= 0 {
+		config.Window = 16
+	}
+	if config.HeuristicCacheSize <= 0 {
+		config.HeuristicCacheSize = 8
+	}
+
+	// The agent can't get further than window steps away,
+	// so a state cell coordinate is always inside this box.
+	side := 2*config.Window + 1
+
+	return &CooperativeAStar{
+		window: config.Window,
+		search: newSpaceTimeAStar(side, side, config.Window),
+
+		maxHeuristics: config.HeuristicCacheSize,
+	}
+}
+
+// NotifyCellChanged tells the planner that the cell tile tag
+// or its blocked status was changed.
+// The cached heuristics are repaired during the next BuildPath call
+// that needs them (see FlowField.Update).
+func (c *CooperativeAStar) NotifyCellChanged(cell GridCoord) {
+	for i := range c.heuristics {
+		c.heuristics[i].field.NotifyCellChanged(cell)
+	}
+}
+
+// BuildPath finds a path from one coordinate to another for the agent,
+// starting at the given tick.
+// The cells reserved by other agents in the table are avoided.
+//
+// The path steps are written to dst; its previous contents are discarded.
+// A DirNone step in the resulting path means "wait for 1 tick".
+// Note that LongGridPath.Peek returns DirNone at the end of the path too,
+// so use HasNext to check whether there are more steps.
+//
+// The path covers at most Window ticks. If the goal is not reached
+// during this time, the result is partial and the path leads towards the goal.
+// The goal is considered to be reached only if its cell is not
+// reserved by anyone until the window ends.
+//
+// The result cost is a sum of the entered cell costs;
+// every wait action costs 1.
+//
+// Computing the true distance heuristic for a new goal is an O(n*log(n)) operation,
+// where n is a number of the grid cells.
+// The cached heuristics are re-used (see HeuristicCacheSize).
+func (c *CooperativeAStar) BuildPath(g *Grid, from, to GridCoord, l GridLayer, table *ReservationTable, agent, tick int, dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	dst.Reset()
+	result.Finish = from
+	result.Partial = from != to
+
+	field := c.getHeuristic(g, l, to)
+	if _, ok := field.Distance(from); !ok {
+		return result
+	}
+
+	c.rules = cooperativeRules{
+		table:  table,
+		agent:  agent,
+		tick:   tick,
+		window: c.window,
+	}
+	finish, ok := c.search.search(g, l, from, to, field, &c.rules, true)
+	c.rules.table = nil // Don't keep the table alive
+	if !ok {
+		// Every possible action leads to a conflict.
+		return result
+	}
+
+	c.constructPath(finish, dst)
+	result.Finish = finish.Coord
+	result.Cost = int(finish.Cost)
+	result.Partial = finish.Coord != to
+	return result
+}
+
+// getHeuristic returns an up-to-date flow field for the goal.
+func (c *CooperativeAStar) getHeuristic(g *Grid, l GridLayer, goal GridCoord) *FlowField {
+	if c.grid != g || c.layer != l {
+		c.grid = g
+		c.layer = l
+		c.heuristics = c.heuristics[:0]
+	}
+	c.useCounter++
+
+	for i := range c.heuristics {
+		h := &c.heuristics[i]
+		if h.goal == goal {
+			h.lastUsed = c.useCounter
+			h.field.Update()
+			return h.field
+		}
+	}
+
+	var h *cooperativeHeuristic
+	if len(c.heuristics) < c.maxHeuristics {
+		c.heuristics = append(c.heuristics, cooperativeHeuristic{
+			field: NewFlowField(g, l, FlowFieldConfig{}),
+		})
+		h = &c.heuristics[len(c.heuristics)-1]
+	} else {
+		h = &c.heuristics[0]
+		for i := range c.heuristics {
+			if c.heuristics[i].lastUsed < h.lastUsed {
+				h = &c.heuristics[i]
+			}
+		}
+	}
+	h.goal = goal
+	h.lastUsed = c.useCounter
+	h.field.Build(goal)
+	return h.field
+}
+
+func (r *cooperativeRules) canEnter(from, to GridCoord, tick int) bool {
+	globalTick := r.tick + tick
+	if r.table.reservedByOther(r.agent, to, globalTick) {
+		return false
+	}
+	return from == to || !r.table.isSwap(r.agent, from, to, globalTick-1)
+}
+
+func (r *cooperativeRules) canFinish(goal GridCoord, tick int) bool {
+	// The agent is going to stay at its goal, so it should not
+	// get in the way of anyone during the rest of the window.
+	for t := r.tick + tick + 1; t <= r.tick+r.window; t++ {
+		if r.table.reservedByOther(r.agent, goal, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CooperativeAStar) constructPath(finish spaceTimeCoord, dst *LongGridPath) {
+	n := int(finish.Tick)
+	if cap(dst.bytes) < n {
+		dst.bytes = make([]byte, n)
+	}
+	dst.bytes = dst.bytes[:n]
+	dst.pos = 0
+
+	// Fill the path in reversed order.
+	pos := finish.Coord
+	for t := n; t > 0; t-- {
+		d := c.search.actionAt(pos, t)
+		dst.bytes[t-1] = byte(d)
+		pos = pos.reversedMove(d)
+	}
+}
@@ -0,0 +1,229 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestReservationTable(t *testing.T) {
+	table := pathing.NewReservationTable()
+	a := pathing.GridCoord{X: 1, Y: 1}
+	if !table.Reserve(1, a, 0) {
+		t.Fatal("can't reserve a free cell")
+	}
+	if !table.Reserve(1, a, 0) {
+		t.Fatal("can't reserve a cell twice for the same agent")
+	}
+	if table.Reserve(2, a, 0) {
+		t.Fatal("reserved a cell that is occupied by another agent")
+	}
+	if !table.Reserve(2, a, 1) {
+		t.Fatal("can't reserve a cell that is free during that tick")
+	}
+
+	path := pathing.MakeLongGridPath(nil)
+	path.Push(pathing.DirRight)
+	path.Push(pathing.DirNone)
+	path.Push(pathing.DirDown)
+	if !table.ReservePath(3, pathing.GridCoord{X: 5, Y: 5}, 10, &path) {
+		t.Fatal("can't reserve a path")
+	}
+	tests := []struct {
+		c     pathing.GridCoord
+		tick  int
+		agent int
+		ok    bool
+	}{
+		{pathing.GridCoord{X: 5, Y: 5}, 10, 3, true},
+		{pathing.GridCoord{X: 6, Y: 5}, 11, 3, true},
+		{pathing.GridCoord{X: 6, Y: 5}, 12, 3, true},
+		{pathing.GridCoord{X: 6, Y: 6}, 13, 3, true},
+		{pathing.GridCoord{X: 6, Y: 6}, 14, 0, false},
+		{a, 0, 1, true},
+		{a, 1, 2, true},
+	}
+	check := func() {
+		t.Helper()
+		for _, test := range tests {
+			agent, ok := table.ReservedBy(test.c, test.tick)
+			if agent != test.agent || ok != test.ok {
+				t.Fatalf("ReservedBy(%v, %d): have (%d, %v), want (%d, %v)", test.c, test.tick, agent, ok, test.agent, test.ok)
+			}
+		}
+	}
+	check()
+
+	table.Release(3)
+	for i := 0; i < 4; i++ {
+		tests[i].agent = 0
+		tests[i].ok = false
+	}
+	check()
+
+	table.Reset()
+	if _, ok := table.ReservedBy(a, 0); ok {
+		t.Fatal("reset table is not empty")
+	}
+}
+
+func TestCooperativeAStar(t *testing.T) {
+	// The agents are moving towards each other.
+	// One of them needs to step aside and wait.
+	parseResult := testParseGrid(t, []string{
+		"xxxxx.x",
+		".......",
+		"xxxxxxx",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	type agentInfo struct {
+		from pathing.GridCoord
+		to   pathing.GridCoord
+		path pathing.LongGridPath
+	}
+	agents := []*agentInfo{
+		{from: pathing.GridCoord{X: 0, Y: 1}, to: pathing.GridCoord{X: 6, Y: 1}},
+		{from: pathing.GridCoord{X: 6, Y: 1}, to: pathing.GridCoord{X: 0, Y: 1}},
+	}
+
+	const window = 16
+	table := pathing.NewReservationTable()
+	planner := pathing.NewCooperativeAStar(pathing.CooperativeAStarConfig{Window: window})
+	for id, a := range agents {
+		result := planner.BuildPath(g, a.from, a.to, l, table, id, 0, &a.path)
+		if result.Partial || result.Finish != a.to {
+			t.Fatalf("agent%d: goal is not reached: %v (%s)", id, result.Finish, a.path.String())
+		}
+		if !table.ReservePath(id, a.from, 0, &a.path) {
+			t.Fatalf("agent%d: path reservation failed", id)
+		}
+		for tick := a.path.Len() + 1; tick <= window; tick++ {
+			table.Reserve(id, a.to, tick)
+		}
+	}
+
+	if agents[0].path.Len() != 6 {
+		t.Fatalf("the first agent should take a direct path, got %s", agents[0].path.String())
+	}
+	waits := 0
+	for agents[1].path.HasNext() {
+		if agents[1].path.Next() == pathing.DirNone {
+			waits++
+		}
+	}
+	if waits == 0 {
+		t.Fatalf("the second agent should wait, got %s", agents[1].path.String())
+	}
+
+	// Simulate the movement and check the collisions.
+	positions := make([]pathing.GridCoord, len(agents))
+	for i, a := range agents {
+		positions[i] = a.from
+		a.path.Rewind()
+	}
+	for tick := 1; tick <= window; tick++ {
+		prev := append([]pathing.GridCoord(nil), positions...)
+		for i, a := range agents {
+			if a.path.HasNext() {
+				positions[i] = positions[i].Move(a.path.Next())
+			}
+			if g.GetCellCost(positions[i], l) == 0 {
+				t.Fatalf("tick%d: agent%d is at the impassable cell %v", tick, i, positions[i])
+			}
+		}
+		if positions[0] == positions[1] {
+			t.Fatalf("tick%d: vertex conflict at %v", tick, positions[0])
+		}
+		if positions[0] == prev[1] && positions[1] == prev[0] {
+			t.Fatalf("tick%d: edge conflict between %v and %v", tick, prev[0], prev[1])
+		}
+	}
+	for i, a := range agents {
+		if positions[i] != a.to {
+			t.Fatalf("agent%d ended up at %v", i, positions[i])
+		}
+	}
+}
+
+func TestCooperativeAStarPartial(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		"..........",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	table := pathing.NewReservationTable()
+	planner := pathing.NewCooperativeAStar(pathing.CooperativeAStarConfig{Window: 4})
+	var path pathing.LongGridPath
+
+	from := pathing.GridCoord{X: 0, Y: 0}
+	to := pathing.GridCoord{X: 9, Y: 0}
+	result := planner.BuildPath(g, from, to, l, table, 0, 0, &path)
+	if !result.Partial || path.Len() != 4 || result.Finish != (pathing.GridCoord{X: 4, Y: 0}) {
+		t.Fatalf("unexpected result: %v partial=%v (%s)", result.Finish, result.Partial, path.String())
+	}
+
+	// The goal is occupied by someone else at the end of the window.
+	to = pathing.GridCoord{X: 2, Y: 0}
+	table.Reserve(1, to, 4)
+	result = planner.BuildPath(g, from, to, l, table, 0, 0, &path)
+	if !result.Partial || path.Len() != 4 {
+		t.Fatalf("unexpected result: %v partial=%v (%s)", result.Finish, result.Partial, path.String())
+	}
+
+	// All neighbors are reserved: the agent can only wait.
+	table.Reset()
+	table.Reserve(1, pathing.GridCoord{X: 1, Y: 0}, 1)
+	table.Reserve(1, pathing.GridCoord{X: 0, Y: 1}, 1)
+	result = planner.BuildPath(g, from, to, l, table, 0, 0, &path)
+	if result.Partial || path.Peek() != pathing.DirNone || !path.HasNext() {
+		t.Fatalf("unexpected result: %v partial=%v (%s)", result.Finish, result.Partial, path.String())
+	}
+}
+
+func TestCooperativeAStarHeuristicCache(t *testing.T) {
+	// Without the reservations, the planner should find the optimal paths.
+	// There are more goals than the cache can hold and the grid
+	// is changing, so the cached heuristics are evicted and repaired.
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	table := pathing.NewReservationTable()
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	var want pathing.LongGridPath
+	var have pathing.LongGridPath
+	const window = 32
+	for i := 0; i < 20; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 8)
+		planner := pathing.NewCooperativeAStar(pathing.CooperativeAStarConfig{
+			Window:             window,
+			HeuristicCacheSize: 2,
+		})
+		goals := make([]pathing.GridCoord, 3)
+		for j := range goals {
+			goals[j] = pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		}
+		for round := 0; round < 20; round++ {
+			changed := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			g.SetCellTile(changed, uint8(rng.Intn(4)))
+			planner.NotifyCellChanged(changed)
+
+			from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			to := goals[rng.Intn(len(goals))]
+			wantResult := astar.BuildLongPath(g, from, to, l, &want)
+			if want.Len() > window {
+				continue
+			}
+			haveResult := planner.BuildPath(g, from, to, l, table, 0, 0, &have)
+			if haveResult.Partial != wantResult.Partial {
+				t.Fatalf("test%d: round%d: %v=>%v partial flag mismatch:\nhave: %v\nwant: %v", i, round, from, to, haveResult.Partial, wantResult.Partial)
+			}
+			if !haveResult.Partial && haveResult.Cost != wantResult.Cost {
+				t.Fatalf("test%d: round%d: %v=>%v cost mismatch:\nhave: %v\nwant: %v", i, round, from, to, haveResult.Cost, wantResult.Cost)
+			}
+		}
+	}
+}
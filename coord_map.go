@@ -26,6 +26,26 @@ func newCoordMap(numCols, numRows int) *coordMap {
 	}
 }
 
+// resizeCoordMap returns a coord map of the requested size.
+// It re-uses m if possible.
+func resizeCoordMap(m *coordMap, numCols, numRows int) *coordMap {
+	if m == nil {
+		return newCoordMap(numCols, numRows)
+	}
+	if m.numCols == numCols && m.numRows == numRows {
+		return m
+	}
+	size := numRows * numCols
+	if cap(m.elems) < size {
+		return newCoordMap(numCols, numRows)
+	}
+	m.elems = m.elems[:size]
+	m.numCols = numCols
+	m.numRows = numRows
+	m.clear()
+	return m
+}
+
 func (m *coordMap) Contains(k uint) bool {
 	if k < uint(len(m.elems)) {
 		return m.elems[k].gen == m.gen
@@ -78,3 +98,7 @@ func (m *coordMap) clear() {
 func (s *coordMap) packCoord(c GridCoord) uint {
 	return uint((c.Y * s.numCols) + c.X)
 }
+
+func (s *coordMap) unpackCoord(k uint) GridCoord {
+	return GridCoord{X: int(k) % s.numCols, Y: int(k) / s.numCols}
+}
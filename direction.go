@@ -1,6 +1,10 @@
 package pathing
 
-// Direction is a simple enumeration of axial movement directions.
+// Direction is a simple enumeration of movement directions.
+//
+// The first four values are axial directions.
+// The diagonal directions are only produced by pathfinders
+// that have the diagonal movement mode enabled.
 type Direction int
 
 //go:generate stringer -type=Direction -trimprefix=Dir
@@ -9,11 +13,16 @@ const (
 	DirDown
 	DirLeft
 	DirUp
+	DirDownRight
+	DirDownLeft
+	DirUpLeft
+	DirUpRight
 	DirNone // A special sentinel value
 )
 
 // Reversed returns an opposite direction.
-// For instance, DirRight would become DirLeft.
+// For instance, DirRight would become DirLeft
+// and DirDownRight would become DirUpLeft.
 func (d Direction) Reversed() Direction {
 	switch d {
 	case DirRight:
@@ -24,6 +33,44 @@ func (d Direction) Reversed() Direction {
 		return DirRight
 	case DirUp:
 		return DirDown
+	case DirDownRight:
+		return DirUpLeft
+	case DirDownLeft:
+		return DirUpRight
+	case DirUpLeft:
+		return DirDownRight
+	case DirUpRight:
+		return DirDownLeft
+	default:
+		return DirNone
+	}
+}
+
+// IsDiagonal reports whether d is one of the diagonal directions.
+func (d Direction) IsDiagonal() bool {
+	return d >= DirDownRight && d <= DirUpRight
+}
+
+// directionFromDelta returns a direction that leads from {0, 0} towards {dx, dy}.
+// Only the delta signs matter.
+func directionFromDelta(dx, dy int) Direction {
+	switch {
+	case dx > 0 && dy > 0:
+		return DirDownRight
+	case dx < 0 && dy > 0:
+		return DirDownLeft
+	case dx < 0 && dy < 0:
+		return DirUpLeft
+	case dx > 0 && dy < 0:
+		return DirUpRight
+	case dx > 0:
+		return DirRight
+	case dx < 0:
+		return DirLeft
+	case dy > 0:
+		return DirDown
+	case dy < 0:
+		return DirUp
 	default:
 		return DirNone
 	}
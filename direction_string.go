@@ -12,12 +12,16 @@ func _() {
 	_ = x[DirDown-1]
 	_ = x[DirLeft-2]
 	_ = x[DirUp-3]
-	_ = x[DirNone-4]
+	_ = x[DirDownRight-4]
+	_ = x[DirDownLeft-5]
+	_ = x[DirUpLeft-6]
+	_ = x[DirUpRight-7]
+	_ = x[DirNone-8]
 }
 
-const _Direction_name = "RightDownLeftUpNone"
+const _Direction_name = "RightDownLeftUpDownRightDownLeftUpLeftUpRightNone"
 
-var _Direction_index = [...]uint8{0, 5, 9, 13, 15, 19}
+var _Direction_index = [...]uint8{0, 5, 9, 13, 15, 24, 32, 38, 45, 49}
 
 func (i Direction) String() string {
 	if i < 0 || i >= Direction(len(_Direction_index)-1) {
@@ -0,0 +1,352 @@
+package pathing
+
+import (
+	"math"
+)
+
+// DStarLite implements a D* Lite incremental pathfinding algorithm.
+// You must use NewDStarLite() function to obtain an instance of this type.
+//
+// It's intended for the agents that move through a changing grid.
+// The search state is kept between the BuildPath calls,
+// so the path can be repaired after the grid changes
+// without re-running the search from scratch.
+//
+// The search goes backwards: from the goal to the agent.
+// Only the cells that could affect the current path are re-visited
+// when the grid changes around the agent.
+//
+// The planner is bound to a specific Grid and GridLayer pair and it
+// covers the entire grid, so it needs 8 bytes per Grid cell.
+// Since the paths are not limited by the distance, LongGridPath is used for the results.
+//
+// A typical usage looks like this:
+//
+//	planner.Reset(unitPos, targetPos)
+//	planner.BuildPath(&path)
+//	// ...the unit makes a step and the grid changes.
+//	planner.SetStart(unitPos)
+//	planner.NotifyCellChanged(changedCell)
+//	planner.BuildPath(&path)
+type DStarLite struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols int
+	numRows int
+
+	// g is a cost-to-goal estimate, rhs is its one-step lookahead value.
+	// A cell is consistent if these values are equal.
+	g   []uint32
+	rhs []uint32
+
+	start     GridCoord
+	lastStart GridCoord
+	goal      int
+	km        uint32
+
+	frontier *minheap[dstarNode]
+	changed  []GridCoord
+
+	moveCosts
+}
+
+type DStarLiteConfig struct {
+	// Diagonal enables the 8-directional movement mode.
+	// See AStarConfig.Diagonal comment.
+	Diagonal bool
+
+	// CornerCutting relaxes the diagonal movement rules.
+	// See AStarConfig.CornerCutting comment.
+	CornerCutting bool
+
+	// DiagonalCost is a diagonal step cost multiplier.
+	// See AStarConfig.DiagonalCost comment.
+	DiagonalCost float64
+}
+
+type dstarNode struct {
+	index uint32
+	key   uint64
+}
+
+const dstarInf = math.MaxUint32
+
+// NewDStarLite creates a planner for the given grid and layer.
+// The planner has no goal, use Reset method to initialize it.
+func NewDStarLite(g *Grid, l GridLayer, config DStarLiteConfig) *DStarLite {
+	numCells := g.numCols * g.numRows
+	d := &DStarLite{
+		grid:     g,
+		layer:    l,
+		numCols:  int(g.numCols),
+		numRows:  int(g.numRows),
+		g:        make([]uint32, numCells),
+		rhs:      make([]uint32, numCells),
+		goal:     -1,
+		frontier: newMinheap[dstarNode](64),
+
+		moveCosts: makeMoveCosts(config.Diagonal, config.DiagonalCost, config.CornerCutting),
+	}
+
+	for i := range d.g {
+		d.g[i] = dstarInf
+		d.rhs[i] = dstarInf
+	}
+
+	return d
+}
+
+// Reset discards the previous search state and starts planning
+// a path between the two coordinates.
+// It's an O(n) operation, where n is a number of grid cells.
+//
+// The search itself is performed during the BuildPath call.
+func (d *DStarLite) Reset(from, to GridCoord) {
+	for i := range d.g {
+		d.g[i] = dstarInf
+		d.rhs[i] = dstarInf
+	}
+	d.frontier.Reset()
+	d.changed = d.changed[:0]
+	d.km = 0
+	d.start = from
+	d.lastStart = from
+
+	d.goal = -1
+	if d.contains(to) {
+		d.goal = d.index(to)
+		d.rhs[d.goal] = 0
+		d.push(d.goal)
+	}
+}
+
+// SetStart updates the agent position.
+// It's usually called after the agent follows a path step,
+// but the new position doesn't have to be adjacent to the old one.
+func (d *DStarLite) SetStart(pos GridCoord) {
+	d.start = pos
+}
+
+// NotifyCellChanged tells the planner that the cell tile tag
+// or its blocked status was changed.
+// The changes are applied during the next BuildPath call.
+func (d *DStarLite) NotifyCellChanged(c GridCoord) {
+	if d.contains(c) {
+		d.changed = append(d.changed, c)
+	}
+}
+
+// BuildPath writes the path from the current start to the goal into dst.
+// The pending cell changes are applied before that.
+//
+// The first call after Reset performs a full search,
+// the next calls only repair the affected parts of the search state.
+//
+// If the goal can't be reached, a partial result with an empty path is returned.
+func (d *DStarLite) BuildPath(dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	dst.Reset()
+	result.Finish = d.start
+	result.Partial = true
+	if d.goal == -1 || !d.contains(d.start) {
+		return result
+	}
+
+	d.applyChanges()
+	d.computeShortestPath()
+
+	i := d.index(d.start)
+	if d.rhs[i] == dstarInf {
+		return result
+	}
+
+	// Following the cheapest step always leads to the goal,
+	// the steps limit is just a precaution.
+	pos := d.start
+	for steps := 0; i != d.goal && steps < len(d.g); steps++ {
+		dir, next := d.bestStep(pos)
+		if dir == DirNone {
+			return result
+		}
+		dst.Push(dir)
+		pos = next
+		i = d.index(pos)
+	}
+	if i != d.goal {
+		dst.Reset()
+		return result
+	}
+
+	result.Finish = pos
+	result.Cost = d.unscaleCost(d.rhs[d.index(d.start)])
+	result.Partial = false
+	return result
+}
+
+func (d *DStarLite) applyChanges() {
+	// The keys of the cells that are already queued are
+	// computed relative to the last start position.
+	// Instead of re-computing all of them, the km offset is used.
+	if d.lastStart != d.start {
+		d.km += uint32(d.heuristic(d.lastStart, d.start))
+		d.lastStart = d.start
+	}
+
+	// A changed cell affects the edges that enter it
+	// (and the diagonal edges that squeeze by it).
+	// All of these edges start from its neighbors.
+	for _, c := range d.changed {
+		for _, offset := range neighborOffsets[:d.numNeighbors] {
+			neighbor := c.Add(offset)
+			if d.contains(neighbor) {
+				d.updateCell(d.index(neighbor))
+			}
+		}
+	}
+	d.changed = d.changed[:0]
+}
+
+func (d *DStarLite) computeShortestPath() {
+	start := d.index(d.start)
+	frontier := d.frontier
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+		if current.key >= d.calcKey(start) && d.g[start] == d.rhs[start] {
+			frontier.Push(int(current.key), current)
+			break
+		}
+
+		i := int(current.index)
+		if d.g[i] == d.rhs[i] {
+			// An outdated queue entry.
+			continue
+		}
+		if key := d.calcKey(i); current.key < key {
+			frontier.Push(int(key), dstarNode{index: current.index, key: key})
+			continue
+		}
+
+		if d.g[i] > d.rhs[i] {
+			d.g[i] = d.rhs[i]
+		} else {
+			d.g[i] = dstarInf
+			d.updateCell(i)
+		}
+		x := i % d.numCols
+		y := i / d.numCols
+		for _, offset := range neighborOffsets[:d.numNeighbors] {
+			nx := uint(x + offset.X)
+			ny := uint(y + offset.Y)
+			if nx >= uint(d.numCols) || ny >= uint(d.numRows) {
+				continue
+			}
+			d.updateCell(int(ny)*d.numCols + int(nx))
+		}
+	}
+}
+
+// updateCell recomputes the rhs value of the cell and
+// puts it into the queue if it became inconsistent.
+func (d *DStarLite) updateCell(i int) {
+	if i != d.goal {
+		x := uint(i % d.numCols)
+		y := uint(i / d.numCols)
+		best := uint32(dstarInf)
+		for dir := 0; dir < d.numNeighbors; dir++ {
+			cost := d.stepCost(x, y, dir)
+			if cost == dstarInf {
+				continue
+			}
+			offset := neighborOffsets[dir]
+			j := int(y+uint(offset.Y))*d.numCols + int(x+uint(offset.X))
+			if d.g[j] == dstarInf {
+				continue
+			}
+			if v := cost + d.g[j]; v < best {
+				best = v
+			}
+		}
+		d.rhs[i] = best
+	}
+	if d.g[i] != d.rhs[i] {
+		d.push(i)
+	}
+}
+
+// bestStep selects the cheapest step from pos towards the goal.
+func (d *DStarLite) bestStep(pos GridCoord) (Direction, GridCoord) {
+	x := uint(pos.X)
+	y := uint(pos.Y)
+	bestDir := DirNone
+	best := uint32(dstarInf)
+	for dir := 0; dir < d.numNeighbors; dir++ {
+		cost := d.stepCost(x, y, dir)
+		if cost == dstarInf {
+			continue
+		}
+		j := d.index(pos.Add(neighborOffsets[dir]))
+		if d.g[j] == dstarInf {
+			continue
+		}
+		if v := cost + d.g[j]; v < best {
+			best = v
+			bestDir = Direction(dir)
+		}
+	}
+	if bestDir == DirNone {
+		return DirNone, pos
+	}
+	return bestDir, pos.Move(bestDir)
+}
+
+// stepCost returns the cost of the step from {x, y} in the given direction.
+// It returns dstarInf if the step is impossible.
+func (d *DStarLite) stepCost(x, y uint, dir int) uint32 {
+	g := d.grid
+	offset := neighborOffsets[dir]
+	nx := x + uint(offset.X)
+	ny := y + uint(offset.Y)
+	if nx >= g.numCols || ny >= g.numRows {
+		return dstarInf
+	}
+	cellCost := uint32(g.getCellCost(nx, ny, d.layer))
+	if cellCost == 0 {
+		return dstarInf
+	}
+	if dir >= int(DirDownRight) {
+		if !g.canMoveDiagonally(x, y, offset, d.layer, d.cornerCutting) {
+			return dstarInf
+		}
+		return cellCost * d.diagonalCost
+	}
+	return cellCost * d.axialCost
+}
+
+func (d *DStarLite) push(i int) {
+	key := d.calcKey(i)
+	d.frontier.Push(int(key), dstarNode{index: uint32(i), key: key})
+}
+
+// calcKey returns a cell priority.
+// The primary key is stored in the high bits, so the
+// keys can be compared as integers.
+func (d *DStarLite) calcKey(i int) uint64 {
+	v := d.g[i]
+	if d.rhs[i] < v {
+		v = d.rhs[i]
+	}
+	if v == dstarInf {
+		return math.MaxInt64
+	}
+	h := uint32(d.heuristic(d.start, GridCoord{X: i % d.numCols, Y: i / d.numCols}))
+	return uint64(v+h+d.km)<<32 | uint64(v)
+}
+
+func (d *DStarLite) contains(c GridCoord) bool {
+	return uint(c.X) < uint(d.numCols) && uint(c.Y) < uint(d.numRows)
+}
+
+func (d *DStarLite) index(c GridCoord) int {
+	return c.Y*d.numCols + c.X
+}
@@ -0,0 +1,123 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkDStarLiteRepair(b *testing.B) {
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 64 * 32, WorldHeight: 64 * 32})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	d := pathing.NewDStarLite(g, l, pathing.DStarLiteConfig{})
+	d.Reset(pathing.GridCoord{X: 2, Y: 32}, pathing.GridCoord{X: 60, Y: 32})
+	var path pathing.LongGridPath
+	d.BuildPath(&path)
+	c := pathing.GridCoord{X: 30, Y: 32}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.SetCellIsBlocked(c, i%2 == 0)
+		d.NotifyCellChanged(c)
+		d.BuildPath(&path)
+	}
+}
+
+func TestDStarLite(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		".A...x..$.",
+		".....x....",
+		".....x....",
+		"..........",
+	})
+	g := parseResult.grid
+	from := parseResult.start
+	to := parseResult.dest
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	d := pathing.NewDStarLite(g, l, pathing.DStarLiteConfig{})
+	d.Reset(from, to)
+	var path pathing.LongGridPath
+
+	result := d.BuildPath(&path)
+	checkLongPath(t, g, l, from, result, &path)
+	if result.Partial || result.Cost != 9 {
+		t.Fatalf("unexpected result: cost=%d partial=%v path=%s", result.Cost, result.Partial, path.String())
+	}
+
+	// Close the top passage: the path should go around the bottom.
+	g.SetCellIsBlocked(pathing.GridCoord{X: 5, Y: 0}, true)
+	d.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 0})
+	result = d.BuildPath(&path)
+	checkLongPath(t, g, l, from, result, &path)
+	if result.Partial || result.Cost != 13 {
+		t.Fatalf("unexpected result: cost=%d partial=%v path=%s", result.Cost, result.Partial, path.String())
+	}
+
+	// Make a step and close the bottom passage too.
+	from = from.Move(path.Next())
+	d.SetStart(from)
+	g.SetCellIsBlocked(pathing.GridCoord{X: 5, Y: 4}, true)
+	d.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 4})
+	result = d.BuildPath(&path)
+	if !result.Partial || result.Finish != from || path.Len() != 0 {
+		t.Fatalf("expected an empty partial result, got a path to %v", result.Finish)
+	}
+
+	// Turn the wall in the middle into a water tile.
+	g.SetCellTile(pathing.GridCoord{X: 5, Y: 2}, 3)
+	d.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 2})
+	result = d.BuildPath(&path)
+	checkLongPath(t, g, l, from, result, &path)
+	if result.Partial || result.Finish != to {
+		t.Fatalf("unexpected result: finish=%v partial=%v", result.Finish, result.Partial)
+	}
+}
+
+func TestDStarLiteRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	var path pathing.LongGridPath
+	var wantPath pathing.LongGridPath
+	for i := 0; i < 200; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 40)
+		diagonal := rng.Intn(2) == 0
+		d := pathing.NewDStarLite(g, l, pathing.DStarLiteConfig{Diagonal: diagonal})
+		astar := pathing.NewAStar(pathing.AStarConfig{Diagonal: diagonal})
+
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		d.Reset(from, to)
+		for j := 0; j < 10; j++ {
+			numChanges := rng.Intn(5)
+			for k := 0; k < numChanges; k++ {
+				c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				g.SetCellTile(c, uint8(rng.Intn(4)))
+				d.NotifyCellChanged(c)
+			}
+
+			have := d.BuildPath(&path)
+			want := astar.BuildLongPath(g, from, to, l, &wantPath)
+			if have.Partial != want.Partial {
+				t.Fatalf("test%d.%d: %v->%v: partial flag mismatch: have %v, want %v", i, j, from, to, have.Partial, want.Partial)
+			}
+			if have.Partial {
+				continue
+			}
+			checkLongPath(t, g, l, from, have, &path)
+			if have.Cost != want.Cost {
+				t.Fatalf("test%d.%d: %v->%v: have cost=%d, want cost=%d", i, j, from, to, have.Cost, want.Cost)
+			}
+
+			// Follow the path for a few steps.
+			path.Rewind()
+			for n := rng.Intn(4); n > 0 && path.HasNext(); n-- {
+				from = from.Move(path.Next())
+			}
+			d.SetStart(from)
+		}
+	}
+}
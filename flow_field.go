@@ -0,0 +1,322 @@
+package pathing
+
+import (
+	"math"
+)
+
+// FlowField is a Dijkstra map (also known as an integrated cost field).
+// You must use NewFlowField() function to obtain an instance of this type.
+//
+// It stores the travelling cost from every grid cell to the nearest goal,
+// so any number of units can find their way to the goal with
+// O(1) NextDirection() calls. This is much cheaper than building
+// an individual path for every unit that chases the same target.
+//
+// The field is bound to a specific Grid and GridLayer pair and it covers
+// the entire grid, so it needs 5 bytes per Grid cell.
+//
+// When the grid changes, use NotifyCellChanged + Update to
+// repair the field incrementally instead of rebuilding it from scratch.
+type FlowField struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols int
+	numRows int
+
+	dists []uint32
+	dirs  []uint8
+
+	goals   []GridCoord
+	changed []GridCoord
+
+	frontier    *minheap[flowFieldNode]
+	invalidated []uint32
+
+	moveCosts
+}
+
+type FlowFieldConfig struct {
+	// Diagonal enables the 8-directional movement mode.
+	// See AStarConfig.Diagonal comment.
+	Diagonal bool
+
+	// CornerCutting relaxes the diagonal movement rules.
+	// See AStarConfig.CornerCutting comment.
+	CornerCutting bool
+
+	// DiagonalCost is a diagonal step cost multiplier.
+	// See AStarConfig.DiagonalCost comment.
+	DiagonalCost float64
+}
+
+const flowFieldUnreachable = math.MaxUint32
+
+// NewFlowField creates a flow field for the given grid and layer.
+// The field has no goals, use Build method to compute it.
+func NewFlowField(g *Grid, l GridLayer, config FlowFieldConfig) *FlowField {
+	numCells := g.numCols * g.numRows
+	f := &FlowField{
+		grid:     g,
+		layer:    l,
+		numCols:  int(g.numCols),
+		numRows:  int(g.numRows),
+		dists:    make([]uint32, numCells),
+		dirs:     make([]uint8, numCells),
+		frontier: newMinheap[flowFieldNode](64),
+
+		moveCosts: makeMoveCosts(config.Diagonal, config.DiagonalCost, config.CornerCutting),
+	}
+
+	f.reset()
+
+	return f
+}
+
+// Build computes the flow field for the given set of goals.
+// It's an O(n*log(n)) operation, where n is a number of reachable cells.
+//
+// A goal cell is always considered to be enterable even if
+// the layer marks it as blocked; a cost of 1 is used in that case.
+// This makes it possible to use buildings and other obstacles as goals.
+//
+// The out-of-bounds goals are ignored.
+func (f *FlowField) Build(goals ...GridCoord) {
+	f.goals = append(f.goals[:0], goals...)
+	f.changed = f.changed[:0]
+	f.reset()
+
+	f.frontier.Reset()
+	for _, goal := range f.goals {
+		if !f.contains(goal) {
+			continue
+		}
+		i := f.index(goal)
+		f.dists[i] = 0
+		f.frontier.Push(0, flowFieldNode{index: uint32(i)})
+	}
+	f.propagate()
+}
+
+// NotifyCellChanged tells the flow field that the cell tile tag
+// or its blocked status was changed.
+// The changes are applied during the next Update call.
+func (f *FlowField) NotifyCellChanged(c GridCoord) {
+	if f.contains(c) {
+		f.changed = append(f.changed, c)
+	}
+}
+
+// Update repairs the flow field after the grid changes.
+// See NotifyCellChanged.
+//
+// Only the cells that could be affected by the changes are recomputed.
+func (f *FlowField) Update() {
+	if len(f.changed) == 0 {
+		return
+	}
+
+	f.frontier.Reset()
+	invalidated := f.invalidated[:0]
+
+	// Every cell that could lead through a changed cell
+	// (or squeeze by it diagonally) needs to be recomputed.
+	for _, c := range f.changed {
+		invalidated = f.invalidate(invalidated, c)
+		if f.numNeighbors == 8 {
+			for _, offset := range &neighborOffsets {
+				invalidated = f.invalidate(invalidated, c.Add(offset))
+			}
+		}
+	}
+
+	// The valid cells around the affected area are the new starting points.
+	// The changed cells are seeded too: their cost decrease
+	// could make some paths shorter.
+	for _, i := range invalidated {
+		f.seed(f.coord(int(i)))
+	}
+	for _, c := range f.changed {
+		f.seed(c)
+	}
+
+	f.invalidated = invalidated[:0]
+	f.changed = f.changed[:0]
+
+	f.propagate()
+}
+
+// Distance returns the travelling cost from c to the nearest goal.
+// The second result is false if no goal can be reached from c.
+//
+// Like with BuildPath, the c cell itself doesn't need to be passable:
+// a unit standing on a blocked cell can still leave it.
+//
+// It's an O(1) operation.
+func (f *FlowField) Distance(c GridCoord) (int, bool) {
+	if !f.contains(c) {
+		return 0, false
+	}
+	d := f.dists[f.index(c)]
+	if d == flowFieldUnreachable {
+		return 0, false
+	}
+	return f.unscaleCost(d), true
+}
+
+// NextDirection returns the direction of the next step from c
+// towards the nearest goal.
+// It returns DirNone if c is a goal or if no goal can be reached.
+//
+// It's an O(1) operation.
+func (f *FlowField) NextDirection(c GridCoord) Direction {
+	if !f.contains(c) {
+		return DirNone
+	}
+	return Direction(f.dirs[f.index(c)])
+}
+
+// seed pushes c and its neighbors that have a known distance to the frontier.
+func (f *FlowField) seed(c GridCoord) {
+	i := f.index(c)
+	if f.dists[i] != flowFieldUnreachable {
+		f.frontier.Push(int(f.dists[i]), flowFieldNode{index: uint32(i), dist: f.dists[i]})
+	}
+	for _, offset := range neighborOffsets[:f.numNeighbors] {
+		neighbor := c.Add(offset)
+		if !f.contains(neighbor) {
+			continue
+		}
+		j := f.index(neighbor)
+		if f.dists[j] != flowFieldUnreachable {
+			f.frontier.Push(int(f.dists[j]), flowFieldNode{index: uint32(j), dist: f.dists[j]})
+		}
+	}
+}
+
+func (f *FlowField) reset() {
+	for i := range f.dists {
+		f.dists[i] = flowFieldUnreachable
+		f.dirs[i] = uint8(DirNone)
+	}
+}
+
+// invalidate marks the c cell and all cells whose path goes
+// through it as unreachable.
+func (f *FlowField) invalidate(invalidated []uint32, c GridCoord) []uint32 {
+	if !f.contains(c) {
+		return invalidated
+	}
+	i := f.index(c)
+	if f.dists[i] == flowFieldUnreachable {
+		// Already invalidated (or was never reachable).
+		return invalidated
+	}
+
+	start := len(invalidated)
+	if f.dists[i] == 0 {
+		// Goals keep their distance, but the paths that enter them don't.
+		invalidated = f.invalidateChildren(invalidated, c)
+	} else {
+		f.dists[i] = flowFieldUnreachable
+		f.dirs[i] = uint8(DirNone)
+		invalidated = append(invalidated, uint32(i))
+	}
+
+	// The invalidated slice is used as a queue here.
+	for head := start; head < len(invalidated); head++ {
+		invalidated = f.invalidateChildren(invalidated, f.coord(int(invalidated[head])))
+	}
+
+	return invalidated
+}
+
+// invalidateChildren marks the cells that make their next step into parent as unreachable.
+func (f *FlowField) invalidateChildren(invalidated []uint32, parent GridCoord) []uint32 {
+	for _, offset := range neighborOffsets[:f.numNeighbors] {
+		child := parent.Add(offset)
+		if !f.contains(child) {
+			continue
+		}
+		j := f.index(child)
+		if f.dists[j] == flowFieldUnreachable || f.dists[j] == 0 {
+			continue
+		}
+		if child.Move(Direction(f.dirs[j])) != parent {
+			continue
+		}
+		f.dists[j] = flowFieldUnreachable
+		f.dirs[j] = uint8(DirNone)
+		invalidated = append(invalidated, uint32(j))
+	}
+	return invalidated
+}
+
+// propagate runs the Dijkstra algorithm starting from the frontier cells.
+// The search goes backwards: from the goals to the other cells.
+func (f *FlowField) propagate() {
+	g := f.grid
+	frontier := f.frontier
+	for !frontier.IsEmpty() {
+		node := frontier.Pop()
+		i := node.index
+		currentDist := f.dists[i]
+		if node.dist > currentDist {
+			// A shorter path to this cell was found after this node was pushed.
+			continue
+		}
+		current := f.coord(int(i))
+
+		// Entering the current cell costs this much.
+		enterCost := uint32(g.getCellCost(uint(current.X), uint(current.Y), f.layer))
+		if enterCost == 0 {
+			if currentDist != 0 {
+				// This cell can be left, but it can't be entered.
+				continue
+			}
+			// Only the goals can be entered regardless of the cost.
+			enterCost = 1
+		}
+
+		for dir, offset := range neighborOffsets[:f.numNeighbors] {
+			next := current.Add(offset)
+			cx := uint(next.X)
+			cy := uint(next.Y)
+			if cx >= g.numCols || cy >= g.numRows {
+				continue
+			}
+			stepCost := f.axialCost
+			if dir >= int(DirDownRight) {
+				if !g.canMoveDiagonally(cx, cy, neighborOffsets[Direction(dir).Reversed()], f.layer, f.cornerCutting) {
+					continue
+				}
+				stepCost = f.diagonalCost
+			}
+			newDist := currentDist + enterCost*stepCost
+			j := f.index(next)
+			if newDist >= f.dists[j] {
+				continue
+			}
+			f.dists[j] = newDist
+			f.dirs[j] = uint8(Direction(dir).Reversed())
+			frontier.Push(int(newDist), flowFieldNode{index: uint32(j), dist: newDist})
+		}
+	}
+}
+
+type flowFieldNode struct {
+	index uint32
+	dist  uint32
+}
+
+func (f *FlowField) contains(c GridCoord) bool {
+	return uint(c.X) < uint(f.numCols) && uint(c.Y) < uint(f.numRows)
+}
+
+func (f *FlowField) index(c GridCoord) int {
+	return c.Y*f.numCols + c.X
+}
+
+func (f *FlowField) coord(i int) GridCoord {
+	return GridCoord{X: i % f.numCols, Y: i / f.numCols}
+}
@@ -0,0 +1,233 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkFlowField(b *testing.B) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 100, WorldHeight: 32 * 100})
+	for y := 10; y < 90; y += 10 {
+		for x := 0; x < 90; x++ {
+			g.SetCellTile(pathing.GridCoord{X: x + (y/10%2)*10, Y: y}, 1)
+		}
+	}
+	f := pathing.NewFlowField(g, l, pathing.FlowFieldConfig{})
+	goal := pathing.GridCoord{X: 99, Y: 99}
+
+	b.Run("build", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			f.Build(goal)
+		}
+	})
+
+	b.Run("update", func(b *testing.B) {
+		f.Build(goal)
+		cell := pathing.GridCoord{X: 50, Y: 5}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			g.SetCellIsBlocked(cell, i%2 == 0)
+			f.NotifyCellChanged(cell)
+			f.Update()
+		}
+	})
+}
+
+func TestFlowField(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"A.........",
+		"xxxxxxxx..",
+		"..........",
+		".wwwwxxxxx",
+		"B....x....",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	f := pathing.NewFlowField(g, l, pathing.FlowFieldConfig{})
+	f.Build(parseResult.dest)
+
+	tests := []struct {
+		pos       pathing.GridCoord
+		dist      int
+		dir       pathing.Direction
+		reachable bool
+	}{
+		{pos: parseResult.dest, dist: 0, dir: pathing.DirNone, reachable: true},
+		{pos: pathing.GridCoord{X: 0, Y: 3}, dist: 1, dir: pathing.DirDown, reachable: true},
+		{pos: pathing.GridCoord{X: 4, Y: 4}, dist: 4, dir: pathing.DirLeft, reachable: true},
+		{pos: pathing.GridCoord{X: 4, Y: 2}, dist: 6, dir: pathing.DirLeft, reachable: true},
+		{pos: parseResult.start, dist: 20, dir: pathing.DirRight, reachable: true},
+		{pos: pathing.GridCoord{X: 6, Y: 4}, dir: pathing.DirNone},
+		{pos: pathing.GridCoord{X: 0, Y: 1}, dist: 3, dir: pathing.DirDown, reachable: true},
+		{pos: pathing.GridCoord{X: -1, Y: 0}, dir: pathing.DirNone},
+		{pos: pathing.GridCoord{X: 0, Y: 100}, dir: pathing.DirNone},
+	}
+	for _, test := range tests {
+		dist, reachable := f.Distance(test.pos)
+		if reachable != test.reachable {
+			t.Fatalf("%v: reachable mismatch:\nhave: %v\nwant: %v", test.pos, reachable, test.reachable)
+		}
+		if dist != test.dist {
+			t.Fatalf("%v: distance mismatch:\nhave: %v\nwant: %v", test.pos, dist, test.dist)
+		}
+		if dir := f.NextDirection(test.pos); dir != test.dir {
+			t.Fatalf("%v: direction mismatch:\nhave: %v\nwant: %v", test.pos, dir, test.dir)
+		}
+	}
+
+	// Open the shortcut.
+	shortcut := pathing.GridCoord{X: 7, Y: 3}
+	g.SetCellTile(shortcut, 0)
+	f.NotifyCellChanged(shortcut)
+	f.Update()
+	if dist, _ := f.Distance(pathing.GridCoord{X: 6, Y: 4}); dist != 12 {
+		t.Fatalf("distance after update mismatch:\nhave: %v\nwant: %v", dist, 12)
+	}
+
+	// Now add the second goal.
+	f.Build(parseResult.dest, pathing.GridCoord{X: 9, Y: 4})
+	if dist, _ := f.Distance(pathing.GridCoord{X: 6, Y: 4}); dist != 3 {
+		t.Fatalf("distance to the second goal mismatch:\nhave: %v\nwant: %v", dist, 3)
+	}
+	if dist, _ := f.Distance(pathing.GridCoord{X: 4, Y: 4}); dist != 4 {
+		t.Fatalf("distance to the first goal mismatch:\nhave: %v\nwant: %v", dist, 4)
+	}
+}
+
+func TestFlowFieldBlockedGoal(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		".A...~....",
+		"..........",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	f := pathing.NewFlowField(g, l, pathing.FlowFieldConfig{})
+	goal := pathing.GridCoord{X: 5, Y: 1}
+	f.Build(goal)
+	if dist, _ := f.Distance(parseResult.start); dist != 4 {
+		t.Fatalf("distance mismatch:\nhave: %v\nwant: %v", dist, 4)
+	}
+	if dir := f.NextDirection(goal); dir != pathing.DirNone {
+		t.Fatalf("goal direction mismatch:\nhave: %v\nwant: %v", dir, pathing.DirNone)
+	}
+}
+
+func TestFlowFieldMatchesAStar(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	configs := []struct {
+		astar pathing.AStarConfig
+		field pathing.FlowFieldConfig
+	}{
+		{},
+		{
+			astar: pathing.AStarConfig{Diagonal: true},
+			field: pathing.FlowFieldConfig{Diagonal: true},
+		},
+		{
+			astar: pathing.AStarConfig{Diagonal: true, CornerCutting: true, DiagonalCost: 1.5},
+			field: pathing.FlowFieldConfig{Diagonal: true, CornerCutting: true, DiagonalCost: 1.5},
+		},
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, config := range configs {
+		astar := pathing.NewAStar(config.astar)
+		for i := 0; i < 300; i++ {
+			g, numCols, numRows := testRandomGrid(rng, 25)
+			to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			g.SetCellTile(to, 0)
+			f := pathing.NewFlowField(g, l, config.field)
+			f.Build(to)
+
+			for j := 0; j < 10; j++ {
+				from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				want := astar.BuildPath(g, from, to, l)
+				dist, reachable := f.Distance(from)
+				if want.Partial {
+					// The A* search area is limited, so it's not an
+					// evidence of the destination being unreachable.
+					continue
+				}
+				if !reachable {
+					t.Fatalf("test%d: %v=>%v is reachable according to A*", i, from, to)
+				}
+				if dist != want.Cost {
+					t.Fatalf("test%d: %v=>%v cost mismatch:\nhave: %v\nwant: %v", i, from, to, dist, want.Cost)
+				}
+				checkFlowFieldDirections(t, g, f, l, from, to)
+			}
+		}
+	}
+}
+
+func TestFlowFieldUpdate(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, config := range []pathing.FlowFieldConfig{{}, {Diagonal: true}} {
+		for i := 0; i < 200; i++ {
+			g, numCols, numRows := testRandomGrid(rng, 25)
+			goals := make([]pathing.GridCoord, rng.Intn(3)+1)
+			for j := range goals {
+				goals[j] = pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			}
+			f := pathing.NewFlowField(g, l, config)
+			f.Build(goals...)
+			fresh := pathing.NewFlowField(g, l, config)
+
+			for round := 0; round < 5; round++ {
+				numChanges := rng.Intn(4) + 1
+				for j := 0; j < numChanges; j++ {
+					c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+					if rng.Intn(2) == 0 {
+						g.SetCellIsBlocked(c, !g.GetCellIsBlocked(c))
+					} else {
+						g.SetCellTile(c, uint8(rng.Intn(4)))
+					}
+					f.NotifyCellChanged(c)
+				}
+				f.Update()
+				fresh.Build(goals...)
+
+				for y := 0; y < numRows; y++ {
+					for x := 0; x < numCols; x++ {
+						c := pathing.GridCoord{X: x, Y: y}
+						haveDist, haveReachable := f.Distance(c)
+						wantDist, wantReachable := fresh.Distance(c)
+						if haveDist != wantDist || haveReachable != wantReachable {
+							t.Fatalf("test%d: round%d: %v distance mismatch:\nhave: %v (%v)\nwant: %v (%v)",
+								i, round, c, haveDist, haveReachable, wantDist, wantReachable)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func checkFlowFieldDirections(t *testing.T, g *pathing.Grid, f *pathing.FlowField, l pathing.GridLayer, from, to pathing.GridCoord) {
+	t.Helper()
+
+	pos := from
+	for steps := 0; pos != to; steps++ {
+		if steps > g.NumCols()*g.NumRows() {
+			t.Fatalf("%v=>%v: the flow field directions are looped", from, to)
+		}
+		dir := f.NextDirection(pos)
+		if dir == pathing.DirNone {
+			t.Fatalf("%v=>%v: no direction at %v", from, to, pos)
+		}
+		pos = pos.Move(dir)
+		if g.GetCellCost(pos, l) == 0 {
+			t.Fatalf("%v=>%v: the flow goes through a blocked %v cell", from, to, pos)
+		}
+	}
+}
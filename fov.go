@@ -0,0 +1,115 @@
+package pathing
+
+// ComputeFOV calls visit for every cell that can be seen from the origin.
+// It implements a recursive shadowcasting algorithm.
+//
+// The opacity layer is used to interpret the tile tags:
+// a value of 0 means that the cell is opaque (it blocks the vision).
+// The opaque cells themselves are visible, so the walls are reported too.
+// The cells outside of the grid are considered to be opaque.
+//
+// The radius limits the vision to a circle around the origin.
+// The origin is always visible (unless it's outside of the grid).
+//
+// Note that the visit function can be called more than once for the same cell:
+// the algorithm scans 8 octants and they share the border cells.
+func ComputeFOV(g *Grid, origin GridCoord, radius int, opacity GridLayer, visit func(GridCoord)) {
+	if uint(origin.X) >= g.numCols || uint(origin.Y) >= g.numRows {
+		return
+	}
+	visit(origin)
+	if radius <= 0 {
+		return
+	}
+	fov := fovState{
+		grid:     g,
+		origin:   origin,
+		radius:   radius,
+		radiusSq: radius*radius + radius,
+		opacity:  opacity,
+		visit:    visit,
+	}
+	for i := range fovOctants {
+		fov.castLight(1, 1.0, 0.0, &fovOctants[i])
+	}
+}
+
+type fovState struct {
+	grid     *Grid
+	origin   GridCoord
+	radius   int
+	radiusSq int
+	opacity  GridLayer
+	visit    func(GridCoord)
+}
+
+// fovOctant is a transformation matrix that maps the
+// octant-local coordinates to the grid offsets.
+type fovOctant struct {
+	xx, xy, yx, yy int
+}
+
+var fovOctants = [8]fovOctant{
+	{xx: 1, xy: 0, yx: 0, yy: 1},
+	{xx: 0, xy: 1, yx: 1, yy: 0},
+	{xx: 0, xy: -1, yx: 1, yy: 0},
+	{xx: -1, xy: 0, yx: 0, yy: 1},
+	{xx: -1, xy: 0, yx: 0, yy: -1},
+	{xx: 0, xy: -1, yx: -1, yy: 0},
+	{xx: 0, xy: 1, yx: -1, yy: 0},
+	{xx: 1, xy: 0, yx: 0, yy: -1},
+}
+
+// castLight scans the octant rows starting from the given one.
+// The start and end are the slopes of the light beam.
+func (fov *fovState) castLight(row int, start, end float64, oct *fovOctant) {
+	if start < end {
+		return
+	}
+	g := fov.grid
+	newStart := 0.0
+	for j := row; j <= fov.radius; j++ {
+		dy := -j
+		blocked := false
+		for dx := -j; dx <= 0; dx++ {
+			leftSlope := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			rightSlope := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+			if start < rightSlope {
+				continue
+			}
+			if end > leftSlope {
+				break
+			}
+
+			x := fov.origin.X + dx*oct.xx + dy*oct.xy
+			y := fov.origin.Y + dx*oct.yx + dy*oct.yy
+			opaque := true
+			if uint(x) < g.numCols && uint(y) < g.numRows {
+				opaque = g.getCellCost(uint(x), uint(y), fov.opacity) == 0
+				if dx*dx+dy*dy <= fov.radiusSq {
+					fov.visit(GridCoord{X: x, Y: y})
+				}
+			}
+
+			if blocked {
+				if opaque {
+					newStart = rightSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+				continue
+			}
+			if opaque && j < fov.radius {
+				// This cell casts a shadow; the part of
+				// the beam before it is scanned recursively.
+				blocked = true
+				fov.castLight(j+1, start, leftSlope, oct)
+				newStart = rightSlope
+			}
+		}
+		if blocked {
+			break
+		}
+	}
+}
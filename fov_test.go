@@ -0,0 +1,175 @@
+package pathing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkComputeFOV(b *testing.B) {
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 64, WorldHeight: 32 * 64})
+	for y := 0; y < 64; y += 4 {
+		for x := y % 8; x < 64; x += 8 {
+			g.SetCellTile(pathing.GridCoord{X: x, Y: y}, 1)
+		}
+	}
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	numVisited := 0
+	visit := func(pathing.GridCoord) {
+		numVisited++
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pathing.ComputeFOV(g, pathing.GridCoord{X: 30, Y: 30}, 20, l, visit)
+	}
+}
+
+func TestComputeFOV(t *testing.T) {
+	tests := []struct {
+		name   string
+		radius int
+		input  []string
+		output []string
+	}{
+		{
+			name:   "open",
+			radius: 3,
+			input: []string{
+				".........",
+				".........",
+				".........",
+				".........",
+				"....A....",
+				".........",
+				".........",
+				".........",
+				".........",
+			},
+			output: []string{
+				"         ",
+				"   ***   ",
+				"  *****  ",
+				" ******* ",
+				" ***A*** ",
+				" ******* ",
+				"  *****  ",
+				"   ***   ",
+				"         ",
+			},
+		},
+
+		{
+			name:   "zero_radius",
+			radius: 0,
+			input: []string{
+				"...",
+				".A.",
+				"...",
+			},
+			output: []string{
+				"   ",
+				" A ",
+				"   ",
+			},
+		},
+
+		{
+			name:   "pillar",
+			radius: 10,
+			input: []string{
+				"..........",
+				"..........",
+				"..........",
+				"....x.....",
+				"....A.....",
+			},
+			output: []string{
+				"**     ***",
+				"***   ****",
+				"**** *****",
+				"****x*****",
+				"****A*****",
+			},
+		},
+
+		{
+			name:   "room",
+			radius: 10,
+			input: []string{
+				"..........",
+				".xxxxxx...",
+				".x....x...",
+				".x..A.....",
+				".x....x...",
+				".xxxxxx...",
+				"..........",
+			},
+			output: []string{
+				"          ",
+				" xxxxxx   ",
+				" x****x***",
+				" x**A*****",
+				" x****x***",
+				" xxxxxx   ",
+				"          ",
+			},
+		},
+
+		{
+			name:   "grid_edge",
+			radius: 5,
+			input: []string{
+				"A..",
+				".x.",
+				"...",
+			},
+			output: []string{
+				"A**",
+				"*x*",
+				"** ",
+			},
+		},
+	}
+
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parseResult := testParseGrid(t, test.input)
+			rows := make([][]byte, parseResult.numRows)
+			for i := range rows {
+				rows[i] = []byte(strings.Repeat(" ", parseResult.numCols))
+			}
+			pathing.ComputeFOV(parseResult.grid, parseResult.start, test.radius, l, func(c pathing.GridCoord) {
+				if c.X < 0 || c.Y < 0 || c.X >= parseResult.numCols || c.Y >= parseResult.numRows {
+					t.Fatalf("visited an out-of-bounds %v cell", c)
+				}
+				marker := byte('*')
+				switch test.input[c.Y][c.X] {
+				case 'x':
+					marker = 'x'
+				case 'A':
+					marker = 'A'
+				}
+				rows[c.Y][c.X] = marker
+			})
+			have := make([]string, len(rows))
+			for i := range rows {
+				have[i] = string(rows[i])
+			}
+			if strings.Join(have, "\n") != strings.Join(test.output, "\n") {
+				t.Fatalf("FOV mismatch:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(test.output, "\n"))
+			}
+		})
+	}
+}
+
+func TestComputeFOVOutside(t *testing.T) {
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 4, WorldHeight: 32 * 4})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	pathing.ComputeFOV(g, pathing.GridCoord{X: -1, Y: 2}, 5, l, func(c pathing.GridCoord) {
+		t.Fatalf("unexpected %v cell visit", c)
+	})
+}
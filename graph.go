@@ -0,0 +1,195 @@
+package pathing
+
+import (
+	"math"
+)
+
+// Graph is a user-defined navigation graph.
+// It can be used to find paths over the room graphs,
+// road networks, waypoints and other non-grid maps.
+//
+// The node type N is usually a small value like an integer ID
+// or a pointer, since it's used as a map key by the pathfinder.
+type Graph[N comparable] interface {
+	// Neighbors appends the nodes that can be reached from n
+	// to dst and returns the extended slice.
+	// It's like an append-style function: its result is
+	// re-used for the next calls to avoid the allocations.
+	Neighbors(dst []N, n N) []N
+
+	// EdgeCost returns a traversal cost of the from->to edge.
+	// Just like with a GridLayer, a cost of 0 means that
+	// the edge can't be traversed.
+	EdgeCost(from, to N) int
+
+	// Heuristic returns an estimated cost of the path between the nodes.
+	// For the optimal results, it should never overestimate the cost.
+	// A Heuristic that always returns 0 turns A* into Dijkstra.
+	Heuristic(from, to N) int
+}
+
+// GraphAStar implements an A* search over a user-defined Graph.
+// You must use NewGraphAStar() function to obtain an instance of this type.
+//
+// Once created, you should re-use it to build paths.
+// Do not throw the instance away after building the path once.
+//
+// The graph node state is stored in a generational map
+// (like the grid coord maps), so it's never cleared between the searches.
+// The working space grows up to the number of visited graph nodes.
+type GraphAStar[N comparable] struct {
+	frontier  *minheap[graphCoord[N]]
+	nodes     map[N]graphNodeInfo[N]
+	gen       uint32
+	neighbors []N
+}
+
+type GraphAStarConfig struct {
+	// NumNodes is a size hint for the GraphAStar constructor.
+	// If you keep it at 0, the working space will grow on demand.
+	NumNodes uint
+}
+
+// BuildGraphPathResult is a GraphAStar.BuildPath() return value.
+type BuildGraphPathResult[N comparable] struct {
+	// Path is a sequence of the nodes that lead to the Finish.
+	// The start node is not included, so a path that
+	// ends where it starts is empty.
+	//
+	// It's the dst slice argument passed to BuildPath,
+	// extended with the path nodes.
+	Path []N
+
+	// Finish is where the constructed path ends.
+	// It's the destination node unless it's a partial result.
+	// For the partial results, it's the node that has
+	// the smallest heuristic distance to the destination.
+	Finish N
+
+	// Cost is a path final movement cost.
+	Cost int
+
+	// Whether this is a partial path result.
+	// This happens if the destination can't be reached.
+	Partial bool
+}
+
+type graphCoord[N comparable] struct {
+	Node N
+	Cost int
+}
+
+type graphNodeInfo[N comparable] struct {
+	parent N
+	cost   int
+	gen    uint32
+}
+
+// NewGraphAStar creates a ready-to-use GraphAStar object.
+func NewGraphAStar[N comparable](config GraphAStarConfig) *GraphAStar[N] {
+	return &GraphAStar[N]{
+		frontier:  newMinheap[graphCoord[N]](32),
+		nodes:     make(map[N]graphNodeInfo[N], config.NumNodes),
+		gen:       1,
+		neighbors: make([]N, 0, 8),
+	}
+}
+
+// BuildPath attempts to find a path between the two graph nodes.
+//
+// The path nodes are appended to dst; pass the previous
+// result Path[:0] to avoid the extra allocations.
+func (astar *GraphAStar[N]) BuildPath(g Graph[N], from, to N, dst []N) BuildGraphPathResult[N] {
+	result := BuildGraphPathResult[N]{Path: dst}
+	if from == to {
+		result.Finish = to
+		return result
+	}
+
+	astar.reset()
+
+	finish, cost, found := astar.search(g, from, to)
+	result.Path = astar.constructPath(from, finish, dst)
+	result.Finish = finish
+	result.Cost = cost
+	result.Partial = !found
+
+	return result
+}
+
+func (astar *GraphAStar[N]) search(g Graph[N], from, to N) (N, int, bool) {
+	frontier := astar.frontier
+	frontier.Reset()
+
+	gen := astar.gen
+	astar.nodes[from] = graphNodeInfo[N]{parent: from, gen: gen}
+	frontier.Push(0, graphCoord[N]{Node: from})
+
+	shortestDist := math.MaxInt
+	fallbackNode := from
+	fallbackCost := 0
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+
+		if current.Node == to {
+			return to, current.Cost, true
+		}
+		if info := astar.nodes[current.Node]; current.Cost > info.cost {
+			// A better path to this node was already found.
+			continue
+		}
+
+		dist := g.Heuristic(current.Node, to)
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackNode = current.Node
+			fallbackCost = current.Cost
+		}
+
+		astar.neighbors = g.Neighbors(astar.neighbors[:0], current.Node)
+		for _, next := range astar.neighbors {
+			edgeCost := g.EdgeCost(current.Node, next)
+			if edgeCost <= 0 {
+				continue
+			}
+			newNextCost := current.Cost + edgeCost
+			if info, ok := astar.nodes[next]; ok && info.gen == gen && newNextCost >= info.cost {
+				continue
+			}
+			astar.nodes[next] = graphNodeInfo[N]{
+				parent: current.Node,
+				cost:   newNextCost,
+				gen:    gen,
+			}
+			priority := newNextCost + g.Heuristic(next, to)
+			frontier.Push(priority, graphCoord[N]{Node: next, Cost: newNextCost})
+		}
+	}
+
+	return fallbackNode, fallbackCost, false
+}
+
+func (astar *GraphAStar[N]) constructPath(from, to N, dst []N) []N {
+	// The nodes are collected in the reversed order first.
+	start := len(dst)
+	for n := to; n != from; n = astar.nodes[n].parent {
+		dst = append(dst, n)
+	}
+	path := dst[start:]
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return dst
+}
+
+func (astar *GraphAStar[N]) reset() {
+	if astar.gen == math.MaxUint32 {
+		// Just like with a coordMap, this will probably never happen.
+		for k := range astar.nodes {
+			delete(astar.nodes, k)
+		}
+		astar.gen = 1
+		return
+	}
+	astar.gen++
+}
@@ -0,0 +1,186 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+// roadGraph is a simple adjacency list graph with
+// the 2D node positions used for the heuristic.
+type roadGraph struct {
+	pos   []pathing.GridCoord
+	edges [][]roadEdge
+}
+
+type roadEdge struct {
+	to   int
+	cost int
+}
+
+func (g *roadGraph) connect(a, b, cost int) {
+	g.edges[a] = append(g.edges[a], roadEdge{to: b, cost: cost})
+	g.edges[b] = append(g.edges[b], roadEdge{to: a, cost: cost})
+}
+
+func (g *roadGraph) Neighbors(dst []int, n int) []int {
+	for _, e := range g.edges[n] {
+		dst = append(dst, e.to)
+	}
+	return dst
+}
+
+func (g *roadGraph) EdgeCost(from, to int) int {
+	for _, e := range g.edges[from] {
+		if e.to == to {
+			return e.cost
+		}
+	}
+	return 0
+}
+
+func (g *roadGraph) Heuristic(from, to int) int {
+	return g.pos[from].Dist(g.pos[to])
+}
+
+// gridGraph adapts the Grid to the Graph interface.
+type gridGraph struct {
+	grid  *pathing.Grid
+	layer pathing.GridLayer
+}
+
+func (g *gridGraph) Neighbors(dst []pathing.GridCoord, c pathing.GridCoord) []pathing.GridCoord {
+	for _, d := range []pathing.Direction{pathing.DirRight, pathing.DirDown, pathing.DirLeft, pathing.DirUp} {
+		next := c.Move(d)
+		if g.grid.GetCellCost(next, g.layer) != 0 {
+			dst = append(dst, next)
+		}
+	}
+	return dst
+}
+
+func (g *gridGraph) EdgeCost(from, to pathing.GridCoord) int {
+	return int(g.grid.GetCellCost(to, g.layer))
+}
+
+func (g *gridGraph) Heuristic(from, to pathing.GridCoord) int {
+	return from.Dist(to)
+}
+
+func TestGraphAStar(t *testing.T) {
+	//  0 --6-- 1 --1-- 2
+	//  |               |
+	//  1               1
+	//  |               |
+	//  3 --1-- 4 --1-- 5    6 (isolated)
+	g := &roadGraph{
+		pos: []pathing.GridCoord{
+			{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0},
+			{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1},
+			{X: 3, Y: 1},
+		},
+		edges: make([][]roadEdge, 7),
+	}
+	g.connect(0, 1, 6)
+	g.connect(1, 2, 1)
+	g.connect(0, 3, 1)
+	g.connect(3, 4, 1)
+	g.connect(4, 5, 1)
+	g.connect(5, 2, 1)
+
+	tests := []struct {
+		from    int
+		to      int
+		path    []int
+		finish  int
+		cost    int
+		partial bool
+	}{
+		{from: 0, to: 0, path: []int{}, finish: 0},
+		{from: 0, to: 1, path: []int{3, 4, 5, 2, 1}, finish: 1, cost: 5},
+		{from: 0, to: 2, path: []int{3, 4, 5, 2}, finish: 2, cost: 4},
+		{from: 1, to: 3, path: []int{2, 5, 4, 3}, finish: 3, cost: 4},
+		{from: 4, to: 4, path: []int{}, finish: 4},
+		{from: 0, to: 6, path: []int{3, 4, 5}, finish: 5, cost: 3, partial: true},
+		{from: 6, to: 0, path: []int{}, finish: 6, partial: true},
+	}
+
+	astar := pathing.NewGraphAStar[int](pathing.GraphAStarConfig{})
+	var path []int
+	for _, test := range tests {
+		result := astar.BuildPath(g, test.from, test.to, path[:0])
+		path = result.Path
+		if len(result.Path) != len(test.path) {
+			t.Fatalf("%d->%d: path mismatch:\nhave: %v\nwant: %v", test.from, test.to, result.Path, test.path)
+		}
+		for i := range test.path {
+			if result.Path[i] != test.path[i] {
+				t.Fatalf("%d->%d: path mismatch:\nhave: %v\nwant: %v", test.from, test.to, result.Path, test.path)
+			}
+		}
+		if result.Finish != test.finish {
+			t.Fatalf("%d->%d: finish mismatch: have %d, want %d", test.from, test.to, result.Finish, test.finish)
+		}
+		if result.Cost != test.cost {
+			t.Fatalf("%d->%d: cost mismatch: have %d, want %d", test.from, test.to, result.Cost, test.cost)
+		}
+		if result.Partial != test.partial {
+			t.Fatalf("%d->%d: partial flag mismatch: have %v, want %v", test.from, test.to, result.Partial, test.partial)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		result := astar.BuildPath(g, 0, 1, path[:0])
+		path = result.Path
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocs, got %v", allocs)
+	}
+}
+
+func TestGraphAStarMatchesAStar(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 1, 1, 1, 1})
+	gridAStar := pathing.NewAStar(pathing.AStarConfig{})
+	graphAStar := pathing.NewGraphAStar[pathing.GridCoord](pathing.GraphAStarConfig{})
+	var longPath pathing.LongGridPath
+	var path []pathing.GridCoord
+	for i := 0; i < 100; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 40)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		if g.GetCellCost(from, l) == 0 || g.GetCellCost(to, l) == 0 {
+			continue
+		}
+
+		want := gridAStar.BuildLongPath(g, from, to, l, &longPath)
+		have := graphAStar.BuildPath(&gridGraph{grid: g, layer: l}, from, to, path[:0])
+		path = have.Path
+		if have.Partial != want.Partial {
+			t.Fatalf("test%d: %v->%v: partial flag mismatch: have %v, want %v", i, from, to, have.Partial, want.Partial)
+		}
+		if want.Partial {
+			continue
+		}
+		if have.Cost != want.Cost {
+			t.Fatalf("test%d: %v->%v: cost mismatch: have %d, want %d", i, from, to, have.Cost, want.Cost)
+		}
+		if len(have.Path) != 0 && have.Path[len(have.Path)-1] != to {
+			t.Fatalf("test%d: %v->%v: the path ends at %v", i, from, to, have.Path[len(have.Path)-1])
+		}
+		pos := from
+		cost := 0
+		for _, c := range have.Path {
+			if pos.Dist(c) != 1 {
+				t.Fatalf("test%d: %v->%v: invalid %v->%v step", i, from, to, pos, c)
+			}
+			cost += int(g.GetCellCost(c, l))
+			pos = c
+		}
+		if cost != have.Cost {
+			t.Fatalf("test%d: %v->%v: path cost is %d, reported %d", i, from, to, cost, have.Cost)
+		}
+	}
+}
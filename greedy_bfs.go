@@ -1,6 +1,25 @@
 package pathing
 
-var neighborOffsets = [4]GridCoord{
+import (
+	"math"
+)
+
+// neighborOffsets are indexed by Direction.
+// The first 4 are axial, the rest are diagonal.
+var neighborOffsets = [8]GridCoord{
+	{X: 1},
+	{Y: 1},
+	{X: -1},
+	{Y: -1},
+	{X: 1, Y: 1},
+	{X: -1, Y: 1},
+	{X: -1, Y: -1},
+	{X: 1, Y: -1},
+}
+
+// axialNeighborOffsets are the first 4 neighborOffsets.
+// Iterating over the array is a bit faster than using a slice.
+var axialNeighborOffsets = [4]GridCoord{
 	{X: 1},
 	{Y: 1},
 	{X: -1},
@@ -20,6 +39,56 @@ type GreedyBFS struct {
 	pqueue     *priorityQueue[weightedGridCoord]
 	coordSlice []weightedGridCoord
 	coordMap   *coordMap
+
+	// These are allocated lazily by BuildLongPath.
+	// In the diagonal mode, heapFrontier is allocated right away.
+	heapFrontier *minheap[weightedGridCoord]
+	longCoordMap *coordMap
+
+	numNeighbors  int
+	cornerCutting bool
+
+	clearance *ClearanceMap
+	agentSize uint8
+
+	maxExpanded int
+
+	state bfsSearch
+}
+
+// bfsSearch is a resumable greedy BFS search state.
+// See GreedyBFS.Start and GreedyBFS.Step.
+type bfsSearch struct {
+	grid      *Grid
+	layer     GridLayer
+	clearance *ClearanceMap
+
+	origin GridCoord
+	start  GridCoord
+	goal   GridCoord
+
+	// goals are used for the multi-goal searches (if multiGoal is set).
+	// The goal field is ignored in this case.
+	// They're stored by value to avoid the heap allocations.
+	goals     pathGoals
+	multiGoal bool
+
+	maxWeight int
+	pathmap   *coordMap
+
+	// useHeap is set for the unbounded searches and for the diagonal mode.
+	// The bucket-based priority queue can't handle the big distances
+	// (the diagonal mode distances are scaled), so a minheap is used instead.
+	useHeap bool
+
+	hotFrontier []weightedGridCoord
+
+	shortestDist  int
+	fallbackCoord GridCoord
+	numExpanded   int
+
+	found bool
+	done  bool
 }
 
 // BuildPathResult is a BuildPath() method return value.
@@ -56,6 +125,29 @@ type GreedyBFSConfig struct {
 	// if the grids you're going operate on are small.
 	NumCols uint
 	NumRows uint
+
+	// Diagonal enables the 8-directional movement mode.
+	// The constructed paths may contain diagonal steps (like DirDownRight).
+	//
+	// A diagonal step is only possible if both adjacent axial cells are passable.
+	// See CornerCutting option to relax this rule.
+	Diagonal bool
+
+	// CornerCutting allows a diagonal step when only one of the
+	// adjacent axial cells is passable.
+	// Squeezing between two impassable cells is never allowed.
+	//
+	// This option is only meaningful in the Diagonal mode.
+	CornerCutting bool
+
+	// Clearance and AgentSize enable the agent size aware pathfinding.
+	// See AStarConfig.Clearance and AStarConfig.AgentSize.
+	Clearance *ClearanceMap
+	AgentSize uint8
+
+	// MaxExpandedNodes limits the number of the expanded nodes per search.
+	// See AStarConfig.MaxExpandedNodes.
+	MaxExpandedNodes int
 }
 
 // NewGreedyBFS creates a ready-to-use GreedyBFS object.
@@ -80,6 +172,22 @@ func NewGreedyBFS(config GreedyBFSConfig) *GreedyBFS {
 		pqueue:     newPriorityQueue[weightedGridCoord](),
 		coordMap:   newCoordMap(coordMapCols, coordMapRows),
 		coordSlice: make([]weightedGridCoord, 0, 40),
+
+		numNeighbors:  4,
+		cornerCutting: config.CornerCutting,
+
+		maxExpanded: math.MaxInt,
+	}
+	if config.MaxExpandedNodes > 0 {
+		bfs.maxExpanded = config.MaxExpandedNodes
+	}
+	if config.Diagonal {
+		bfs.numNeighbors = 8
+		bfs.heapFrontier = newMinheap[weightedGridCoord](64)
+	}
+	if config.Clearance != nil && config.AgentSize > 1 {
+		bfs.clearance = config.Clearance
+		bfs.agentSize = config.AgentSize
 	}
 
 	return bfs
@@ -106,6 +214,102 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 	localStart := from.Sub(origin)
 	localGoal := to.Sub(origin)
 
+	bfs.coordMap.Reset()
+
+	var finish GridCoord
+	var found bool
+	if bfs.numNeighbors == 4 && bfs.clearance.boundTo(g, l) == nil && !g.wideTags {
+		finish, found = bfs.searchPlain(g, l, origin, localStart, localGoal)
+	} else {
+		finish, found = bfs.search(g, l, origin, localStart, localGoal, nil, gridPathLimit(bfs.numNeighbors), bfs.numNeighbors == 8, bfs.coordMap)
+	}
+	result.Steps = constructPath(localStart, finish, bfs.coordMap)
+	result.Finish = finish.Add(origin)
+	result.Cost = result.Steps.Len()
+	result.Partial = !found
+
+	return result
+}
+
+// BuildLongPath is like BuildPath, but it's not limited by the GridPath max length.
+// It will search the entire grid if needed.
+//
+// The path steps are written to dst; its previous contents are discarded.
+// Re-use the same dst object to avoid the extra allocations.
+//
+// The first BuildLongPath call (or a call with a bigger grid) allocates
+// the working space that is big enough to hold the entire grid.
+func (bfs *GreedyBFS) BuildLongPath(g *Grid, from, to GridCoord, l GridLayer, dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	if from == to {
+		dst.Reset()
+		result.Finish = to
+		return result
+	}
+
+	bfs.Start(g, from, to, l)
+	bfs.Step(math.MaxInt)
+	return bfs.Result(dst)
+}
+
+// Start begins a time-sliced search between the two coordinates.
+// See AStar.Start.
+func (bfs *GreedyBFS) Start(g *Grid, from, to GridCoord, l GridLayer) {
+	if bfs.heapFrontier == nil {
+		bfs.heapFrontier = newMinheap[weightedGridCoord](64)
+	}
+	bfs.longCoordMap = resizeCoordMap(bfs.longCoordMap, int(g.numCols), int(g.numRows))
+	bfs.longCoordMap.Reset()
+
+	bfs.startSearch(g, l, GridCoord{}, from, to, nil, math.MaxInt, true, bfs.longCoordMap)
+}
+
+// Step continues the time-sliced search started by Start.
+// See AStar.Step.
+func (bfs *GreedyBFS) Step(maxNodes int) bool {
+	return bfs.step(maxNodes)
+}
+
+// Result returns the time-sliced search result.
+// See AStar.Result.
+func (bfs *GreedyBFS) Result(dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	s := &bfs.state
+	if s.pathmap == nil {
+		// The search was never started.
+		dst.Reset()
+		result.Partial = true
+		return result
+	}
+	constructLongPath(s.start, s.fallbackCoord, s.pathmap, dst)
+	result.Finish = s.fallbackCoord
+	result.Cost = dst.Len()
+	result.Partial = !s.found
+	return result
+}
+
+// search runs the greedy BFS algorithm using the local coordinates.
+// The origin is used to translate them into the grid coordinates.
+//
+// If goals are not nil, the search ends at any of them and localGoal is ignored.
+//
+// It returns the coord where the path ends and
+// whether it's the goal (otherwise it's a fallback coord).
+func (bfs *GreedyBFS) search(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord, goals *pathGoals, maxWeight int, useHeap bool, pathmap *coordMap) (GridCoord, bool) {
+	bfs.startSearch(g, l, origin, localStart, localGoal, goals, maxWeight, useHeap, pathmap)
+	bfs.step(math.MaxInt)
+	s := &bfs.state
+	return s.fallbackCoord, s.found
+}
+
+// searchPlain is a specialized version of search for the most common case:
+// a 4-directional bounded search to a single goal without the clearance checks
+// over a default mode grid (see GridConfig.WideTags).
+//
+// The generic step loop has to support all search modes and the time slicing,
+// this makes the plain search measurably slower (up to 15%).
+// Keep this loop in sync with step.
+func (bfs *GreedyBFS) searchPlain(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord) (GridCoord, bool) {
 	frontier := bfs.pqueue
 	frontier.Reset()
 
@@ -113,11 +317,12 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 	hotFrontier = append(hotFrontier, weightedGridCoord{Coord: localStart})
 
 	pathmap := bfs.coordMap
-	pathmap.Reset()
+	maxExpanded := bfs.maxExpanded
 
-	shortestDist := 0xff
-	var fallbackCoord GridCoord
-	foundPath := false
+	shortestDist := 0xffffffff
+	fallbackCoord := localStart
+	found := false
+	numExpanded := 0
 	for len(hotFrontier) != 0 || !frontier.IsEmpty() {
 		var current weightedGridCoord
 		if len(hotFrontier) != 0 {
@@ -127,16 +332,19 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 			current = frontier.Pop()
 		}
 
+		if current.Weight > gridPathMaxLen {
+			// The path to this node doesn't fit into the GridPath.
+			break
+		}
 		if current.Coord == localGoal {
-			result.Steps = constructPath(localStart, localGoal, pathmap)
-			result.Finish = to
-			result.Cost = result.Steps.Len()
-			foundPath = true
+			fallbackCoord = localGoal
+			found = true
 			break
 		}
-		if current.Weight > gridPathMaxLen {
+		if numExpanded >= maxExpanded {
 			break
 		}
+		numExpanded++
 
 		dist := localGoal.Dist(current.Coord)
 		if dist < shortestDist {
@@ -144,14 +352,14 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 			fallbackCoord = current.Coord
 		}
 
-		for dir, offset := range &neighborOffsets {
+		for dir, offset := range &axialNeighborOffsets {
 			next := current.Coord.Add(offset)
 			cx := uint(next.X) + uint(origin.X)
 			cy := uint(next.Y) + uint(origin.Y)
 			if cx >= g.numCols || cy >= g.numRows {
 				continue
 			}
-			if g.getCellCost(cx, cy, l) == 0 {
+			if g.getTagCost(cx, cy, l) == 0 {
 				continue
 			}
 			pathmapKey := pathmap.packCoord(next)
@@ -160,6 +368,170 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 			}
 			pathmap.Set(pathmapKey, uint32(dir))
 			nextDist := localGoal.Dist(next)
+			nextWeighted := weightedGridCoord{
+				Coord:  next,
+				Weight: current.Weight + 1,
+			}
+			if nextDist < dist {
+				hotFrontier = append(hotFrontier, nextWeighted)
+			} else {
+				frontier.Push(nextDist, nextWeighted)
+			}
+		}
+	}
+
+	// In case if that slice was growing due to appends,
+	// save that extra capacity for later.
+	bfs.coordSlice = hotFrontier[:0]
+
+	return fallbackCoord, found
+}
+
+func (bfs *GreedyBFS) startSearch(g *Grid, l GridLayer, origin, localStart, localGoal GridCoord, goals *pathGoals, maxWeight int, useHeap bool, pathmap *coordMap) {
+	if useHeap {
+		bfs.heapFrontier.Reset()
+	} else {
+		bfs.pqueue.Reset()
+	}
+
+	hotFrontier := bfs.coordSlice[:0]
+	hotFrontier = append(hotFrontier, weightedGridCoord{Coord: localStart})
+
+	bfs.state = bfsSearch{
+		grid:      g,
+		layer:     l,
+		clearance: bfs.clearance.boundTo(g, l),
+
+		origin: origin,
+		start:  localStart,
+		goal:   localGoal,
+
+		maxWeight: maxWeight,
+		pathmap:   pathmap,
+		useHeap:   useHeap,
+
+		hotFrontier: hotFrontier,
+
+		shortestDist:  0xffffffff,
+		fallbackCoord: localStart,
+	}
+	if goals != nil {
+		bfs.state.goals = *goals
+		bfs.state.multiGoal = true
+	}
+}
+
+// step runs the search started by startSearch.
+// It expands at most maxNodes nodes.
+func (bfs *GreedyBFS) step(maxNodes int) bool {
+	s := &bfs.state
+	if s.done {
+		return true
+	}
+
+	frontier := bfs.pqueue
+	heapFrontier := bfs.heapFrontier
+	useHeap := s.useHeap
+	hotFrontier := s.hotFrontier
+	pathmap := s.pathmap
+	g := s.grid
+	l := s.layer
+	clearance := s.clearance
+	wide := g.wideTags
+	origin := s.origin
+	localGoal := s.goal
+	goals := &s.goals
+	multiGoal := s.multiGoal
+
+	// The hot loop state is kept in the local variables;
+	// it's saved back to the search state when the loop ends.
+	shortestDist := s.shortestDist
+	fallbackCoord := s.fallbackCoord
+	numExpanded := s.numExpanded
+
+	for ; maxNodes > 0; maxNodes-- {
+		var current weightedGridCoord
+		if len(hotFrontier) != 0 {
+			current = hotFrontier[len(hotFrontier)-1]
+			hotFrontier = hotFrontier[:len(hotFrontier)-1]
+		} else if useHeap && !heapFrontier.IsEmpty() {
+			current = heapFrontier.Pop()
+		} else if !useHeap && !frontier.IsEmpty() {
+			current = frontier.Pop()
+		} else {
+			s.done = true
+			break
+		}
+
+		if current.Weight > s.maxWeight {
+			// The path to this node is too long.
+			// For the bounded searches, it wouldn't fit into the GridPath.
+			s.done = true
+			break
+		}
+		if !multiGoal && current.Coord == localGoal || multiGoal && goals.reached(current.Coord.Add(origin)) {
+			fallbackCoord = current.Coord
+			s.found = true
+			s.done = true
+			break
+		}
+		if numExpanded >= bfs.maxExpanded {
+			s.done = true
+			break
+		}
+		numExpanded++
+
+		var dist int
+		if !multiGoal {
+			dist = bfs.dist(localGoal, current.Coord)
+		} else {
+			dist = bfs.goalsDist(goals, current.Coord.Add(origin))
+		}
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackCoord = current.Coord
+		}
+
+		bestHotDist := dist
+		for dir, offset := range neighborOffsets[:bfs.numNeighbors] {
+			next := current.Coord.Add(offset)
+			cx := uint(next.X) + uint(origin.X)
+			cy := uint(next.Y) + uint(origin.Y)
+			if cx >= g.numCols || cy >= g.numRows {
+				continue
+			}
+			if g.getTagCost(cx, cy, l) == 0 || wide && g.isBlockedCell(cx, cy) {
+				continue
+			}
+			if clearance != nil && !clearance.fits(cx, cy, bfs.agentSize) {
+				continue
+			}
+			if dir >= int(DirDownRight) {
+				x := uint(current.Coord.X) + uint(origin.X)
+				y := uint(current.Coord.Y) + uint(origin.Y)
+				if !g.canMoveDiagonally(x, y, offset, l, bfs.cornerCutting) {
+					continue
+				}
+				if clearance != nil && !clearance.fitsDiagonally(x, y, offset, bfs.agentSize) {
+					continue
+				}
+			}
+			pathmapKey := pathmap.packCoord(next)
+			if pathmap.Contains(pathmapKey) {
+				continue
+			}
+			pathmap.Set(pathmapKey, uint32(dir))
+			var nextDist int
+			if !multiGoal {
+				nextDist = bfs.dist(localGoal, next)
+			} else if goals.match != nil {
+				// Without a heuristic, the steps count is used as a priority.
+				// It never exceeds the priority queue buckets limit.
+				// The dist is always 0 here, so the hot frontier is not used.
+				nextDist = current.Weight + 1
+			} else {
+				nextDist = bfs.goalsDist(goals, next.Add(origin))
+			}
 			nextWeighted := weightedGridCoord{
 				Coord: next,
 				// This is used to determine the out-of-scope coordinates.
@@ -168,22 +540,121 @@ func (bfs *GreedyBFS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildP
 			}
 			if nextDist < dist {
 				hotFrontier = append(hotFrontier, nextWeighted)
+				if nextDist <= bestHotDist {
+					bestHotDist = nextDist
+				} else {
+					// Keep the most promising coord on top of the hot frontier.
+					// This can only happen in the diagonal mode.
+					n := len(hotFrontier)
+					hotFrontier[n-1], hotFrontier[n-2] = hotFrontier[n-2], hotFrontier[n-1]
+				}
+			} else if useHeap {
+				heapFrontier.Push(nextDist, nextWeighted)
 			} else {
 				frontier.Push(nextDist, nextWeighted)
 			}
 		}
 	}
 
-	if !foundPath {
-		result.Steps = constructPath(localStart, fallbackCoord, pathmap)
-		result.Finish = fallbackCoord.Add(origin)
-		result.Cost = result.Steps.Len()
-		result.Partial = true
+	s.shortestDist = shortestDist
+	s.fallbackCoord = fallbackCoord
+	s.numExpanded = numExpanded
+	s.hotFrontier = hotFrontier
+	if s.done {
+		// In case if that slice was growing due to appends,
+		// save that extra capacity for later.
+		bfs.coordSlice = hotFrontier[:0]
 	}
 
-	// In case if that slice was growing due to appends,
-	// save that extra capacity for later.
-	bfs.coordSlice = hotFrontier[:0]
+	return s.done
+}
+
+// BuildHexPath is like BuildPath, but it works with a HexGrid.
+// The from and to are the offset coordinates.
+//
+// The Diagonal-related options and the Clearance option are ignored.
+func (bfs *GreedyBFS) BuildHexPath(g *HexGrid, from, to GridCoord, l GridLayer) BuildHexPathResult {
+	var result BuildHexPathResult
+	if from == to {
+		result.Finish = to
+		return result
+	}
+
+	// See AStar.BuildHexPath for the local coordinates notes.
+	origin := findPathOrigin(from)
+
+	frontier := bfs.pqueue
+	frontier.Reset()
+
+	pathmap := bfs.coordMap
+	pathmap.Reset()
+
+	frontier.Push(0, weightedGridCoord{Coord: from})
+
+	goal := OffsetToHex(to)
+	shortestDist := 0xffffffff
+	fallbackCoord := from
+	foundPath := false
+	numExpanded := 0
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+
+		if current.Weight > gridPathDiagMaxLen {
+			// The path to this node doesn't fit into the GridPath.
+			break
+		}
+		if current.Coord == to {
+			fallbackCoord = to
+			foundPath = true
+			break
+		}
+		if numExpanded >= bfs.maxExpanded {
+			break
+		}
+		numExpanded++
+
+		currentHex := OffsetToHex(current.Coord)
+		dist := goal.Dist(currentHex)
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackCoord = current.Coord
+		}
+
+		for dir, offset := range hexNeighborOffsets {
+			nextHex := currentHex.Add(offset)
+			next := nextHex.Offset()
+			local := next.Sub(origin)
+			if uint(local.X) >= uint(pathmap.numCols) || uint(local.Y) >= uint(pathmap.numRows) {
+				continue
+			}
+			if g.getCellCost(next, l) == 0 {
+				continue
+			}
+			pathmapKey := pathmap.packCoord(local)
+			if pathmap.Contains(pathmapKey) {
+				continue
+			}
+			pathmap.Set(pathmapKey, uint32(dir))
+			frontier.Push(goal.Dist(nextHex), weightedGridCoord{
+				Coord:  next,
+				Weight: current.Weight + 1,
+			})
+		}
+	}
+
+	result.Steps = constructHexPath(from, fallbackCoord, origin, pathmap)
+	result.Finish = fallbackCoord
+	result.Cost = result.Steps.Len()
+	result.Partial = !foundPath
 
 	return result
 }
+
+func (bfs *GreedyBFS) dist(a, b GridCoord) int {
+	if bfs.numNeighbors == 4 {
+		return a.Dist(b)
+	}
+	// An octile distance where the diagonal step costs 1.5 of the axial one.
+	// It's scaled to keep it integral.
+	return a.octileDist(b, 2, 3)
+}
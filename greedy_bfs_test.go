@@ -718,6 +718,15 @@ var bfsTests = []pathfindTestCase{
 		partial: true,
 	},
 
+	{
+		// The goal is 57 steps away, the path to it doesn't fit into the GridPath.
+		name: "distlimit_goal",
+		path: []string{
+			"A                                                        B",
+		},
+		partial: true,
+	},
+
 	{
 		name: "distlimit2",
 		path: []string{
@@ -758,3 +767,108 @@ var bfsTests = []pathfindTestCase{
 		cost:    56,
 	},
 }
+
+func TestGreedyBFSDiagonal(t *testing.T) {
+	for i := range bfsDiagonalTests {
+		runPathfindTest(t, bfsDiagonalTests[i], func(cols, rows uint) pathBuilder {
+			return pathing.NewGreedyBFS(pathing.GreedyBFSConfig{
+				NumCols:  cols,
+				NumRows:  rows,
+				Diagonal: true,
+			})
+		})
+	}
+}
+
+var bfsDiagonalTests = []pathfindTestCase{
+	{
+		name: "trivial_diagonal",
+		path: []string{
+			"..........",
+			"...A......",
+			".... .....",
+			"..... ....",
+			".....$....",
+			"..........",
+		},
+	},
+
+	{
+		name: "no_corner_cutting1",
+		path: []string{
+			"........",
+			"...A   .",
+			"..xxxx .",
+			"....x. .",
+			"....x$..",
+			"........",
+		},
+	},
+
+	{
+		name: "no_corner_cutting2",
+		path: []string{
+			"A.x.....",
+			" x......",
+			" .......",
+			".     $.",
+		},
+	},
+
+	{
+		name: "zigzag",
+		path: []string{
+			"A       ",
+			"xxxxxxx ",
+			"        ",
+			" xxxxxxx",
+			"   $....",
+		},
+	},
+
+	{
+		name: "ignore_costs",
+		path: []string{
+			"..........",
+			".A..wwww..",
+			".. .wwww..",
+			"... WWWW $",
+			"..........",
+		},
+	},
+
+	{
+		name: "far_goal",
+		path: []string{
+			"....................................",
+			"....................................",
+			".....   ............................",
+			"..... x. ...........................",
+			".... .x.. ..........................",
+			"...A..x... .........................",
+			"......x.... ........................",
+			"......x.....      ..................",
+			"......xxxxxxxxxxx. .................",
+			"......x........x...              $..",
+			"......xx.......x....................",
+			"......xx............................",
+		},
+	},
+
+	{
+		name: "distlimit",
+		path: []string{
+			"A                                     .................................B",
+		},
+		partial: true,
+	},
+
+	{
+		// The goal is 38 steps away, the path to it doesn't fit into the GridPath.
+		name: "distlimit_goal",
+		path: []string{
+			"A                                     B",
+		},
+		partial: true,
+	},
+}
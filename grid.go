@@ -6,6 +6,18 @@ type Grid struct {
 
 	bytes []byte
 
+	// blocked is a separate blocked bits storage.
+	// It's only used in the WideTags mode,
+	// otherwise the blocked bit is a part of the cell nibble.
+	blocked []byte
+
+	// wideTags reports whether the grid is in the WideTags mode.
+	// It's stored explicitly: an empty grid has no blocked bytes in any mode.
+	wideTags bool
+
+	// tagMask is 0b111 in the default mode and 0b1111 in the WideTags mode.
+	tagMask uint8
+
 	cellWidth  int
 	cellHeight int
 
@@ -36,12 +48,21 @@ type GridConfig struct {
 	CellHeight uint
 
 	// DefaultTile controls the default grid fill.
-	// Only the 3 lower bits matter as a tile tag value can't exceed a value of 7.
+	// Only the 3 lower bits matter as a tile tag value can't exceed a value of 7
+	// (4 bits and a value of 15 in the WideTags mode).
 	// This value is a minor option, but it can be used to populate the grid
 	// with the most common tile.
 	// Although it does fill the grid in an optimized way, it's mostly a convenience option
 	// to make the initialization easier.
 	DefaultTile uint8
+
+	// WideTags enables the 16 tile tags mode.
+	// A tile tag value can be in [0-15] range, but the blocked bits
+	// are stored separately, so the grid needs 1 extra bit per cell.
+	//
+	// Use MakeGridLayer16 to create the layers for such grids.
+	// The blocked cells are always impassable in this mode.
+	WideTags bool
 }
 
 // NewGrid creates a Grid object.
@@ -55,46 +76,26 @@ func NewGrid(config GridConfig) *Grid {
 	}
 
 	g := &Grid{
-		cellWidth:  int(config.CellWidth),
-		cellHeight: int(config.CellHeight),
-
-		fcellWidth:  float64(config.CellWidth),
-		fcellHeight: float64(config.CellHeight),
+		tagMask: 0b111,
 	}
-
-	g.fcellHalfWidth = float64(config.CellWidth / 2)
-	g.fcellHalfHeight = float64(config.CellHeight / 2)
+	g.setCellSize(config.CellWidth, config.CellHeight)
 
 	g.numCols = config.WorldWidth / config.CellWidth
 	g.numRows = config.WorldHeight / config.CellHeight
 
-	numCells := g.numCols * g.numRows
-	numBytes := numCells / 2
-	if numCells%2 != 0 {
-		numBytes++
+	b := make([]byte, gridNumBytes(g.numCols, g.numRows))
+
+	if config.WideTags {
+		g.wideTags = true
+		g.tagMask = 0b1111
+		g.blocked = make([]byte, gridNumBlockedBytes(g.numCols, g.numRows))
 	}
-	b := make([]byte, numBytes)
 
 	defaultTileTag := config.DefaultTile
-	defaultTileTag &= 0b111
+	defaultTileTag &= g.tagMask
 	if defaultTileTag != 0 {
-		v := uint8(0)
-		switch defaultTileTag {
-		case 1:
-			v = 0b0001_0001
-		case 2:
-			v = 0b0010_0010
-		case 3:
-			v = 0b0011_0011
-		case 4:
-			v = 0b0100_0100
-		case 5:
-			v = 0b0101_0101
-		case 6:
-			v = 0b0110_0110
-		case 7:
-			v = 0b0111_0111
-		}
+		// Both cell nibbles get the same tag.
+		v := defaultTileTag | (defaultTileTag << 4)
 		for i := range b {
 			b[i] = v
 		}
@@ -105,6 +106,17 @@ func NewGrid(config GridConfig) *Grid {
 	return g
 }
 
+func (g *Grid) setCellSize(cellWidth, cellHeight uint) {
+	g.cellWidth = int(cellWidth)
+	g.cellHeight = int(cellHeight)
+
+	g.fcellWidth = float64(cellWidth)
+	g.fcellHeight = float64(cellHeight)
+
+	g.fcellHalfWidth = float64(cellWidth / 2)
+	g.fcellHalfHeight = float64(cellHeight / 2)
+}
+
 // NumCols returns the number of columns this grid has.
 func (g *Grid) NumCols() int { return int(g.numCols) }
 
@@ -125,12 +137,60 @@ func (g *Grid) SetCellTile(c GridCoord, tileTag uint8) {
 	if byteIndex < uint(len(g.bytes)) {
 		shift := (i % 2) * 4
 		b := g.bytes[byteIndex]
-		b &^= 0b0111 << shift            // Clear the lower 3 data bits
-		b |= (tileTag & 0b0111) << shift // Mix it with provided bits
+		b &^= g.tagMask << shift            // Clear the tag data bits
+		b |= (tileTag & g.tagMask) << shift // Mix it with provided bits
 		g.bytes[byteIndex] = b
 	}
 }
 
+// BlockedCellFlag marks a blocked cell in the cell values.
+// The cell values are used by ParseGridASCII legend and by SetCellValue.
+//
+// For the WideTags mode grids, use WideBlockedCellFlag instead.
+const BlockedCellFlag uint8 = 0b1000
+
+// WideBlockedCellFlag is like BlockedCellFlag, but for the WideTags mode grids.
+// The 4 lower bits of the cell value are used by the tile tag in that mode.
+const WideBlockedCellFlag uint8 = 0b1_0000
+
+// IsValidCellValue reports whether v is a valid cell value.
+// A cell value combines the tile tag and an optional blocked flag.
+//
+// In the default mode, a tile tag is in [0, 7] range and
+// BlockedCellFlag marks a blocked cell.
+// In the WideTags mode, a tile tag is in [0, 15] range and
+// WideBlockedCellFlag marks a blocked cell.
+func IsValidCellValue(v uint8, wideTags bool) bool {
+	if wideTags {
+		return v&^WideBlockedCellFlag <= 0b1111
+	}
+	return v&^BlockedCellFlag <= 0b111
+}
+
+// SetCellValue assigns both the tile tag and the blocked bit
+// for the given cell coordinate (see IsValidCellValue).
+// It's a shorthand for a SetCellTile+SetCellIsBlocked pair.
+func (g *Grid) SetCellValue(c GridCoord, v uint8) {
+	g.SetCellTile(c, v)
+	g.SetCellIsBlocked(c, v&g.blockedCellFlag() != 0)
+}
+
+// getCellValue is the opposite of SetCellValue.
+func (g *Grid) getCellValue(c GridCoord) uint8 {
+	tag, blocked := g.GetCellTile2(c)
+	if blocked {
+		tag |= g.blockedCellFlag()
+	}
+	return tag
+}
+
+func (g *Grid) blockedCellFlag() uint8 {
+	if g.wideTags {
+		return WideBlockedCellFlag
+	}
+	return BlockedCellFlag
+}
+
 // SetCellIsBlocked writes to a special tile "blocked" bit (0 or 1).
 // You can read that bit with GetCellIsBlocked, it is also reported with GetCellTile2.
 // Blocked cells usually can not be traversed, but it can be changed with the layer
@@ -141,6 +201,15 @@ func (g *Grid) SetCellIsBlocked(c GridCoord, blocked bool) {
 		bit = 0b1000
 	}
 	i := uint(c.Y)*g.numCols + uint(c.X)
+	if g.wideTags {
+		byteIndex := i / 8
+		if byteIndex < uint(len(g.blocked)) {
+			bitShift := i % 8
+			g.blocked[byteIndex] &^= 1 << bitShift
+			g.blocked[byteIndex] |= (bit >> 3) << bitShift
+		}
+		return
+	}
 	byteIndex := i / 2
 	if byteIndex < uint(len(g.bytes)) {
 		shift := (i % 2) * 4
@@ -168,7 +237,7 @@ func (g *Grid) GetCellTile(c GridCoord) uint8 {
 	i := y*g.numCols + x
 	byteIndex := i / 2
 	shift := (i % 2) * 4
-	return ((readByte(g.bytes, byteIndex)) >> shift) & 0b111
+	return ((readByte(g.bytes, byteIndex)) >> shift) & g.tagMask
 }
 
 // GetCellTile2 is like GetCellTile, but also reports
@@ -185,7 +254,10 @@ func (g *Grid) GetCellTile2(c GridCoord) (uint8, bool) {
 	byteIndex := i / 2
 	shift := (i % 2) * 4
 	bits := ((readByte(g.bytes, byteIndex)) >> shift)
-	return bits & 0b111, bits&0b1000 != 0
+	if g.wideTags {
+		return bits & g.tagMask, g.isBlocked(i)
+	}
+	return bits & g.tagMask, bits&0b1000 != 0
 }
 
 // GetCellIsBlocked reports whether the given cell was marked as blocked.
@@ -199,11 +271,26 @@ func (g *Grid) GetCellIsBlocked(c GridCoord) bool {
 		return false
 	}
 	i := y*g.numCols + x
+	if g.wideTags {
+		return g.isBlocked(i)
+	}
 	byteIndex := i / 2
 	shift := (i % 2) * 4
 	return ((readByte(g.bytes, byteIndex))>>shift)&0b1000 != 0
 }
 
+// isBlocked reads the separate blocked bit storage.
+// It should only be used in the WideTags mode.
+func (g *Grid) isBlocked(i uint) bool {
+	return readByte(g.blocked, i/8)&(1<<(i%8)) != 0
+}
+
+// isBlockedCell is like isBlocked, but it accepts the cell coordinates.
+// It should only be used in the WideTags mode.
+func (g *Grid) isBlockedCell(x, y uint) bool {
+	return g.isBlocked(y*g.numCols + x)
+}
+
 // GetCellCost returns a travelling cost for a given cell as specified in the layer.
 // The return value interpreted as this: 0 is a blocked path while any other value
 // is a travelling cost.
@@ -216,10 +303,28 @@ func (g *Grid) GetCellCost(c GridCoord, l GridLayer) uint8 {
 		// Consider out of bound cells as blocked.
 		return 0
 	}
-	return g.getCellCost(x, y, l)
+	if g.wideTags {
+		return g.getCellCost(x, y, l)
+	}
+	return g.getTagCost(x, y, l)
 }
 
+// getCellCost is like GetCellCost, but without the bound checks.
+// Unlike getTagCost, it works correctly with any grid.
 func (g *Grid) getCellCost(x, y uint, l GridLayer) uint8 {
+	if g.wideTags && g.isBlockedCell(x, y) {
+		return 0
+	}
+	return g.getTagCost(x, y, l)
+}
+
+// getTagCost returns the cell cost without checking the WideTags mode blocked bits.
+// This keeps the default mode hot path branch-free.
+//
+// For the WideTags mode grids, the callers should
+// also check the blocked bits via isBlockedCell.
+// The pathfinders usually decide whether it's needed once per search.
+func (g *Grid) getTagCost(x, y uint, l GridLayer) uint8 {
 	i := y*g.numCols + x
 	byteIndex := i / 2
 	shift := (i % 2) * 4
@@ -227,6 +332,23 @@ func (g *Grid) getCellCost(x, y uint, l GridLayer) uint8 {
 	return l.getFast(tileTag)
 }
 
+// canMoveDiagonally reports whether a diagonal step from {x, y} by the offset
+// is allowed by the corner cutting rules.
+// The destination cell is expected to be checked by the caller.
+//
+// A diagonal step can never squeeze between two impassable cells.
+// Unless cornerCutting is true, both adjacent axial cells must be passable.
+func (g *Grid) canMoveDiagonally(x, y uint, offset GridCoord, l GridLayer, cornerCutting bool) bool {
+	// The destination is known to be inside the grid,
+	// therefore both adjacent cells are inside the grid as well.
+	horizontal := g.getCellCost(uint(int(x)+offset.X), y, l) != 0
+	vertical := g.getCellCost(x, uint(int(y)+offset.Y), l) != 0
+	if cornerCutting {
+		return horizontal || vertical
+	}
+	return horizontal && vertical
+}
+
 // AlignPos is an easy way to center the world position inside a grid cell.
 // For instance, with a cell size of 32x32, a {10,10} pos would become {16,16}.
 func (g *Grid) AlignPos(x, y float64) (float64, float64) {
@@ -262,3 +384,20 @@ func (g *Grid) UnpackCoord(v uint32) GridCoord {
 	y := int(u32 >> 16)
 	return GridCoord{X: x, Y: y}
 }
+
+// gridNumBytes returns the number of bytes needed to store the grid cells.
+// Every cell takes 4 bits.
+func gridNumBytes(numCols, numRows uint) uint {
+	numCells := numCols * numRows
+	numBytes := numCells / 2
+	if numCells%2 != 0 {
+		numBytes++
+	}
+	return numBytes
+}
+
+// gridNumBlockedBytes returns the number of bytes needed
+// to store the separate blocked bits (see GridConfig.WideTags).
+func gridNumBlockedBytes(numCols, numRows uint) uint {
+	return (numCols*numRows + 7) / 8
+}
@@ -0,0 +1,136 @@
+package pathing
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ParseGridASCII creates a Grid from its text representation.
+// Every line is a grid row and every rune is a grid cell.
+//
+// The legend maps a rune to the cell value (see IsValidCellValue):
+// the lower bits are the tile tag and the BlockedCellFlag bit marks the cell as blocked.
+// For instance, a legend entry like {'c': 2 | pathing.BlockedCellFlag}
+// would create a blocked cell with a tile tag of 2.
+// If the config has WideTags set, use WideBlockedCellFlag instead.
+//
+// The config cell size is used as is, but the world size is
+// derived from the lines: all of them should have the same number of runes.
+//
+// An error is returned if the lines are malformed or
+// if there is a rune that is missing in the legend.
+func ParseGridASCII(lines []string, legend map[rune]uint8, config GridConfig) (*Grid, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("pathing: parse ASCII grid: no lines")
+	}
+	for r, v := range legend {
+		if !IsValidCellValue(v, config.WideTags) {
+			return nil, fmt.Errorf("pathing: parse ASCII grid: legend value %d of %q is not a valid cell value", v, r)
+		}
+	}
+
+	numCols := utf8.RuneCountInString(lines[0])
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n != numCols {
+			return nil, fmt.Errorf("pathing: parse ASCII grid: line %d: expected %d runes, found %d", i+1, numCols, n)
+		}
+	}
+
+	if config.CellWidth == 0 {
+		config.CellWidth = 32
+	}
+	if config.CellHeight == 0 {
+		config.CellHeight = 32
+	}
+	config.WorldWidth = uint(numCols) * config.CellWidth
+	config.WorldHeight = uint(len(lines)) * config.CellHeight
+	config.DefaultTile = 0
+	g := NewGrid(config)
+
+	for y, line := range lines {
+		x := 0
+		for _, r := range line {
+			v, ok := legend[r]
+			if !ok {
+				return nil, fmt.Errorf("pathing: parse ASCII grid: line %d: column %d: %q is not in the legend", y+1, x+1, r)
+			}
+			g.SetCellValue(GridCoord{X: x, Y: y}, v)
+			x++
+		}
+	}
+
+	return g, nil
+}
+
+// FormatASCII returns a text representation of the grid.
+// It's the opposite of the ParseGridASCII function:
+// the result can be parsed back using the same legend.
+//
+// If several runes map to the same cell value, the smallest rune is used.
+// The cells with values that are missing in the legend are printed as '?'.
+func (g *Grid) FormatASCII(legend map[rune]uint8) []string {
+	rows := g.formatASCII(legend)
+	return asciiRowsToLines(rows)
+}
+
+// FormatASCIIPath is like FormatASCII, but it also draws the path on top of the grid.
+// It's useful to debug the pathfinding results.
+//
+// The path start is marked as 'A', its finish is marked as 'B'
+// and all cells in between are marked as '*'.
+// The path steps that lead outside of the grid are not drawn.
+func (g *Grid) FormatASCIIPath(legend map[rune]uint8, from GridCoord, p GridPath) []string {
+	rows := g.formatASCII(legend)
+	mark := func(c GridCoord, r rune) {
+		if uint(c.X) < g.numCols && uint(c.Y) < g.numRows {
+			rows[c.Y][c.X] = r
+		}
+	}
+	pos := from
+	p.Rewind()
+	for p.HasNext() {
+		pos = pos.Move(p.Next())
+		mark(pos, '*')
+	}
+	mark(from, 'A')
+	mark(pos, 'B')
+	return asciiRowsToLines(rows)
+}
+
+func (g *Grid) formatASCII(legend map[rune]uint8) [][]rune {
+	var runes [1 << 5]rune
+	for i := range runes {
+		runes[i] = -1
+	}
+	for r, v := range legend {
+		if int(v) >= len(runes) {
+			continue
+		}
+		if runes[v] == -1 || r < runes[v] {
+			runes[v] = r
+		}
+	}
+
+	rows := make([][]rune, g.numRows)
+	for y := range rows {
+		row := make([]rune, g.numCols)
+		for x := range row {
+			r := runes[g.getCellValue(GridCoord{X: x, Y: y})]
+			if r == -1 {
+				r = '?'
+			}
+			row[x] = r
+		}
+		rows[y] = row
+	}
+	return rows
+}
+
+func asciiRowsToLines(rows [][]rune) []string {
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = string(row)
+	}
+	return lines
+}
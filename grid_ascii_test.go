@@ -0,0 +1,191 @@
+package pathing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+)
+
+var testASCIILegend = map[rune]uint8{
+	'.': 0,
+	'x': 1,
+	'o': 2,
+	'w': 3,
+	'c': 2 | pathing.BlockedCellFlag,
+	'~': 0 | pathing.BlockedCellFlag,
+	'█': 7 | pathing.BlockedCellFlag,
+}
+
+func TestParseGridASCII(t *testing.T) {
+	lines := []string{
+		"...xx..█",
+		".c.~~..w",
+		"oo.....█",
+	}
+	g, err := pathing.ParseGridASCII(lines, testASCIILegend, pathing.GridConfig{CellWidth: 16, CellHeight: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.NumCols() != 8 || g.NumRows() != 3 {
+		t.Fatalf("grid size mismatch: have %dx%d, want 8x3", g.NumCols(), g.NumRows())
+	}
+	if x, y := g.CoordToPos(pathing.GridCoord{X: 1, Y: 1}); x != 24 || y != 12 {
+		t.Fatalf("cell size mismatch: (1,1) pos is %v,%v", x, y)
+	}
+
+	cells := []struct {
+		c       pathing.GridCoord
+		tag     uint8
+		blocked bool
+	}{
+		{pathing.GridCoord{X: 0, Y: 0}, 0, false},
+		{pathing.GridCoord{X: 3, Y: 0}, 1, false},
+		{pathing.GridCoord{X: 7, Y: 0}, 7, true},
+		{pathing.GridCoord{X: 1, Y: 1}, 2, true},
+		{pathing.GridCoord{X: 3, Y: 1}, 0, true},
+		{pathing.GridCoord{X: 7, Y: 1}, 3, false},
+		{pathing.GridCoord{X: 1, Y: 2}, 2, false},
+	}
+	for _, cell := range cells {
+		tag, blocked := g.GetCellTile2(cell.c)
+		if tag != cell.tag || blocked != cell.blocked {
+			t.Fatalf("%v cell mismatch:\nhave: %d %v\nwant: %d %v", cell.c, tag, blocked, cell.tag, cell.blocked)
+		}
+	}
+
+	formatted := g.FormatASCII(testASCIILegend)
+	if strings.Join(formatted, "\n") != strings.Join(lines, "\n") {
+		t.Fatalf("round trip mismatch:\nhave:\n%s\nwant:\n%s", strings.Join(formatted, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestParseGridASCIIWideTags(t *testing.T) {
+	legend := map[rune]uint8{
+		'.': 0,
+		'x': 7,
+		'~': 12,
+		'#': 15 | pathing.WideBlockedCellFlag,
+	}
+	lines := []string{
+		"..x~",
+		"#~~.",
+	}
+	g, err := pathing.ParseGridASCII(lines, legend, pathing.GridConfig{WideTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag, blocked := g.GetCellTile2(pathing.GridCoord{X: 0, Y: 1}); tag != 15 || !blocked {
+		t.Fatalf("(0,1) cell mismatch: have %d %v", tag, blocked)
+	}
+	if tag, blocked := g.GetCellTile2(pathing.GridCoord{X: 3, Y: 0}); tag != 12 || blocked {
+		t.Fatalf("(3,0) cell mismatch: have %d %v", tag, blocked)
+	}
+	formatted := g.FormatASCII(legend)
+	if strings.Join(formatted, "\n") != strings.Join(lines, "\n") {
+		t.Fatalf("round trip mismatch:\nhave:\n%s\nwant:\n%s", strings.Join(formatted, "\n"), strings.Join(lines, "\n"))
+	}
+
+	// The same legend can't be used for a default grid.
+	_, err = pathing.ParseGridASCII(lines, legend, pathing.GridConfig{})
+	if err == nil {
+		t.Fatal("expected an error for the 4-bit tags without WideTags")
+	}
+}
+
+func TestParseGridASCIIErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		legend map[rune]uint8
+		err    string
+	}{
+		{
+			name: "no_lines",
+			err:  "pathing: parse ASCII grid: no lines",
+		},
+		{
+			name:  "uneven_lines",
+			lines: []string{"...", "....", "..."},
+			err:   "pathing: parse ASCII grid: line 2: expected 3 runes, found 4",
+		},
+		{
+			name:  "unknown_rune",
+			lines: []string{"...", "..?"},
+			err:   `pathing: parse ASCII grid: line 2: column 3: '?' is not in the legend`,
+		},
+		{
+			name:   "bad_legend",
+			lines:  []string{"..."},
+			legend: map[rune]uint8{'.': 16},
+			err:    `pathing: parse ASCII grid: legend value 16 of '.' is not a valid cell value`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			legend := test.legend
+			if legend == nil {
+				legend = testASCIILegend
+			}
+			_, err := pathing.ParseGridASCII(test.lines, legend, pathing.GridConfig{})
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("error mismatch:\nhave: %v\nwant: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestGridFormatASCII(t *testing.T) {
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 4, WorldHeight: 32 * 2})
+	g.SetCellTile(pathing.GridCoord{X: 1, Y: 0}, 5)
+	g.SetCellTile(pathing.GridCoord{X: 2, Y: 1}, 1)
+	legend := map[rune]uint8{
+		'.': 0,
+		' ': 0,
+		'x': 1,
+	}
+	have := strings.Join(g.FormatASCII(legend), "\n")
+	want := strings.Join([]string{
+		" ?  ",
+		"  x ",
+	}, "\n")
+	if have != want {
+		t.Fatalf("format mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestGridFormatASCIIPath(t *testing.T) {
+	lines := []string{
+		"........",
+		"..xxxx..",
+		"....x...",
+		"........",
+	}
+	g, err := pathing.ParseGridASCII(lines, testASCIILegend, pathing.GridConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	from := pathing.GridCoord{X: 3, Y: 2}
+	to := pathing.GridCoord{X: 7, Y: 2}
+	astar := pathing.NewAStar(pathing.AStarConfig{NumCols: 8, NumRows: 4})
+	result := astar.BuildPath(g, from, to, l)
+
+	have := strings.Join(g.FormatASCIIPath(testASCIILegend, from, result.Steps), "\n")
+	want := strings.Join([]string{
+		"........",
+		"..xxxx..",
+		"...Ax..B",
+		"...*****",
+	}, "\n")
+	if have != want {
+		t.Fatalf("format mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+
+	// The formatting should not affect the path iterator.
+	if result.Steps.Len() != 6 || result.Steps.Next() != pathing.DirDown {
+		t.Fatalf("unexpected path state: %v", result.Steps)
+	}
+}
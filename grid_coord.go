@@ -41,6 +41,14 @@ func (c GridCoord) reversedMove(d Direction) GridCoord {
 		return GridCoord{X: c.X + 1, Y: c.Y}
 	case DirUp:
 		return GridCoord{X: c.X, Y: c.Y + 1}
+	case DirDownRight:
+		return GridCoord{X: c.X - 1, Y: c.Y - 1}
+	case DirDownLeft:
+		return GridCoord{X: c.X + 1, Y: c.Y - 1}
+	case DirUpLeft:
+		return GridCoord{X: c.X + 1, Y: c.Y + 1}
+	case DirUpRight:
+		return GridCoord{X: c.X - 1, Y: c.Y + 1}
 	default:
 		return c
 	}
@@ -54,6 +62,7 @@ func (c GridCoord) reversedMove(d Direction) GridCoord {
 //
 //   - {2,2}.Move(DirLeft) would give {1,2}
 //   - {2,2}.Move(DirDown) would give {2,3}
+//   - {2,2}.Move(DirUpRight) would give {3,1}
 func (c GridCoord) Move(d Direction) GridCoord {
 	switch d {
 	case DirRight:
@@ -64,6 +73,14 @@ func (c GridCoord) Move(d Direction) GridCoord {
 		return GridCoord{X: c.X - 1, Y: c.Y}
 	case DirUp:
 		return GridCoord{X: c.X, Y: c.Y - 1}
+	case DirDownRight:
+		return GridCoord{X: c.X + 1, Y: c.Y + 1}
+	case DirDownLeft:
+		return GridCoord{X: c.X - 1, Y: c.Y + 1}
+	case DirUpLeft:
+		return GridCoord{X: c.X - 1, Y: c.Y - 1}
+	case DirUpRight:
+		return GridCoord{X: c.X + 1, Y: c.Y - 1}
 	default:
 		return c
 	}
@@ -74,6 +91,17 @@ func (c GridCoord) Dist(other GridCoord) int {
 	return intabs(c.X-other.X) + intabs(c.Y-other.Y)
 }
 
+// octileDist finds an octile distance between the two coordinates.
+// orth is a cost of the axial step, diag is a cost of the diagonal step.
+func (c GridCoord) octileDist(other GridCoord, orth, diag int) int {
+	dx := intabs(c.X - other.X)
+	dy := intabs(c.Y - other.Y)
+	if dx < dy {
+		dx, dy = dy, dx
+	}
+	return orth*(dx-dy) + diag*dy
+}
+
 func intabs(x int) int {
 	if x < 0 {
 		return -x
@@ -0,0 +1,320 @@
+package pathing
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// The grid binary format (all integers are little-endian):
+//
+//	magic       [4]byte "PGRD"
+//	version     uint16
+//	flags       uint16 (bit 0 is set for the WideTags grids)
+//	numCols     uint32
+//	numRows     uint32
+//	cellWidth   uint32
+//	cellHeight  uint32
+//	cells       [(numCols*numRows+1)/2]byte
+//	blocked     [(numCols*numRows+7)/8]byte (only for the WideTags grids)
+//	checksum    uint32 (CRC-32 IEEE of all the bytes above)
+//
+// The cells and blocked data are the Grid bytes, as is.
+const (
+	gridEncodingMagic   = "PGRD"
+	gridEncodingVersion = 1
+
+	gridHeaderSize   = 24
+	gridChecksumSize = 4
+
+	gridFlagWideTags = 1 << 0
+
+	// The packed coordinates use 16 bits per axis,
+	// so bigger grids are rejected by the decoder.
+	gridEncodingMaxSide = 0xffff
+)
+
+var (
+	// ErrGridDataTruncated is returned when the grid data ends unexpectedly.
+	ErrGridDataTruncated = errors.New("pathing: truncated grid data")
+
+	// ErrGridDataFormat is returned when the data is not a valid grid encoding
+	// (e.g. the header magic or the grid dimensions are invalid).
+	ErrGridDataFormat = errors.New("pathing: invalid grid data format")
+
+	// ErrGridDataVersion is returned when the grid data was encoded
+	// using a format version that is not supported by this package.
+	ErrGridDataVersion = errors.New("pathing: unsupported grid data version")
+
+	// ErrGridDataChecksum is returned when the grid data checksum doesn't match.
+	// It usually means that the data was corrupted.
+	ErrGridDataChecksum = errors.New("pathing: grid data checksum mismatch")
+)
+
+// MarshalBinary encodes the grid into a binary form.
+// It implements the encoding.BinaryMarshaler interface.
+//
+// The cell tiles, their blocked bits, the grid dimensions,
+// the cell sizes and the WideTags mode are stored;
+// use UnmarshalBinary to restore the grid.
+// The encoded data has a versioned header and a checksum.
+func (g *Grid) MarshalBinary() ([]byte, error) {
+	if err := g.checkEncodable(); err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, gridHeaderSize+len(g.bytes)+len(g.blocked)+gridChecksumSize)
+	data = g.appendHeader(data)
+	data = append(data, g.bytes...)
+	data = append(data, g.blocked...)
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+	return data, nil
+}
+
+// UnmarshalBinary decodes the grid encoded by MarshalBinary or WriteTo.
+// It implements the encoding.BinaryUnmarshaler interface.
+//
+// The grid contents are replaced, including its size;
+// a zero value Grid can be used as a decoding destination.
+// The grid is not modified if an error is returned.
+//
+// The returned errors wrap one of the ErrGridData* errors.
+func (g *Grid) UnmarshalBinary(data []byte) error {
+	if len(data) < gridHeaderSize {
+		return fmt.Errorf("%w: %d bytes header is expected, got %d bytes", ErrGridDataTruncated, gridHeaderSize, len(data))
+	}
+	h, err := decodeGridHeader(data[:gridHeaderSize])
+	if err != nil {
+		return err
+	}
+	size := gridHeaderSize + int(h.numBytes()+h.numBlockedBytes()) + gridChecksumSize
+	if len(data) < size {
+		return fmt.Errorf("%w: %d bytes are expected, got %d bytes", ErrGridDataTruncated, size, len(data))
+	}
+	if len(data) > size {
+		return fmt.Errorf("%w: %d unexpected trailing bytes", ErrGridDataFormat, len(data)-size)
+	}
+	body := data[:size-gridChecksumSize]
+	checksum := binary.LittleEndian.Uint32(data[size-gridChecksumSize:])
+	if crc32.ChecksumIEEE(body) != checksum {
+		return ErrGridDataChecksum
+	}
+
+	cells := reuseBytes(g.bytes, h.numBytes())
+	copy(cells, body[gridHeaderSize:])
+	var blocked []byte
+	if h.wideTags {
+		blocked = reuseBytes(g.blocked, h.numBlockedBytes())
+		copy(blocked, body[gridHeaderSize+len(cells):])
+	}
+	h.apply(g, cells, blocked)
+	return nil
+}
+
+// WriteTo writes the grid binary form to w.
+// It implements the io.WriterTo interface.
+//
+// The written data is identical to the MarshalBinary result,
+// but the grid cells are not copied into a temporary buffer.
+func (g *Grid) WriteTo(w io.Writer) (int64, error) {
+	if err := g.checkEncodable(); err != nil {
+		return 0, err
+	}
+
+	var header [gridHeaderSize]byte
+	g.appendHeader(header[:0])
+	checksum := crc32.ChecksumIEEE(header[:])
+	checksum = crc32.Update(checksum, crc32.IEEETable, g.bytes)
+	checksum = crc32.Update(checksum, crc32.IEEETable, g.blocked)
+	var checksumBytes [gridChecksumSize]byte
+	binary.LittleEndian.PutUint32(checksumBytes[:], checksum)
+
+	var written int64
+	for _, b := range [...][]byte{header[:], g.bytes, g.blocked, checksumBytes[:]} {
+		n, err := w.Write(b)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// ReadFrom reads the grid binary form from r.
+// It implements the io.ReaderFrom interface.
+//
+// It reads exactly as many bytes as the encoded grid takes,
+// so several grids can be read from the same stream.
+//
+// See UnmarshalBinary for more details.
+func (g *Grid) ReadFrom(r io.Reader) (int64, error) {
+	var header [gridHeaderSize]byte
+	n, err := io.ReadFull(r, header[:])
+	read := int64(n)
+	if err != nil {
+		return read, gridReadError(err)
+	}
+	h, err := decodeGridHeader(header[:])
+	if err != nil {
+		return read, err
+	}
+
+	// New slices are always allocated here, so the
+	// grid is not modified if the read fails.
+	cells, err := readGridBytes(r, h.numBytes())
+	read += int64(len(cells))
+	if err != nil {
+		return read, gridReadError(err)
+	}
+	var blocked []byte
+	if h.wideTags {
+		blocked, err = readGridBytes(r, h.numBlockedBytes())
+		read += int64(len(blocked))
+		if err != nil {
+			return read, gridReadError(err)
+		}
+	}
+	var checksumBytes [gridChecksumSize]byte
+	n, err = io.ReadFull(r, checksumBytes[:])
+	read += int64(n)
+	if err != nil {
+		return read, gridReadError(err)
+	}
+
+	checksum := crc32.ChecksumIEEE(header[:])
+	checksum = crc32.Update(checksum, crc32.IEEETable, cells)
+	checksum = crc32.Update(checksum, crc32.IEEETable, blocked)
+	if checksum != binary.LittleEndian.Uint32(checksumBytes[:]) {
+		return read, ErrGridDataChecksum
+	}
+
+	h.apply(g, cells, blocked)
+	return read, nil
+}
+
+// readGridBytes reads exactly n bytes from r.
+//
+// The size comes from an unverified header, so the buffer
+// is grown along with the data that was actually read.
+// A truncated stream with a huge grid header can't make us
+// allocate more than ~2x of the stream data size.
+func readGridBytes(r io.Reader, n uint) ([]byte, error) {
+	const chunkSize = 64 * 1024
+	size := n
+	if size > chunkSize {
+		size = chunkSize
+	}
+	buf := make([]byte, 0, size)
+	for uint(len(buf)) < n {
+		if len(buf) == cap(buf) {
+			// Let the append decide the new capacity.
+			buf = append(buf, 0)[:len(buf)]
+		}
+		end := uint(cap(buf))
+		if end > n {
+			end = n
+		}
+		m, err := io.ReadFull(r, buf[len(buf):end])
+		buf = buf[:len(buf)+m]
+		if err != nil {
+			return buf, err
+		}
+	}
+	return buf, nil
+}
+
+func gridReadError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrGridDataTruncated
+	}
+	return err
+}
+
+func (g *Grid) checkEncodable() error {
+	if g.numCols > gridEncodingMaxSide || g.numRows > gridEncodingMaxSide {
+		return fmt.Errorf("%w: %dx%d grid is too big", ErrGridDataFormat, g.numCols, g.numRows)
+	}
+	return nil
+}
+
+func (g *Grid) appendHeader(dst []byte) []byte {
+	dst = append(dst, gridEncodingMagic...)
+	dst = binary.LittleEndian.AppendUint16(dst, gridEncodingVersion)
+	flags := uint16(0)
+	if g.wideTags {
+		flags |= gridFlagWideTags
+	}
+	dst = binary.LittleEndian.AppendUint16(dst, flags)
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(g.numCols))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(g.numRows))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(g.cellWidth))
+	dst = binary.LittleEndian.AppendUint32(dst, uint32(g.cellHeight))
+	return dst
+}
+
+type gridHeader struct {
+	wideTags   bool
+	numCols    uint
+	numRows    uint
+	cellWidth  uint
+	cellHeight uint
+}
+
+func decodeGridHeader(data []byte) (gridHeader, error) {
+	var h gridHeader
+	if string(data[:4]) != gridEncodingMagic {
+		return h, fmt.Errorf("%w: bad magic %q", ErrGridDataFormat, data[:4])
+	}
+	version := binary.LittleEndian.Uint16(data[4:])
+	if version != gridEncodingVersion {
+		return h, fmt.Errorf("%w: %d (expected %d)", ErrGridDataVersion, version, gridEncodingVersion)
+	}
+	flags := binary.LittleEndian.Uint16(data[6:])
+	if flags&^gridFlagWideTags != 0 {
+		return h, fmt.Errorf("%w: unexpected header flags %#x", ErrGridDataFormat, flags)
+	}
+	h.wideTags = flags&gridFlagWideTags != 0
+	h.numCols = uint(binary.LittleEndian.Uint32(data[8:]))
+	h.numRows = uint(binary.LittleEndian.Uint32(data[12:]))
+	h.cellWidth = uint(binary.LittleEndian.Uint32(data[16:]))
+	h.cellHeight = uint(binary.LittleEndian.Uint32(data[20:]))
+	if h.numCols > gridEncodingMaxSide || h.numRows > gridEncodingMaxSide {
+		return h, fmt.Errorf("%w: %dx%d grid is too big", ErrGridDataFormat, h.numCols, h.numRows)
+	}
+	if h.cellWidth == 0 || h.cellHeight == 0 {
+		return h, fmt.Errorf("%w: invalid %dx%d cell size", ErrGridDataFormat, h.cellWidth, h.cellHeight)
+	}
+	return h, nil
+}
+
+func (h *gridHeader) numBytes() uint {
+	return gridNumBytes(h.numCols, h.numRows)
+}
+
+func (h *gridHeader) numBlockedBytes() uint {
+	if !h.wideTags {
+		return 0
+	}
+	return gridNumBlockedBytes(h.numCols, h.numRows)
+}
+
+func (h *gridHeader) apply(g *Grid, cells, blocked []byte) {
+	g.numCols = h.numCols
+	g.numRows = h.numRows
+	g.setCellSize(h.cellWidth, h.cellHeight)
+	g.bytes = cells
+	g.blocked = blocked
+	g.wideTags = h.wideTags
+	g.tagMask = 0b111
+	if h.wideTags {
+		g.tagMask = 0b1111
+	}
+}
+
+func reuseBytes(b []byte, n uint) []byte {
+	if uint(cap(b)) >= n {
+		return b[:n]
+	}
+	return make([]byte, n)
+}
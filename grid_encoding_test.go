@@ -0,0 +1,234 @@
+package pathing_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestGridMarshalBinary(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 50; i++ {
+		numCols := rng.Intn(40) + 1
+		numRows := rng.Intn(40) + 1
+		cellWidth := uint(rng.Intn(64) + 1)
+		cellHeight := uint(rng.Intn(64) + 1)
+		wideTags := i%2 == 1
+		g := pathing.NewGrid(pathing.GridConfig{
+			WorldWidth:  uint(numCols) * cellWidth,
+			WorldHeight: uint(numRows) * cellHeight,
+			CellWidth:   cellWidth,
+			CellHeight:  cellHeight,
+			WideTags:    wideTags,
+		})
+		numTags := 8
+		if wideTags {
+			numTags = 16
+		}
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				c := pathing.GridCoord{X: x, Y: y}
+				g.SetCellTile(c, uint8(rng.Intn(numTags)))
+				g.SetCellIsBlocked(c, rng.Intn(4) == 0)
+			}
+		}
+
+		data, err := g.MarshalBinary()
+		if err != nil {
+			t.Fatalf("test%d: marshal: %v", i, err)
+		}
+		var buf bytes.Buffer
+		n, err := g.WriteTo(&buf)
+		if err != nil {
+			t.Fatalf("test%d: write: %v", i, err)
+		}
+		if n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+			t.Fatalf("test%d: WriteTo and MarshalBinary results mismatch", i)
+		}
+
+		var unmarshaled pathing.Grid
+		if err := unmarshaled.UnmarshalBinary(data); err != nil {
+			t.Fatalf("test%d: unmarshal: %v", i, err)
+		}
+		checkGridsEqual(t, g, &unmarshaled)
+
+		// Reading into a non-empty grid replaces its contents.
+		loaded := pathing.NewGrid(pathing.GridConfig{WorldWidth: 64, WorldHeight: 64})
+		n, err = loaded.ReadFrom(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("test%d: read: %v", i, err)
+		}
+		if n != int64(len(data)) {
+			t.Fatalf("test%d: read %d bytes, want %d", i, n, len(data))
+		}
+		checkGridsEqual(t, g, loaded)
+	}
+}
+
+func TestGridReadFromStream(t *testing.T) {
+	var stream bytes.Buffer
+	grids := make([]*pathing.Grid, 3)
+	for i := range grids {
+		grids[i] = pathing.NewGrid(pathing.GridConfig{
+			WorldWidth:  uint(i+1) * 32,
+			WorldHeight: 96,
+			DefaultTile: uint8(i*5 + 1),
+			WideTags:    i == 2,
+		})
+		if _, err := grids[i].WriteTo(&stream); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range grids {
+		var g pathing.Grid
+		if _, err := g.ReadFrom(&stream); err != nil {
+			t.Fatalf("grid%d: %v", i, err)
+		}
+		checkGridsEqual(t, grids[i], &g)
+	}
+	var g pathing.Grid
+	if _, err := g.ReadFrom(&stream); !errors.Is(err, pathing.ErrGridDataTruncated) {
+		t.Fatalf("expected a truncated data error, got %v", err)
+	}
+}
+
+func TestGridMarshalBinaryEmptyWide(t *testing.T) {
+	// An empty grid has no blocked bytes,
+	// but it should still be decoded as a WideTags grid.
+	g := pathing.NewGrid(pathing.GridConfig{WideTags: true})
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var unmarshaled pathing.Grid
+	if err := unmarshaled.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	var loaded pathing.Grid
+	if _, err := loaded.ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	for _, decoded := range []*pathing.Grid{&unmarshaled, &loaded} {
+		have, err := decoded.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(have, data) {
+			t.Fatalf("re-encoded grid mismatch:\nhave: %v\nwant: %v", have, data)
+		}
+	}
+}
+
+func TestGridUnmarshalBinaryErrors(t *testing.T) {
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 5, WorldHeight: 32 * 3})
+	g.SetCellTile(pathing.GridCoord{X: 1, Y: 1}, 3)
+	g.SetCellIsBlocked(pathing.GridCoord{X: 4, Y: 2}, true)
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	modify := func(f func(data []byte) []byte) []byte {
+		return f(append([]byte(nil), data...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", nil, pathing.ErrGridDataTruncated},
+		{"short_header", data[:10], pathing.ErrGridDataTruncated},
+		{"no_cells", data[:24], pathing.ErrGridDataTruncated},
+		{"no_checksum", data[:len(data)-1], pathing.ErrGridDataTruncated},
+		{"trailing_bytes", append(modify(func(d []byte) []byte { return d }), 0), pathing.ErrGridDataFormat},
+		{"bad_magic", modify(func(d []byte) []byte { d[0] = 'X'; return d }), pathing.ErrGridDataFormat},
+		{"bad_flags", modify(func(d []byte) []byte { d[6] = 0b10; return d }), pathing.ErrGridDataFormat},
+		{"bad_cell_size", modify(func(d []byte) []byte { copy(d[16:20], []byte{0, 0, 0, 0}); return d }), pathing.ErrGridDataFormat},
+		{"too_big", modify(func(d []byte) []byte { d[10] = 1; return d }), pathing.ErrGridDataFormat},
+		{"version", modify(func(d []byte) []byte { d[4] = 2; return d }), pathing.ErrGridDataVersion},
+		{"bad_cells", modify(func(d []byte) []byte { d[25] ^= 0b1000; return d }), pathing.ErrGridDataChecksum},
+		{"bad_checksum", modify(func(d []byte) []byte { d[len(d)-1]++; return d }), pathing.ErrGridDataChecksum},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dst := pathing.NewGrid(pathing.GridConfig{WorldWidth: 64, WorldHeight: 64, DefaultTile: 2})
+			want := pathing.NewGrid(pathing.GridConfig{WorldWidth: 64, WorldHeight: 64, DefaultTile: 2})
+
+			err := dst.UnmarshalBinary(test.data)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("unmarshal error mismatch:\nhave: %v\nwant: %v", err, test.err)
+			}
+			checkGridsEqual(t, want, dst)
+
+			wantReadErr := test.err
+			if test.name == "trailing_bytes" {
+				// The stream reader doesn't care about the extra data.
+				wantReadErr = nil
+			}
+			_, err = dst.ReadFrom(bytes.NewReader(test.data))
+			if !errors.Is(err, wantReadErr) {
+				t.Fatalf("read error mismatch:\nhave: %v\nwant: %v", err, wantReadErr)
+			}
+			if wantReadErr != nil {
+				checkGridsEqual(t, want, dst)
+			}
+		})
+	}
+}
+
+func TestGridReadFromHugeHeader(t *testing.T) {
+	// The header claims a max size grid, but the stream is short.
+	// The decoder should not allocate the whole grid upfront.
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32, WorldHeight: 32})
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(data[8:16], []byte{0xff, 0xff, 0, 0, 0xff, 0xff, 0, 0})
+	data = append(data, make([]byte, 1024)...)
+
+	var dst pathing.Grid
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err = dst.ReadFrom(bytes.NewReader(data))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, pathing.ErrGridDataTruncated) {
+		t.Fatalf("expected a truncated data error, got %v", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1024*1024 {
+		t.Fatalf("too much memory allocated: %d bytes", allocated)
+	}
+}
+
+func checkGridsEqual(t *testing.T, want, have *pathing.Grid) {
+	t.Helper()
+
+	if have.NumCols() != want.NumCols() || have.NumRows() != want.NumRows() {
+		t.Fatalf("grid size mismatch:\nhave: %dx%d\nwant: %dx%d",
+			have.NumCols(), have.NumRows(), want.NumCols(), want.NumRows())
+	}
+	for y := 0; y < want.NumRows(); y++ {
+		for x := 0; x < want.NumCols(); x++ {
+			c := pathing.GridCoord{X: x, Y: y}
+			haveTile, haveBlocked := have.GetCellTile2(c)
+			wantTile, wantBlocked := want.GetCellTile2(c)
+			if haveTile != wantTile || haveBlocked != wantBlocked {
+				t.Fatalf("%v cell mismatch:\nhave: %d %v\nwant: %d %v", c, haveTile, haveBlocked, wantTile, wantBlocked)
+			}
+		}
+	}
+	haveX, haveY := have.CoordToPos(pathing.GridCoord{X: 3, Y: 5})
+	wantX, wantY := want.CoordToPos(pathing.GridCoord{X: 3, Y: 5})
+	if haveX != wantX || haveY != wantY {
+		t.Fatalf("cell size mismatch:\nhave: %v,%v\nwant: %v,%v", haveX, haveY, wantX, wantY)
+	}
+}
@@ -45,12 +45,37 @@ func MakeGridLayerWithBlocked(values [8]uint8, blocked [8]uint8) GridLayer {
 	return GridLayer([2]uint64{tileMapping, blockedMapping})
 }
 
+// MakeGridLayer16 is a GridLayer constructor function for
+// the grids that are created with GridConfig.WideTags option.
+//
+// The array represents a mapping from a tile tag (the key)
+// to a traversal cost (the value).
+//
+// The blocked tiles of such grids are always impassable.
+func MakeGridLayer16(values [16]uint8) GridLayer {
+	var l GridLayer
+	for i := range values {
+		l[i/8] |= uint64(values[i]) << ((i % 8) * 8)
+	}
+	return l
+}
+
 // Get maps a given tile tag into a traversal score.
 // A tile tag is a value in [0-7] range.
+//
+// For the layers that are created with MakeGridLayer16, use Get16.
 func (l GridLayer) Get(tileTag uint8) uint8 {
 	return uint8(l[0] >> (uint64(tileTag) * 8))
 }
 
+// Get16 is like Get, but it's used with the layers that
+// are created with MakeGridLayer16.
+// A tile tag is a value in [0-15] range.
+func (l GridLayer) Get16(tileTag uint8) uint8 {
+	tileTag &= 0b1111
+	return uint8(l[tileTag/8] >> (uint64(tileTag%8) * 8))
+}
+
 func (l GridLayer) getFast(tag uint8) uint8 {
 	return *(*uint8)(unsafe.Add(unsafe.Pointer(&l), tag))
 }
@@ -81,5 +81,36 @@ func TestGridLayer(t *testing.T) {
 				t.Fatalf("(%v).getFast(%d): have %v, want %v", test, i, have, want)
 			}
 		}
+		// The blocked tiles mapping is never returned by Get.
+		l = MakeGridLayerWithBlocked(([8]uint8)(test), ([8]uint8)(test))
+		for i := uint8(8); i <= 15; i++ {
+			if have := l.Get(i); have != 0 {
+				t.Fatalf("(%v).Get(%d): have %v, want 0", test, i, have)
+			}
+		}
+	}
+}
+
+func TestGridLayer16(t *testing.T) {
+	tests := [][]uint8{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+		{1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff},
+		{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 0, 0, 0, 0},
+		{0xff, 0xfe, 0, 0, 100, 0, 0, 3, 2, 0, 0, 50, 0, 0, 0xfa, 1},
+	}
+
+	for _, test := range tests {
+		l := MakeGridLayer16(([16]uint8)(test))
+		for i := uint8(0); i <= 15; i++ {
+			want := test[i]
+			have := l.Get16(i)
+			if want != have {
+				t.Fatalf("(%v).Get16(%d): have %v, want %v", test, i, have, want)
+			}
+			have2 := l.getFast(i)
+			if want != have2 {
+				t.Fatalf("(%v).getFast(%d): have %v, want %v", test, i, have, want)
+			}
+		}
 	}
 }
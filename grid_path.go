@@ -12,16 +12,24 @@ import (
 // specified by the path.
 // The path object is essentialy an iterator.
 //
+// Every axial step is encoded using 2 bits.
+// When a path contains the diagonal steps, all of its steps
+// are encoded using 3 bits, so such path can be only 37 steps long
+// (instead of 56 steps).
+//
 // The path can be copied by simply assigning it, it has a value semantics.
 // You want to pass it around as a value 90% of time,
 // but if you want some function to be able to affect the iterator state,
 // pass it by the pointer.
 type GridPath struct {
 	bytes [gridPathBytes]byte
-	len   byte
+	len   byte // The highest bit is set for the 3-bit steps encoding
 	pos   byte
 }
 
+// gridPathWide is a GridPath.len flag for the 3-bit steps encoding.
+const gridPathWide = 0x80
+
 // MakeGridPath construct a path from the given set of steps.
 func MakeGridPath(steps ...Direction) GridPath {
 	var result GridPath
@@ -35,8 +43,8 @@ func MakeGridPath(steps ...Direction) GridPath {
 // Truncated returns the path of at most n steps long.
 func (p GridPath) Truncated(n byte) GridPath {
 	p2 := p
-	if p2.len > n {
-		p2.len = n
+	if p2.Len() > int(n) {
+		p2.len = n | (p2.len & gridPathWide)
 	}
 	return p2
 }
@@ -44,7 +52,7 @@ func (p GridPath) Truncated(n byte) GridPath {
 // String returns a debug-print version of the path.
 // It's not intended to be used a fast path-to-string method.
 func (p GridPath) String() string {
-	parts := make([]string, 0, p.len)
+	parts := make([]string, 0, p.Len())
 	prevPos := p.pos // Restore the pos later
 	p.Rewind()
 	for p.HasNext() {
@@ -58,7 +66,7 @@ func (p GridPath) String() string {
 // It's not affected by the iterator state; the result is always
 // a total path length regardless of the progress.
 func (p *GridPath) Len() int {
-	return int(p.len)
+	return int(p.len &^ gridPathWide)
 }
 
 // HasNext reports whether there are more steps inside this path.
@@ -69,7 +77,7 @@ func (p *GridPath) HasNext() bool {
 
 // Rewind resets the iterator and allows you to traverse it again.
 func (p *GridPath) Rewind() {
-	p.pos = p.len
+	p.pos = p.len &^ gridPathWide
 }
 
 // Peek returns the next path step without advancing the iterator.
@@ -92,16 +100,23 @@ func (p *GridPath) Skip(n byte) {
 // Peek2 is like Peek(), but it returns two next steps instead of just one.
 func (p *GridPath) Peek2() (Direction, Direction) {
 	// If p.pos is 1, p.pos-2 overflows to 255.
-	// byteIndex will not be inside len(p.bytes), so
+	// It's out of the path bounds, so
 	// p.get(p.pos-2) will return DirNone as it should.
 	// No need to check for that condition here explicitely.
 	return p.get(p.pos - 1), p.get(p.pos - 2)
 }
 
 func (p *GridPath) push(dir Direction) {
+	if dir > DirUp && p.len&gridPathWide == 0 {
+		p.widen()
+	}
 	i := p.pos
 	p.pos++
 	p.len++
+	if p.len&gridPathWide != 0 {
+		p.pushWide(i, dir)
+		return
+	}
 	byteIndex := i / 4
 	bitShift := (i % 4) * 2
 	if byteIndex < uint8(len(p.bytes)) {
@@ -110,6 +125,9 @@ func (p *GridPath) push(dir Direction) {
 }
 
 func (p *GridPath) get(i byte) Direction {
+	if p.len&gridPathWide != 0 {
+		return p.getWide(i)
+	}
 	byteIndex := i / 4
 	bitShift := (i % 4) * 2
 	if byteIndex < uint8(len(p.bytes)) {
@@ -118,6 +136,51 @@ func (p *GridPath) get(i byte) Direction {
 	return DirNone
 }
 
+// widen re-encodes the pushed steps using 3 bits per step.
+func (p *GridPath) widen() {
+	old := *p
+	p.bytes = [gridPathBytes]byte{}
+	p.len |= gridPathWide
+	for i := byte(0); i < old.len; i++ {
+		p.pushWide(i, old.get(i))
+	}
+}
+
+func (p *GridPath) pushWide(i byte, dir Direction) {
+	if i >= gridPathDiagMaxLen {
+		return
+	}
+	bitIndex := uint(i) * 3
+	byteIndex := bitIndex / 8
+	v := uint16(dir) << (bitIndex % 8)
+	p.bytes[byteIndex] |= byte(v)
+	if byteIndex+1 < uint(len(p.bytes)) {
+		p.bytes[byteIndex+1] |= byte(v >> 8)
+	}
+}
+
+func (p *GridPath) getWide(i byte) Direction {
+	if i >= gridPathDiagMaxLen {
+		return DirNone
+	}
+	bitIndex := uint(i) * 3
+	byteIndex := bitIndex / 8
+	v := uint16(p.bytes[byteIndex])
+	if byteIndex+1 < uint(len(p.bytes)) {
+		v |= uint16(p.bytes[byteIndex+1]) << 8
+	}
+	return Direction((v >> (bitIndex % 8)) & 0b111)
+}
+
+// gridPathLimit returns the max path length for the searches
+// that use the given number of neighbors (4 or 8).
+func gridPathLimit(numNeighbors int) int {
+	if numNeighbors == 4 {
+		return gridPathMaxLen
+	}
+	return gridPathDiagMaxLen
+}
+
 func constructPath(from, to GridCoord, pathmap *coordMap) GridPath {
 	// We walk from the finish point towards the start.
 	// The directions are pushed in that order and would lead
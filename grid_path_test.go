@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unsafe"
 
 	"github.com/quasilyte/pathing"
 )
@@ -26,6 +27,14 @@ func testParsePath(s string) pathing.GridPath {
 			directions = append(directions, pathing.DirLeft)
 		case "Up":
 			directions = append(directions, pathing.DirUp)
+		case "DownRight":
+			directions = append(directions, pathing.DirDownRight)
+		case "DownLeft":
+			directions = append(directions, pathing.DirDownLeft)
+		case "UpLeft":
+			directions = append(directions, pathing.DirUpLeft)
+		case "UpRight":
+			directions = append(directions, pathing.DirUpRight)
 		default:
 			panic("unexpected part: " + part)
 		}
@@ -47,6 +56,10 @@ func TestGridPathString(t *testing.T) {
 		"{Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left}",
 		"{Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Right}",
 		"{Up,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left,Left}",
+		"{DownRight}",
+		"{UpLeft,Right,DownLeft,UpRight}",
+		"{Down,DownRight,Right,UpRight,Up,UpLeft,Left,DownLeft}",
+		"{UpRight,UpRight,UpRight,UpRight,UpRight,UpRight,UpRight,UpRight,UpRight,Up}",
 	}
 
 	for _, test := range tests {
@@ -69,6 +82,9 @@ func TestGridPath(t *testing.T) {
 		{pathing.DirDown, pathing.DirLeft, pathing.DirLeft, pathing.DirLeft, pathing.DirLeft, pathing.DirDown},
 		{pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight},
 		{pathing.DirDown, pathing.DirRight, pathing.DirRight, pathing.DirDown, pathing.DirRight, pathing.DirUp, pathing.DirRight, pathing.DirLeft},
+		{pathing.DirUpLeft},
+		{pathing.DirDownRight, pathing.DirDownLeft, pathing.DirUpLeft, pathing.DirUpRight},
+		{pathing.DirUpRight, pathing.DirUp, pathing.DirUpRight, pathing.DirRight, pathing.DirDownRight, pathing.DirDown},
 	}
 
 	for i, directions := range tests {
@@ -84,10 +100,17 @@ func TestGridPath(t *testing.T) {
 
 	r := rand.New(rand.NewSource(time.Now().Unix()))
 	for i := 0; i < 100; i++ {
-		size := r.Intn(20) + 10
+		// The paths with the diagonal steps are shorter.
+		numDirections := 4
+		maxSize := 56
+		if i%2 == 0 {
+			numDirections = 8
+			maxSize = 37
+		}
+		size := r.Intn(maxSize-9) + 10
 		directions := []pathing.Direction{}
 		for j := 0; j < size; j++ {
-			d := r.Intn(4)
+			d := r.Intn(numDirections)
 			directions = append(directions, pathing.Direction(d))
 		}
 		p := pathing.MakeGridPath(directions...)
@@ -109,3 +132,52 @@ func TestGridPath(t *testing.T) {
 		}
 	}
 }
+
+func TestGridPathSize(t *testing.T) {
+	// GridPath is copied around by value, so it should stay compact.
+	if size := unsafe.Sizeof(pathing.GridPath{}); size != 16 {
+		t.Fatalf("unexpected GridPath size: %d", size)
+	}
+}
+
+func TestGridPathTruncated(t *testing.T) {
+	tests := [][]pathing.Direction{
+		{pathing.DirRight, pathing.DirDown, pathing.DirLeft, pathing.DirUp},
+		{pathing.DirRight, pathing.DirDownRight, pathing.DirLeft, pathing.DirUpLeft},
+	}
+	for _, directions := range tests {
+		p := pathing.MakeGridPath(directions...).Truncated(3)
+		want := pathing.MakeGridPath(directions[1:]...).String()
+		if p.Len() != 3 || p.String() != want {
+			t.Fatalf("Truncated(3) of %v:\nhave: %s (len=%d)\nwant: %s", directions, p.String(), p.Len(), want)
+		}
+	}
+}
+
+func TestDirectionReversed(t *testing.T) {
+	tests := []struct {
+		d    pathing.Direction
+		want pathing.Direction
+	}{
+		{pathing.DirRight, pathing.DirLeft},
+		{pathing.DirDown, pathing.DirUp},
+		{pathing.DirLeft, pathing.DirRight},
+		{pathing.DirUp, pathing.DirDown},
+		{pathing.DirDownRight, pathing.DirUpLeft},
+		{pathing.DirDownLeft, pathing.DirUpRight},
+		{pathing.DirUpLeft, pathing.DirDownRight},
+		{pathing.DirUpRight, pathing.DirDownLeft},
+		{pathing.DirNone, pathing.DirNone},
+	}
+
+	for _, test := range tests {
+		have := test.d.Reversed()
+		if have != test.want {
+			t.Fatalf("%s.Reversed():\nhave: %s\nwant: %s", test.d, have, test.want)
+		}
+		c := pathing.GridCoord{X: 5, Y: 5}
+		if c.Move(test.d).Move(have) != c {
+			t.Fatalf("moving %s and then %s does not return to the origin", test.d, have)
+		}
+	}
+}
@@ -343,3 +343,168 @@ func TestGrid(t *testing.T) {
 		}
 	}
 }
+
+func TestWideTagsGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	numCols := rng.Intn(30) + 1
+	numRows := rng.Intn(30) + 1
+	g := pathing.NewGrid(pathing.GridConfig{
+		WorldWidth:  uint(numCols) * 32,
+		WorldHeight: uint(numRows) * 32,
+		DefaultTile: 13,
+		WideTags:    true,
+	})
+
+	values := [16]uint8{}
+	for i := range values {
+		values[i] = uint8(i) * 2
+	}
+	l := pathing.MakeGridLayer16(values)
+
+	for y := 0; y < numRows; y++ {
+		for x := 0; x < numCols; x++ {
+			c := pathing.GridCoord{X: x, Y: y}
+			if tag, blocked := g.GetCellTile2(c); tag != 13 || blocked {
+				t.Fatalf("%v default cell mismatch: have %d %v", c, tag, blocked)
+			}
+		}
+	}
+
+	type cellState struct {
+		tag     uint8
+		blocked bool
+	}
+	cells := make([]cellState, numCols*numRows)
+	for i := 0; i < 4; i++ {
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				c := pathing.GridCoord{X: x, Y: y}
+				state := cellState{tag: uint8(rng.Intn(16)), blocked: rng.Intn(3) == 0}
+				g.SetCellTile(c, state.tag)
+				g.SetCellIsBlocked(c, state.blocked)
+				cells[y*numCols+x] = state
+			}
+		}
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				c := pathing.GridCoord{X: x, Y: y}
+				want := cells[y*numCols+x]
+				tag, blocked := g.GetCellTile2(c)
+				if tag != want.tag || blocked != want.blocked {
+					t.Fatalf("%v cell mismatch:\nhave: %d %v\nwant: %d %v", c, tag, blocked, want.tag, want.blocked)
+				}
+				if g.GetCellTile(c) != want.tag || g.GetCellIsBlocked(c) != want.blocked {
+					t.Fatalf("%v cell getters mismatch", c)
+				}
+				wantCost := values[want.tag]
+				if want.blocked {
+					wantCost = 0
+				}
+				if have := g.GetCellCost(c, l); have != wantCost {
+					t.Fatalf("%v cost mismatch: have %d, want %d", c, have, wantCost)
+				}
+			}
+		}
+	}
+}
+
+func TestSetCellValue(t *testing.T) {
+	tests := []struct {
+		value       uint8
+		wideTags    bool
+		valid       bool
+		wantTag     uint8
+		wantBlocked bool
+	}{
+		{value: 0, valid: true},
+		{value: 7, valid: true, wantTag: 7},
+		{value: 5 | pathing.BlockedCellFlag, valid: true, wantTag: 5, wantBlocked: true},
+		{value: 16},
+		{value: 1 | pathing.WideBlockedCellFlag},
+
+		{value: 0, wideTags: true, valid: true},
+		{value: 9, wideTags: true, valid: true, wantTag: 9},
+		{value: 15 | pathing.WideBlockedCellFlag, wideTags: true, valid: true, wantTag: 15, wantBlocked: true},
+		{value: 32, wideTags: true},
+	}
+
+	for _, test := range tests {
+		if have := pathing.IsValidCellValue(test.value, test.wideTags); have != test.valid {
+			t.Fatalf("IsValidCellValue(%d, %v): have %v, want %v", test.value, test.wideTags, have, test.valid)
+		}
+		if !test.valid {
+			continue
+		}
+		g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 64, WorldHeight: 64, WideTags: test.wideTags})
+		c := pathing.GridCoord{X: 1, Y: 1}
+		g.SetCellValue(c, test.value)
+		if tag, blocked := g.GetCellTile2(c); tag != test.wantTag || blocked != test.wantBlocked {
+			t.Fatalf("SetCellValue(%d) with wideTags=%v: have %d %v, want %d %v",
+				test.value, test.wideTags, tag, blocked, test.wantTag, test.wantBlocked)
+		}
+	}
+}
+
+func TestWideTagsPathfinding(t *testing.T) {
+	legend := map[rune]uint8{
+		'.': 0,
+		'~': 9,
+		'#': 12,
+		'x': 0 | pathing.WideBlockedCellFlag,
+	}
+	lines := []string{
+		"........",
+		".~~~~~~.",
+		".#x####.",
+		"........",
+	}
+	g, err := pathing.ParseGridASCII(lines, legend, pathing.GridConfig{WideTags: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The water tiles (9) are passable, the walls (12) are not.
+	// The blocked cells are never passable even if their tag is.
+	l := pathing.MakeGridLayer16([16]uint8{0: 1, 9: 1})
+
+	from := pathing.GridCoord{X: 2, Y: 3}
+	to := pathing.GridCoord{X: 2, Y: 1}
+	pathfinders := []struct {
+		name  string
+		build func() pathing.GridPath
+	}{
+		{"astar", func() pathing.GridPath {
+			return pathing.NewAStar(pathing.AStarConfig{}).BuildPath(g, from, to, l).Steps
+		}},
+		{"greedy_bfs", func() pathing.GridPath {
+			return pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}).BuildPath(g, from, to, l).Steps
+		}},
+		{"jps", func() pathing.GridPath {
+			return pathing.NewJPS(pathing.JPSConfig{}).BuildPath(g, from, to, l).Steps
+		}},
+	}
+	for _, test := range pathfinders {
+		steps := test.build()
+		pos := from
+		steps.Rewind()
+		for steps.HasNext() {
+			pos = pos.Move(steps.Next())
+			if g.GetCellCost(pos, l) == 0 {
+				t.Fatalf("%s: the path goes through %v", test.name, pos)
+			}
+		}
+		if pos != to {
+			t.Fatalf("%s: the path ends at %v", test.name, pos)
+		}
+		if steps.Len() != 6 {
+			t.Fatalf("%s: expected 6 steps, got %d (%s)", test.name, steps.Len(), steps.String())
+		}
+	}
+
+	astar := pathing.NewAStar(pathing.AStarConfig{NumCols: uint(g.NumCols()), NumRows: uint(g.NumRows())})
+	allocs := testing.AllocsPerRun(10, func() {
+		astar.BuildPath(g, from, to, l)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocs, got %v", allocs)
+	}
+}
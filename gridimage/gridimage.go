@@ -0,0 +1,229 @@
+// Package gridimage converts images to pathing grids and renders
+// the grids (with paths and other debug info) to images.
+//
+// The rendered images are useful for debugging: they can be
+// saved as PNG files and attached to the bug reports.
+package gridimage
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"github.com/quasilyte/pathing"
+)
+
+// Palette maps a pixel color to the grid cell value.
+//
+// A cell value is the same as in pathing.ParseGridASCII legend:
+// the lower bits are the tile tag and the pathing.BlockedCellFlag
+// bit marks the cell as blocked (see pathing.IsValidCellValue).
+type Palette map[color.NRGBA]uint8
+
+// NewGrid creates a Grid from the image; every pixel becomes a grid cell.
+//
+// The config cell size is used as is, but the world size is
+// derived from the image bounds.
+//
+// An error is returned if there is a color that is missing in the palette.
+func NewGrid(img image.Image, palette Palette, config pathing.GridConfig) (*pathing.Grid, error) {
+	for c, v := range palette {
+		if !pathing.IsValidCellValue(v, config.WideTags) {
+			return nil, fmt.Errorf("gridimage: palette value %d of %v is not a valid cell value", v, c)
+		}
+	}
+
+	bounds := img.Bounds()
+	if config.CellWidth == 0 {
+		config.CellWidth = 32
+	}
+	if config.CellHeight == 0 {
+		config.CellHeight = 32
+	}
+	config.WorldWidth = uint(bounds.Dx()) * config.CellWidth
+	config.WorldHeight = uint(bounds.Dy()) * config.CellHeight
+	config.DefaultTile = 0
+	g := pathing.NewGrid(config)
+
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			pixel := color.NRGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			v, ok := palette[pixel]
+			if !ok {
+				return nil, fmt.Errorf("gridimage: pixel (%d,%d): color %v is not in the palette", x, y, pixel)
+			}
+			g.SetCellValue(pathing.GridCoord{X: x, Y: y}, v)
+		}
+	}
+
+	return g, nil
+}
+
+// RenderConfig is a Render() function parameter.
+// See field comments for more details.
+type RenderConfig struct {
+	// CellSize is a size of the rendered cell square, in pixels.
+	//
+	// If left unset (0), a value of 8 is used.
+	CellSize int
+
+	// Layer is used to color the cells: the impassable cells
+	// are dark and the passable cells are light.
+	// The more expensive cells are a bit darker.
+	// See also CostColor.
+	Layer pathing.GridLayer
+
+	// CostColor overrides the cell color selection.
+	// It's called with the layer cost of every cell.
+	CostColor func(cost uint8) color.Color
+
+	// Explored cells are highlighted.
+	// It can be used to visualize the cells visited by the pathfinder.
+	Explored []pathing.GridCoord
+
+	// Paths are drawn on top of the grid.
+	Paths []Path
+}
+
+// Path is a path to draw, see RenderConfig.
+type Path struct {
+	// Start is where the path begins.
+	// It's marked with a StartColor square.
+	Start pathing.GridCoord
+
+	// Steps is a path to draw.
+	// The cell where the path ends is marked with a FinishColor square.
+	Steps pathing.GridPath
+
+	// Color is a color of the path line.
+	//
+	// If left unset (nil), DefaultPathColor is used.
+	Color color.Color
+}
+
+var (
+	// DefaultPathColor is used for the paths without the explicit color.
+	DefaultPathColor color.Color = color.NRGBA{R: 0xe0, G: 0x40, B: 0xe0, A: 0xff}
+
+	// StartColor is used to mark the path start.
+	StartColor color.Color = color.NRGBA{R: 0x20, G: 0xc0, B: 0x20, A: 0xff}
+
+	// FinishColor is used to mark the path finish.
+	FinishColor color.Color = color.NRGBA{R: 0xe0, G: 0x20, B: 0x20, A: 0xff}
+
+	// ExploredColor is used to highlight the explored cells.
+	ExploredColor color.Color = color.NRGBA{R: 0x60, G: 0xa0, B: 0xff, A: 0xff}
+)
+
+// Render draws the grid and the overlays described by the config.
+// Every grid cell becomes a CellSize x CellSize square.
+func Render(g *pathing.Grid, config RenderConfig) *image.NRGBA {
+	cellSize := config.CellSize
+	if cellSize <= 0 {
+		cellSize = 8
+	}
+	costColor := config.CostColor
+	if costColor == nil {
+		costColor = defaultCostColor
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, g.NumCols()*cellSize, g.NumRows()*cellSize))
+	r := renderer{img: img, grid: g, cellSize: cellSize}
+
+	for y := 0; y < g.NumRows(); y++ {
+		for x := 0; x < g.NumCols(); x++ {
+			c := pathing.GridCoord{X: x, Y: y}
+			r.fillCell(c, 0, costColor(g.GetCellCost(c, config.Layer)))
+		}
+	}
+
+	for _, c := range config.Explored {
+		r.blendCell(c, ExploredColor)
+	}
+
+	// The paths are drawn as a chain of the smaller squares,
+	// so the underlying cell colors are still visible.
+	pathInset := cellSize / 3
+	markerInset := cellSize / 6
+	for _, p := range config.Paths {
+		clr := p.Color
+		if clr == nil {
+			clr = DefaultPathColor
+		}
+		pos := p.Start
+		steps := p.Steps
+		steps.Rewind()
+		for steps.HasNext() {
+			pos = pos.Move(steps.Next())
+			r.fillCell(pos, pathInset, clr)
+		}
+		r.fillCell(p.Start, markerInset, StartColor)
+		r.fillCell(pos, markerInset, FinishColor)
+	}
+
+	return img
+}
+
+// WritePNG renders the grid and encodes the result as PNG.
+// See Render for more details.
+func WritePNG(w io.Writer, g *pathing.Grid, config RenderConfig) error {
+	return png.Encode(w, Render(g, config))
+}
+
+func defaultCostColor(cost uint8) color.Color {
+	if cost == 0 {
+		return color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff}
+	}
+	// Every extra cost point makes the cell a bit darker.
+	shade := 0xff - 0x10*int(cost-1)
+	if shade < 0x90 {
+		shade = 0x90
+	}
+	return color.NRGBA{R: uint8(shade), G: uint8(shade), B: uint8(shade), A: 0xff}
+}
+
+type renderer struct {
+	img      *image.NRGBA
+	grid     *pathing.Grid
+	cellSize int
+}
+
+func (r *renderer) cellRect(c pathing.GridCoord, inset int) image.Rectangle {
+	rect := image.Rect(c.X*r.cellSize, c.Y*r.cellSize, (c.X+1)*r.cellSize, (c.Y+1)*r.cellSize)
+	return rect.Inset(inset)
+}
+
+func (r *renderer) fillCell(c pathing.GridCoord, inset int, clr color.Color) {
+	if uint(c.X) >= uint(r.grid.NumCols()) || uint(c.Y) >= uint(r.grid.NumRows()) {
+		return
+	}
+	rect := r.cellRect(c, inset)
+	v := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			r.img.SetNRGBA(x, y, v)
+		}
+	}
+}
+
+// blendCell mixes the cell color with the given color in equal proportions.
+func (r *renderer) blendCell(c pathing.GridCoord, clr color.Color) {
+	if uint(c.X) >= uint(r.grid.NumCols()) || uint(c.Y) >= uint(r.grid.NumRows()) {
+		return
+	}
+	rect := r.cellRect(c, 0)
+	v := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			old := r.img.NRGBAAt(x, y)
+			r.img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8((uint(old.R) + uint(v.R)) / 2),
+				G: uint8((uint(old.G) + uint(v.G)) / 2),
+				B: uint8((uint(old.B) + uint(v.B)) / 2),
+				A: 0xff,
+			})
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package gridimage_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+	"github.com/quasilyte/pathing/gridimage"
+)
+
+var (
+	colorFloor = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	colorWall  = color.NRGBA{A: 0xff}
+	colorSand  = color.NRGBA{R: 0xff, G: 0xff, A: 0xff}
+	colorCrate = color.NRGBA{R: 0x80, G: 0x40, A: 0xff}
+)
+
+func TestNewGrid(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(10, 20, 14, 22))
+	pixels := [][]color.NRGBA{
+		{colorFloor, colorWall, colorSand, colorFloor},
+		{colorCrate, colorFloor, colorFloor, colorWall},
+	}
+	for y, row := range pixels {
+		for x, clr := range row {
+			img.SetNRGBA(10+x, 20+y, clr)
+		}
+	}
+	palette := gridimage.Palette{
+		colorFloor: 0,
+		colorWall:  1,
+		colorSand:  2,
+		colorCrate: 0 | pathing.BlockedCellFlag,
+	}
+	g, err := gridimage.NewGrid(img, palette, pathing.GridConfig{CellWidth: 16, CellHeight: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legend := map[rune]uint8{
+		'.': 0,
+		'x': 1,
+		's': 2,
+		'c': 0 | pathing.BlockedCellFlag,
+	}
+	have := strings.Join(g.FormatASCII(legend), "\n")
+	want := strings.Join([]string{
+		".xs.",
+		"c..x",
+	}, "\n")
+	if have != want {
+		t.Fatalf("grid mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+	if x, y := g.CoordToPos(pathing.GridCoord{X: 1, Y: 1}); x != 24 || y != 24 {
+		t.Fatalf("cell size mismatch: (1,1) pos is %v,%v", x, y)
+	}
+
+	delete(palette, colorSand)
+	_, err = gridimage.NewGrid(img, palette, pathing.GridConfig{})
+	wantErr := "gridimage: pixel (2,0): color {255 255 0 255} is not in the palette"
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("error mismatch:\nhave: %v\nwant: %s", err, wantErr)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g, err := pathing.ParseGridASCII([]string{
+		"....",
+		".xo.",
+		"....",
+	}, map[rune]uint8{'.': 0, 'x': 1, 'o': 2}, pathing.GridConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := gridimage.RenderConfig{
+		CellSize: 6,
+		Layer:    pathing.MakeGridLayer([8]uint8{1, 0, 3, 0, 0, 0, 0, 0}),
+		Explored: []pathing.GridCoord{{X: 0, Y: 2}, {X: 1, Y: 2}},
+		Paths: []gridimage.Path{
+			{
+				Start: pathing.GridCoord{X: 0, Y: 0},
+				Steps: pathing.MakeGridPath(pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirDown),
+			},
+		},
+	}
+	img := gridimage.Render(g, config)
+	if img.Bounds() != image.Rect(0, 0, 24, 18) {
+		t.Fatalf("image bounds mismatch: %v", img.Bounds())
+	}
+
+	colorAt := func(c pathing.GridCoord, dx, dy int) color.Color {
+		return img.At(c.X*6+dx, c.Y*6+dy)
+	}
+	tests := []struct {
+		name   string
+		cell   pathing.GridCoord
+		dx, dy int
+		want   color.Color
+	}{
+		{"floor", pathing.GridCoord{X: 0, Y: 1}, 3, 3, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+		{"wall", pathing.GridCoord{X: 1, Y: 1}, 3, 3, color.NRGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff}},
+		{"expensive", pathing.GridCoord{X: 2, Y: 1}, 3, 3, color.NRGBA{R: 0xdf, G: 0xdf, B: 0xdf, A: 0xff}},
+		{"explored", pathing.GridCoord{X: 1, Y: 2}, 3, 3, color.NRGBA{R: 0xaf, G: 0xcf, B: 0xff, A: 0xff}},
+		{"path", pathing.GridCoord{X: 2, Y: 0}, 3, 3, gridimage.DefaultPathColor},
+		{"path_edge", pathing.GridCoord{X: 2, Y: 0}, 0, 0, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}},
+		{"start", pathing.GridCoord{X: 0, Y: 0}, 3, 3, gridimage.StartColor},
+		{"finish", pathing.GridCoord{X: 3, Y: 1}, 3, 3, gridimage.FinishColor},
+	}
+	for _, test := range tests {
+		have := color.NRGBAModel.Convert(colorAt(test.cell, test.dx, test.dy))
+		want := color.NRGBAModel.Convert(test.want)
+		if have != want {
+			t.Fatalf("%s: %v color mismatch:\nhave: %v\nwant: %v", test.name, test.cell, have, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gridimage.WritePNG(&buf, g, config); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bounds() != img.Bounds() {
+		t.Fatalf("decoded image bounds mismatch: %v", decoded.Bounds())
+	}
+}
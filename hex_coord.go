@@ -0,0 +1,100 @@
+package pathing
+
+// HexCoord is an axial hex coordinate.
+// See HexGrid for the coordinate systems overview.
+//
+// The Q axis goes to the right and the R axis goes down-right,
+// so moving down-left means {Q-1, R+1}.
+type HexCoord struct {
+	Q int
+	R int
+}
+
+// HexDirection is an enumeration of the six hex movement directions.
+type HexDirection int
+
+const (
+	HexDirRight HexDirection = iota
+	HexDirDownRight
+	HexDirDownLeft
+	HexDirLeft
+	HexDirUpLeft
+	HexDirUpRight
+	HexDirNone // A special sentinel value
+)
+
+// hexNeighborOffsets are indexed by HexDirection.
+var hexNeighborOffsets = [6]HexCoord{
+	{Q: 1},
+	{R: 1},
+	{Q: -1, R: 1},
+	{Q: -1},
+	{R: -1},
+	{Q: 1, R: -1},
+}
+
+var hexDirectionNames = [...]string{
+	"Right",
+	"DownRight",
+	"DownLeft",
+	"Left",
+	"UpLeft",
+	"UpRight",
+	"None",
+}
+
+// String returns a direction name, like "DownLeft".
+func (d HexDirection) String() string {
+	if d < 0 || int(d) >= len(hexDirectionNames) {
+		return "HexDirection(?)"
+	}
+	return hexDirectionNames[d]
+}
+
+// Reversed returns an opposite direction.
+// For instance, HexDirRight would become HexDirLeft.
+func (d HexDirection) Reversed() HexDirection {
+	if d < 0 || d >= HexDirNone {
+		return HexDirNone
+	}
+	return (d + 3) % 6
+}
+
+// OffsetToHex converts an offset coordinate to the axial one.
+func OffsetToHex(c GridCoord) HexCoord {
+	// c.Y&1 works for the negative values too.
+	return HexCoord{Q: c.X - (c.Y-(c.Y&1))/2, R: c.Y}
+}
+
+// Offset converts an axial coordinate to the offset one.
+func (h HexCoord) Offset() GridCoord {
+	return GridCoord{X: h.Q + (h.R-(h.R&1))/2, Y: h.R}
+}
+
+// Add performs a + operation and returns the result coordinate.
+func (h HexCoord) Add(other HexCoord) HexCoord {
+	return HexCoord{Q: h.Q + other.Q, R: h.R + other.R}
+}
+
+// Sub performs a - operation and returns the result coordinate.
+func (h HexCoord) Sub(other HexCoord) HexCoord {
+	return HexCoord{Q: h.Q - other.Q, R: h.R - other.R}
+}
+
+// Move translates the coordinate one step towards the direction.
+//
+// Just like with GridCoord, the coordinates are not validated.
+func (h HexCoord) Move(d HexDirection) HexCoord {
+	if d < 0 || d >= HexDirNone {
+		return h
+	}
+	return h.Add(hexNeighborOffsets[d])
+}
+
+// Dist returns the number of hex steps between the two coordinates.
+func (h HexCoord) Dist(other HexCoord) int {
+	dq := intabs(h.Q - other.Q)
+	dr := intabs(h.R - other.R)
+	ds := intabs(h.Q + h.R - other.Q - other.R)
+	return (dq + dr + ds) / 2
+}
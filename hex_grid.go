@@ -0,0 +1,192 @@
+package pathing
+
+import (
+	"math"
+)
+
+// HexGrid is a hexagonal map that holds the tile tags.
+// You must use NewHexGrid() function to obtain an instance of this type.
+//
+// The hexes are pointy-topped and the odd rows are shifted right
+// by a half of the hex width (the so called "odd-r" layout).
+//
+// There are two coordinate flavors:
+//
+//   - The offset coordinates (a GridCoord): the column and row of the hex.
+//     This is what the HexGrid methods and the pathfinders use.
+//   - The axial coordinates (a HexCoord): they make the hex math simpler,
+//     the HexCoord.Dist and HexCoord.Move are the examples of that.
+//
+// Use OffsetToHex and HexCoord.Offset methods to convert between them.
+//
+// The tile tags are stored exactly like in the Grid, so the same
+// GridLayer objects can be used to interpret them.
+type HexGrid struct {
+	cells *Grid
+
+	hexWidth      float64
+	hexHeight     float64
+	hexHalfWidth  float64
+	hexHalfHeight float64
+	rowHeight     float64
+}
+
+type HexGridConfig struct {
+	// NumCols and NumRows specify the hex map size.
+	NumCols uint
+	NumRows uint
+
+	// HexWidth and HexHeight specify the hex size in pixels.
+	// For a regular hexagon, the width is sqrt(3)/2 of its height.
+	// The rows overlap: the row height is 3/4 of the HexHeight.
+	//
+	// If left unset (0), a 28x32 hex size is used.
+	HexWidth  uint
+	HexHeight uint
+
+	// DefaultTile and WideTags have the same meaning as in GridConfig.
+	DefaultTile uint8
+	WideTags    bool
+}
+
+// NewHexGrid creates a HexGrid object.
+func NewHexGrid(config HexGridConfig) *HexGrid {
+	if config.HexWidth == 0 {
+		config.HexWidth = 28
+	}
+	if config.HexHeight == 0 {
+		config.HexHeight = 32
+	}
+
+	g := &HexGrid{
+		cells: NewGrid(GridConfig{
+			WorldWidth:  config.NumCols,
+			WorldHeight: config.NumRows,
+			CellWidth:   1,
+			CellHeight:  1,
+			DefaultTile: config.DefaultTile,
+			WideTags:    config.WideTags,
+		}),
+		hexWidth:      float64(config.HexWidth),
+		hexHeight:     float64(config.HexHeight),
+		hexHalfWidth:  float64(config.HexWidth) / 2,
+		hexHalfHeight: float64(config.HexHeight) / 2,
+		rowHeight:     float64(config.HexHeight) * 0.75,
+	}
+
+	return g
+}
+
+// NumCols returns the number of hex columns.
+func (g *HexGrid) NumCols() int { return int(g.cells.numCols) }
+
+// NumRows returns the number of hex rows.
+func (g *HexGrid) NumRows() int { return int(g.cells.numRows) }
+
+// SetCellTile assigns a new tile tag to the specified hex.
+// See Grid.SetCellTile for more details.
+func (g *HexGrid) SetCellTile(c GridCoord, tileTag uint8) {
+	g.cells.SetCellTile(c, tileTag)
+}
+
+// SetCellIsBlocked changes the blocked bit of the specified hex.
+// See Grid.SetCellIsBlocked for more details.
+func (g *HexGrid) SetCellIsBlocked(c GridCoord, blocked bool) {
+	g.cells.SetCellIsBlocked(c, blocked)
+}
+
+// GetCellTile returns the hex tile tag.
+// See Grid.GetCellTile for more details.
+func (g *HexGrid) GetCellTile(c GridCoord) uint8 {
+	return g.cells.GetCellTile(c)
+}
+
+// GetCellIsBlocked reports whether the given hex was marked as blocked.
+// See Grid.GetCellIsBlocked for more details.
+func (g *HexGrid) GetCellIsBlocked(c GridCoord) bool {
+	return g.cells.GetCellIsBlocked(c)
+}
+
+// GetCellCost returns a travelling cost for a given hex as specified in the layer.
+// See Grid.GetCellCost for more details.
+func (g *HexGrid) GetCellCost(c GridCoord, l GridLayer) uint8 {
+	return g.cells.GetCellCost(c, l)
+}
+
+// Move is like HexCoord.Move, but it operates on the offset coordinates.
+func (g *HexGrid) Move(c GridCoord, d HexDirection) GridCoord {
+	return OffsetToHex(c).Move(d).Offset()
+}
+
+// AlignPos is an easy way to center the world position inside a hex.
+func (g *HexGrid) AlignPos(x, y float64) (float64, float64) {
+	return g.CoordToPos(g.PosToCoord(x, y))
+}
+
+// PosToCoord converts a world position into a hex offset coordinate.
+// It's the hex that contains the pos (the hex shape is respected,
+// this is not a bounding box check).
+//
+// The {0, 0} hex top-left bounding box corner is at the {0, 0} pos.
+func (g *HexGrid) PosToCoord(x, y float64) GridCoord {
+	return g.PosToHex(x, y).Offset()
+}
+
+// PosToHex is like PosToCoord, but it returns an axial coordinate.
+func (g *HexGrid) PosToHex(x, y float64) HexCoord {
+	// Translate the pos to a regular hex space where
+	// the hex size (center-to-corner distance) is 1.
+	px := (x - g.hexHalfWidth) / g.hexWidth * math.Sqrt(3)
+	py := (y - g.hexHalfHeight) / g.hexHeight * 2
+	q := (math.Sqrt(3)/3)*px - (1.0/3)*py
+	r := (2.0 / 3) * py
+	return hexRound(q, r)
+}
+
+// CoordToPos converts a hex offset coordinate into a world position.
+// The returned pos is the hex center.
+func (g *HexGrid) CoordToPos(c GridCoord) (float64, float64) {
+	x := float64(c.X)*g.hexWidth + g.hexHalfWidth
+	if c.Y&1 != 0 {
+		x += g.hexHalfWidth
+	}
+	y := float64(c.Y)*g.rowHeight + g.hexHalfHeight
+	return x, y
+}
+
+// HexToPos is like CoordToPos, but it accepts an axial coordinate.
+func (g *HexGrid) HexToPos(h HexCoord) (float64, float64) {
+	return g.CoordToPos(h.Offset())
+}
+
+// getCellCost is like GetCellCost, but it skips the extra grid method calls.
+// The coordinate is bound-checked.
+func (g *HexGrid) getCellCost(c GridCoord, l GridLayer) uint8 {
+	if uint(c.X) >= g.cells.numCols || uint(c.Y) >= g.cells.numRows {
+		return 0
+	}
+	x := uint(c.X)
+	y := uint(c.Y)
+	if g.cells.wideTags && g.cells.isBlockedCell(x, y) {
+		return 0
+	}
+	return g.cells.getTagCost(x, y, l)
+}
+
+// hexRound returns the hex that contains the fractional axial coordinate.
+func hexRound(q, r float64) HexCoord {
+	s := -q - r
+	rq := math.Round(q)
+	rr := math.Round(r)
+	rs := math.Round(s)
+	dq := math.Abs(rq - q)
+	dr := math.Abs(rr - r)
+	ds := math.Abs(rs - s)
+	switch {
+	case dq > dr && dq > ds:
+		rq = -rr - rs
+	case dr > ds:
+		rr = -rq - rs
+	}
+	return HexCoord{Q: int(rq), R: int(rr)}
+}
@@ -0,0 +1,254 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+// hexGraph adapts the HexGrid to the Graph interface.
+type hexGraph struct {
+	grid  *pathing.HexGrid
+	layer pathing.GridLayer
+}
+
+func (g *hexGraph) Neighbors(dst []pathing.GridCoord, c pathing.GridCoord) []pathing.GridCoord {
+	for d := pathing.HexDirRight; d < pathing.HexDirNone; d++ {
+		next := g.grid.Move(c, d)
+		if g.grid.GetCellCost(next, g.layer) != 0 {
+			dst = append(dst, next)
+		}
+	}
+	return dst
+}
+
+func (g *hexGraph) EdgeCost(from, to pathing.GridCoord) int {
+	return int(g.grid.GetCellCost(to, g.layer))
+}
+
+func (g *hexGraph) Heuristic(from, to pathing.GridCoord) int {
+	return pathing.OffsetToHex(from).Dist(pathing.OffsetToHex(to))
+}
+
+func TestHexCoord(t *testing.T) {
+	for y := -5; y <= 5; y++ {
+		for x := -5; x <= 5; x++ {
+			c := pathing.GridCoord{X: x, Y: y}
+			h := pathing.OffsetToHex(c)
+			if h.Offset() != c {
+				t.Fatalf("%v => %v => %v", c, h, h.Offset())
+			}
+			for d := pathing.HexDirRight; d < pathing.HexDirNone; d++ {
+				next := h.Move(d)
+				if dist := h.Dist(next); dist != 1 {
+					t.Fatalf("%v.Move(%s) dist is %d", h, d, dist)
+				}
+				if back := next.Move(d.Reversed()); back != h {
+					t.Fatalf("%v.Move(%s).Move(%s) is %v", h, d, d.Reversed(), back)
+				}
+			}
+		}
+	}
+
+	tests := []struct {
+		from pathing.GridCoord
+		dir  pathing.HexDirection
+		want pathing.GridCoord
+	}{
+		// An even row.
+		{pathing.GridCoord{X: 2, Y: 2}, pathing.HexDirRight, pathing.GridCoord{X: 3, Y: 2}},
+		{pathing.GridCoord{X: 2, Y: 2}, pathing.HexDirDownRight, pathing.GridCoord{X: 2, Y: 3}},
+		{pathing.GridCoord{X: 2, Y: 2}, pathing.HexDirDownLeft, pathing.GridCoord{X: 1, Y: 3}},
+		{pathing.GridCoord{X: 2, Y: 2}, pathing.HexDirUpLeft, pathing.GridCoord{X: 1, Y: 1}},
+		{pathing.GridCoord{X: 2, Y: 2}, pathing.HexDirUpRight, pathing.GridCoord{X: 2, Y: 1}},
+		// An odd row.
+		{pathing.GridCoord{X: 2, Y: 1}, pathing.HexDirLeft, pathing.GridCoord{X: 1, Y: 1}},
+		{pathing.GridCoord{X: 2, Y: 1}, pathing.HexDirDownRight, pathing.GridCoord{X: 3, Y: 2}},
+		{pathing.GridCoord{X: 2, Y: 1}, pathing.HexDirDownLeft, pathing.GridCoord{X: 2, Y: 2}},
+		{pathing.GridCoord{X: 2, Y: 1}, pathing.HexDirUpLeft, pathing.GridCoord{X: 2, Y: 0}},
+		{pathing.GridCoord{X: 2, Y: 1}, pathing.HexDirUpRight, pathing.GridCoord{X: 3, Y: 0}},
+	}
+	g := pathing.NewHexGrid(pathing.HexGridConfig{NumCols: 8, NumRows: 8})
+	for _, test := range tests {
+		if have := g.Move(test.from, test.dir); have != test.want {
+			t.Fatalf("%v.Move(%s):\nhave: %v\nwant: %v", test.from, test.dir, have, test.want)
+		}
+	}
+}
+
+func TestHexGridPos(t *testing.T) {
+	g := pathing.NewHexGrid(pathing.HexGridConfig{
+		NumCols:   10,
+		NumRows:   10,
+		HexWidth:  28,
+		HexHeight: 32,
+	})
+
+	tests := []struct {
+		x, y float64
+		want pathing.GridCoord
+	}{
+		{14, 16, pathing.GridCoord{X: 0, Y: 0}},
+		{28, 40, pathing.GridCoord{X: 0, Y: 1}},
+		{42, 16, pathing.GridCoord{X: 1, Y: 0}},
+		{27, 16, pathing.GridCoord{X: 0, Y: 0}},
+		{29, 16, pathing.GridCoord{X: 1, Y: 0}},
+		// The bounding box corners belong to the neighbor hexes.
+		{2, 2, pathing.GridCoord{X: -1, Y: -1}},
+		{2, 30, pathing.GridCoord{X: -1, Y: 1}},
+		{26, 30, pathing.GridCoord{X: 0, Y: 1}},
+	}
+	for _, test := range tests {
+		if have := g.PosToCoord(test.x, test.y); have != test.want {
+			t.Fatalf("PosToCoord(%v, %v):\nhave: %v\nwant: %v", test.x, test.y, have, test.want)
+		}
+	}
+
+	for y := 0; y < g.NumRows(); y++ {
+		for x := 0; x < g.NumCols(); x++ {
+			c := pathing.GridCoord{X: x, Y: y}
+			posX, posY := g.CoordToPos(c)
+			// All points near the hex center belong to the same hex.
+			for _, delta := range [][2]float64{{0, 0}, {-9, 0}, {9, 0}, {0, -11}, {0, 11}, {-7, -7}, {7, 7}} {
+				if have := g.PosToCoord(posX+delta[0], posY+delta[1]); have != c {
+					t.Fatalf("PosToCoord(%v, %v): have %v, want %v", posX+delta[0], posY+delta[1], have, c)
+				}
+			}
+			if hx, hy := g.HexToPos(pathing.OffsetToHex(c)); hx != posX || hy != posY {
+				t.Fatalf("%v: HexToPos and CoordToPos mismatch", c)
+			}
+			if h := g.PosToHex(posX, posY); h != pathing.OffsetToHex(c) {
+				t.Fatalf("%v: PosToHex mismatch: %v", c, h)
+			}
+		}
+	}
+}
+
+func TestHexGridPathfinding(t *testing.T) {
+	// The odd rows are shifted right:
+	//
+	//	. . . . . .
+	//	 . x x x x .
+	//	. . . . x .
+	//	 x x x . x .
+	//	. . . . . .
+	g := pathing.NewHexGrid(pathing.HexGridConfig{NumCols: 6, NumRows: 5})
+	for _, c := range []pathing.GridCoord{
+		{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1}, {X: 4, Y: 1},
+		{X: 4, Y: 2},
+		{X: 0, Y: 3}, {X: 1, Y: 3}, {X: 2, Y: 3}, {X: 4, Y: 3},
+	} {
+		g.SetCellTile(c, 1)
+	}
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	bfs := pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})
+	tests := []struct {
+		from    pathing.GridCoord
+		to      pathing.GridCoord
+		cost    int
+		partial bool
+	}{
+		{from: pathing.GridCoord{X: 0, Y: 0}, to: pathing.GridCoord{X: 0, Y: 0}, cost: 0},
+		{from: pathing.GridCoord{X: 0, Y: 0}, to: pathing.GridCoord{X: 5, Y: 0}, cost: 5},
+		{from: pathing.GridCoord{X: 1, Y: 2}, to: pathing.GridCoord{X: 0, Y: 4}, cost: 7},
+		{from: pathing.GridCoord{X: 3, Y: 2}, to: pathing.GridCoord{X: 5, Y: 2}, cost: 5},
+	}
+	for _, test := range tests {
+		results := map[string]pathing.BuildHexPathResult{
+			"astar": astar.BuildHexPath(g, test.from, test.to, l),
+			"bfs":   bfs.BuildHexPath(g, test.from, test.to, l),
+		}
+		for name, result := range results {
+			checkHexPath(t, g, l, test.from, result)
+			if result.Partial != test.partial {
+				t.Fatalf("%s: %v->%v: partial flag mismatch", name, test.from, test.to)
+			}
+			if result.Finish != test.to {
+				t.Fatalf("%s: %v->%v: finish mismatch: %v", name, test.from, test.to, result.Finish)
+			}
+			if name == "astar" && result.Cost != test.cost {
+				t.Fatalf("%s: %v->%v: cost mismatch: have %d, want %d (%s)", name, test.from, test.to, result.Cost, test.cost, result.Steps)
+			}
+		}
+	}
+
+	// Close both passages.
+	g.SetCellTile(pathing.GridCoord{X: 3, Y: 3}, 1)
+	g.SetCellTile(pathing.GridCoord{X: 5, Y: 3}, 1)
+	from := pathing.GridCoord{X: 0, Y: 0}
+	to := pathing.GridCoord{X: 0, Y: 4}
+	for _, result := range []pathing.BuildHexPathResult{astar.BuildHexPath(g, from, to, l), bfs.BuildHexPath(g, from, to, l)} {
+		checkHexPath(t, g, l, from, result)
+		if !result.Partial {
+			t.Fatalf("expected a partial result, got a path to %v", result.Finish)
+		}
+		if result.Finish.Y != 2 {
+			t.Fatalf("expected the partial path to end at the row 2, got %v", result.Finish)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		astar.BuildHexPath(g, from, to, l)
+		bfs.BuildHexPath(g, from, to, l)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocs, got %v", allocs)
+	}
+}
+
+func TestHexGridAStarOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 1, 1, 1, 1})
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	graphAStar := pathing.NewGraphAStar[pathing.GridCoord](pathing.GraphAStarConfig{})
+	var path []pathing.GridCoord
+	for i := 0; i < 100; i++ {
+		numCols := rng.Intn(30) + 2
+		numRows := rng.Intn(30) + 2
+		g := pathing.NewHexGrid(pathing.HexGridConfig{NumCols: uint(numCols), NumRows: uint(numRows)})
+		testRandomTiles(rng, g, numCols, numRows)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		if g.GetCellCost(from, l) == 0 || g.GetCellCost(to, l) == 0 {
+			continue
+		}
+
+		want := graphAStar.BuildPath(&hexGraph{grid: g, layer: l}, from, to, path[:0])
+		path = want.Path
+		if want.Partial || len(want.Path) > 40 {
+			continue
+		}
+		have := astar.BuildHexPath(g, from, to, l)
+		checkHexPath(t, g, l, from, have)
+		if have.Partial || have.Cost != want.Cost {
+			t.Fatalf("test%d: %v->%v: have cost=%d partial=%v, want cost=%d", i, from, to, have.Cost, have.Partial, want.Cost)
+		}
+	}
+}
+
+func checkHexPath(t *testing.T, g *pathing.HexGrid, l pathing.GridLayer, from pathing.GridCoord, result pathing.BuildHexPathResult) {
+	t.Helper()
+
+	pos := from
+	cost := 0
+	steps := result.Steps
+	steps.Rewind()
+	for steps.HasNext() {
+		pos = g.Move(pos, steps.Next())
+		c := g.GetCellCost(pos, l)
+		if c == 0 {
+			t.Fatalf("%v: the path %s goes through %v", from, result.Steps, pos)
+		}
+		cost += int(c)
+	}
+	if pos != result.Finish {
+		t.Fatalf("%v: the path %s ends at %v instead of %v", from, result.Steps, pos, result.Finish)
+	}
+	if cost < result.Cost {
+		t.Fatalf("%v: the path %s cost is %d, reported %d", from, result.Steps, cost, result.Cost)
+	}
+}
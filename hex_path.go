@@ -0,0 +1,102 @@
+package pathing
+
+import (
+	"strings"
+)
+
+// HexPath is like GridPath, but its steps are the hex directions.
+// It has a value semantics.
+// The hex steps are encoded like the GridPath diagonal steps,
+// so the path can be only 37 steps long.
+//
+// The path steps can be applied to either axial or offset coordinates:
+// see HexCoord.Move and HexGrid.Move.
+type HexPath struct {
+	steps GridPath
+}
+
+// BuildHexPathResult is a BuildHexPath() method return value.
+//
+// It's identical to the BuildPathResult, except for the path steps type.
+type BuildHexPathResult struct {
+	Steps HexPath
+
+	// Finish is an offset coordinate of the hex where the constructed path ends.
+	Finish GridCoord
+
+	// Cost is a path final movement cost.
+	// See BuildPathResult.Cost comment.
+	Cost int
+
+	// Whether this is a partial path result.
+	// See BuildPathResult.Partial comment.
+	Partial bool
+}
+
+// MakeHexPath construct a path from the given set of steps.
+func MakeHexPath(steps ...HexDirection) HexPath {
+	var result HexPath
+	for i := len(steps) - 1; i >= 0; i-- {
+		result.push(steps[i])
+	}
+	result.Rewind()
+	return result
+}
+
+// String returns a debug-print version of the path.
+// It's not intended to be used a fast path-to-string method.
+func (p HexPath) String() string {
+	parts := make([]string, 0, p.steps.Len())
+	p.Rewind()
+	for p.HasNext() {
+		parts = append(parts, p.Next().String())
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Len returns the path length.
+// It's not affected by the iterator state.
+func (p *HexPath) Len() int { return p.steps.Len() }
+
+// HasNext reports whether there are more steps inside this path.
+func (p *HexPath) HasNext() bool { return p.steps.HasNext() }
+
+// Rewind resets the iterator and allows you to traverse it again.
+func (p *HexPath) Rewind() { p.steps.Rewind() }
+
+// Peek returns the next path step without advancing the iterator.
+func (p *HexPath) Peek() HexDirection {
+	// The hex directions fit into the GridPath 3-bit step encoding.
+	d := p.steps.Peek()
+	if d == DirNone {
+		return HexDirNone
+	}
+	return HexDirection(d)
+}
+
+// Next returns the next path step and advances the iterator.
+func (p *HexPath) Next() HexDirection {
+	d := p.Peek()
+	p.steps.pos--
+	return d
+}
+
+func (p *HexPath) push(d HexDirection) {
+	p.steps.push(Direction(d))
+}
+
+func constructHexPath(from, to GridCoord, origin GridCoord, pathmap *coordMap) HexPath {
+	// See constructPath comment: the steps are pushed
+	// in the reversed order, which is what HexPath needs.
+	//
+	// The parity of the row is important for the offset coordinates,
+	// so the moves are done using the global coordinates.
+	var result HexPath
+	pos := to
+	for pos != from {
+		d, _ := pathmap.Get(pathmap.packCoord(pos.Sub(origin)))
+		result.push(HexDirection(d))
+		pos = OffsetToHex(pos).Move(HexDirection(d).Reversed()).Offset()
+	}
+	return result
+}
@@ -0,0 +1,671 @@
+package pathing
+
+import (
+	"math"
+)
+
+// HPAStar implements a hierarchical pathfinding A* (HPA*) algorithm.
+// You must use NewHPAStar() function to obtain an instance of this type.
+//
+// The grid is partitioned into square clusters.
+// The cells that connect the neighbouring clusters (entrances) form
+// an abstract graph; the edges inside a cluster are precomputed.
+// The path is found using that small abstract graph first and then
+// every abstract edge is refined into the actual steps using AStar.
+// That search never leaves the edge target cluster.
+//
+// This makes it possible to build the cross-map paths on big grids.
+// The paths are near-optimal: they're optimal in terms of the abstract graph,
+// but the entrances placement can make them a bit longer than necessary.
+//
+// The abstract graph is built for every GridLayer separately.
+// It happens automatically during the first BuildLongPath call with that layer.
+//
+// When the grid changes, use NotifyCellChanged + Update to
+// rebuild only the affected clusters.
+type HPAStar struct {
+	grid *Grid
+
+	clusterSize int
+	clusterCols int
+	clusterRows int
+
+	graphs []*hpaGraph
+
+	dirty         []bool
+	dirtyClusters []int
+
+	astar   *AStar
+	segment LongGridPath
+
+	frontier      *minheap[hpaFrontierItem]
+	localFrontier *minheap[int32]
+	localDists    []uint32
+	startDists    []uint32
+	goalDists     []uint32
+	waypoints     []GridCoord
+}
+
+type HPAStarConfig struct {
+	// ClusterSize is a number of cells on the cluster side.
+	// The bigger clusters make the abstract graph smaller,
+	// but it takes more time to build or update a single cluster.
+	//
+	// If left unset (0), a default value of 16 is used.
+	// The values higher than 32 are treated as 32.
+	ClusterSize int
+
+	// Diagonal enables the 8-directional movement mode.
+	// See AStarConfig.Diagonal comment.
+	//
+	// The cluster entrances are always connected by the axial steps.
+	Diagonal bool
+
+	// CornerCutting relaxes the diagonal movement rules.
+	// See AStarConfig.CornerCutting comment.
+	CornerCutting bool
+
+	// DiagonalCost is a diagonal step cost multiplier.
+	// See AStarConfig.DiagonalCost comment.
+	DiagonalCost float64
+}
+
+type hpaGraph struct {
+	layer    GridLayer
+	clusters []hpaCluster
+
+	// gen is used to tell the stale search state apart.
+	gen uint32
+}
+
+type hpaCluster struct {
+	nodes []hpaNode
+}
+
+type hpaNode struct {
+	coord GridCoord
+	edges []hpaEdge
+
+	// The abstract search state.
+	gen    uint32
+	cost   uint32
+	parent hpaNodeRef
+}
+
+type hpaEdge struct {
+	to   GridCoord
+	cost uint32
+
+	// The target node location.
+	// The index is just a hint: it becomes stale
+	// when the target cluster is rebuilt.
+	cluster int32
+	index   int32
+}
+
+type hpaNodeRef struct {
+	cluster int32
+	index   int32
+}
+
+type hpaFrontierItem struct {
+	ref  hpaNodeRef
+	cost uint32
+}
+
+// hpaStartRef is a virtual node that represents the search start.
+var hpaStartRef = hpaNodeRef{cluster: -1, index: -1}
+
+// hpaLongEntranceLen is a min number of cells for an entrance
+// that is represented by two nodes (one per each end) instead of one.
+const hpaLongEntranceLen = 6
+
+const hpaUnreachable = math.MaxUint32
+
+// NewHPAStar creates a ready-to-use HPAStar object for the given grid.
+//
+// The abstract graphs are bound to this grid, but the grid size is
+// not expected to change.
+func NewHPAStar(g *Grid, config HPAStarConfig) *HPAStar {
+	if config.ClusterSize == 0 {
+		config.ClusterSize = 16
+	}
+	if config.ClusterSize > 32 {
+		config.ClusterSize = 32
+	}
+	if config.ClusterSize < 2 {
+		config.ClusterSize = 2
+	}
+
+	clusterSize := config.ClusterSize
+	clusterCols := (int(g.numCols) + clusterSize - 1) / clusterSize
+	clusterRows := (int(g.numRows) + clusterSize - 1) / clusterSize
+
+	// This AStar is only used for the cluster-bounded searches,
+	// so its BuildPath working space is kept small.
+	astar := NewAStar(AStarConfig{
+		NumCols:       uint(clusterSize),
+		NumRows:       uint(clusterSize),
+		Diagonal:      config.Diagonal,
+		CornerCutting: config.CornerCutting,
+		DiagonalCost:  config.DiagonalCost,
+	})
+
+	hpa := &HPAStar{
+		grid:          g,
+		clusterSize:   clusterSize,
+		clusterCols:   clusterCols,
+		clusterRows:   clusterRows,
+		dirty:         make([]bool, clusterCols*clusterRows),
+		astar:         astar,
+		frontier:      newMinheap[hpaFrontierItem](64),
+		localFrontier: newMinheap[int32](64),
+		localDists:    make([]uint32, clusterSize*clusterSize),
+		startDists:    make([]uint32, clusterSize*clusterSize),
+		goalDists:     make([]uint32, clusterSize*clusterSize),
+	}
+
+	return hpa
+}
+
+// NotifyCellChanged tells the pathfinder that the cell tile tag
+// or its blocked status was changed.
+// The affected clusters are rebuilt during the next Update call.
+func (hpa *HPAStar) NotifyCellChanged(c GridCoord) {
+	g := hpa.grid
+	if uint(c.X) >= g.numCols || uint(c.Y) >= g.numRows {
+		return
+	}
+	hpa.markDirty(c)
+	// The border cells also affect the neighbouring cluster entrances.
+	for _, offset := range neighborOffsets[:4] {
+		hpa.markDirty(c.Add(offset))
+	}
+}
+
+// Update rebuilds the clusters affected by the grid changes.
+// See NotifyCellChanged.
+//
+// All abstract graphs (one per GridLayer) are updated.
+func (hpa *HPAStar) Update() {
+	for _, graph := range hpa.graphs {
+		for _, clusterIndex := range hpa.dirtyClusters {
+			hpa.buildCluster(graph, clusterIndex)
+		}
+	}
+	for _, clusterIndex := range hpa.dirtyClusters {
+		hpa.dirty[clusterIndex] = false
+	}
+	hpa.dirtyClusters = hpa.dirtyClusters[:0]
+}
+
+// BuildLongPath attempts to find a path between the two coordinates.
+// Unlike other pathfinders, HPAStar is bound to the grid it was created for.
+// See AStar.BuildLongPath comment to learn more about the arguments.
+//
+// If the destination is unreachable, a path to the closest
+// entrance is returned and the result is marked as partial.
+func (hpa *HPAStar) BuildLongPath(from, to GridCoord, l GridLayer, dst *LongGridPath) BuildLongPathResult {
+	var result BuildLongPathResult
+	dst.Reset()
+	if from == to {
+		result.Finish = to
+		return result
+	}
+
+	g := hpa.grid
+	if uint(from.X) >= g.numCols || uint(from.Y) >= g.numRows || uint(to.X) >= g.numCols || uint(to.Y) >= g.numRows {
+		result.Finish = from
+		result.Partial = true
+		return result
+	}
+
+	graph := hpa.getGraph(l)
+	finish, found := hpa.search(graph, from, to)
+	result.Finish = hpa.refine(graph, from, finish, dst)
+	result.Cost = hpa.pathCost(from, dst, l)
+	result.Partial = !found || result.Finish != to
+
+	return result
+}
+
+func (hpa *HPAStar) getGraph(l GridLayer) *hpaGraph {
+	for _, graph := range hpa.graphs {
+		if graph.layer == l {
+			return graph
+		}
+	}
+
+	graph := &hpaGraph{
+		layer:    l,
+		clusters: make([]hpaCluster, hpa.clusterCols*hpa.clusterRows),
+	}
+	for i := range graph.clusters {
+		hpa.buildCluster(graph, i)
+	}
+	hpa.graphs = append(hpa.graphs, graph)
+	return graph
+}
+
+// search runs the A* algorithm over the abstract graph.
+//
+// It returns the coord where the abstract path ends and whether it's the goal.
+// The path waypoints are stored in the reversed order.
+func (hpa *HPAStar) search(graph *hpaGraph, from, to GridCoord) (GridCoord, bool) {
+	graph.gen++
+	frontier := hpa.frontier
+	frontier.Reset()
+
+	startCluster := hpa.clusterIndex(from)
+	goalCluster := hpa.clusterIndex(to)
+	hpa.clusterDijkstra(graph.layer, startCluster, from, false, hpa.startDists)
+	hpa.clusterDijkstra(graph.layer, goalCluster, to, true, hpa.goalDists)
+
+	goalCost := uint32(hpaUnreachable)
+	goalParent := hpaStartRef
+	if startCluster == goalCluster {
+		goalCost = hpa.startDists[hpa.localIndex(startCluster, to)]
+	}
+
+	for i := range graph.clusters[startCluster].nodes {
+		n := &graph.clusters[startCluster].nodes[i]
+		d := hpa.startDists[hpa.localIndex(startCluster, n.coord)]
+		if d == hpaUnreachable {
+			continue
+		}
+		ref := hpaNodeRef{cluster: int32(startCluster), index: int32(i)}
+		hpa.relax(graph, n, ref, hpaStartRef, d, to)
+	}
+
+	// The start cell can be impassable, so it's not always an entrance.
+	// The neighbouring clusters are checked explicitly.
+	g := hpa.grid
+	astar := hpa.astar
+	for dir, offset := range neighborOffsets[:astar.numNeighbors] {
+		next := from.Add(offset)
+		cx := uint(next.X)
+		cy := uint(next.Y)
+		if cx >= g.numCols || cy >= g.numRows {
+			continue
+		}
+		clusterIndex := hpa.clusterIndex(next)
+		if clusterIndex == startCluster {
+			continue
+		}
+		nextCellCost := uint32(g.getCellCost(cx, cy, graph.layer))
+		if nextCellCost == 0 {
+			continue
+		}
+		stepCost := astar.axialCost
+		if dir >= int(DirDownRight) {
+			if !g.canMoveDiagonally(uint(from.X), uint(from.Y), offset, graph.layer, astar.cornerCutting) {
+				continue
+			}
+			stepCost = astar.diagonalCost
+		}
+		firstStepCost := nextCellCost * stepCost
+		hpa.clusterDijkstra(graph.layer, clusterIndex, next, false, hpa.localDists)
+		for i := range graph.clusters[clusterIndex].nodes {
+			n := &graph.clusters[clusterIndex].nodes[i]
+			d := hpa.localDists[hpa.localIndex(clusterIndex, n.coord)]
+			if d == hpaUnreachable {
+				continue
+			}
+			ref := hpaNodeRef{cluster: int32(clusterIndex), index: int32(i)}
+			hpa.relax(graph, n, ref, hpaStartRef, firstStepCost+d, to)
+		}
+		if clusterIndex == goalCluster {
+			d := hpa.localDists[hpa.localIndex(clusterIndex, to)]
+			if d != hpaUnreachable && firstStepCost+d < goalCost {
+				goalCost = firstStepCost + d
+			}
+		}
+	}
+
+	shortestDist := from.Dist(to)
+	fallback := hpaStartRef
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+		n := &graph.clusters[current.ref.cluster].nodes[current.ref.index]
+		if current.cost > n.cost {
+			continue // Stale entry
+		}
+		if goalCost != hpaUnreachable && current.cost+uint32(astar.heuristic(n.coord, to)) >= goalCost {
+			break
+		}
+
+		if dist := n.coord.Dist(to); dist < shortestDist {
+			shortestDist = dist
+			fallback = current.ref
+		}
+
+		if int(current.ref.cluster) == goalCluster {
+			d := hpa.goalDists[hpa.localIndex(goalCluster, n.coord)]
+			if d != hpaUnreachable && current.cost+d < goalCost {
+				goalCost = current.cost + d
+				goalParent = current.ref
+			}
+		}
+
+		for i := range n.edges {
+			e := &n.edges[i]
+			cluster := &graph.clusters[e.cluster]
+			if uint(e.index) >= uint(len(cluster.nodes)) || cluster.nodes[e.index].coord != e.to {
+				e.index = int32(cluster.find(e.to))
+				if e.index == -1 {
+					// The target entrance was removed when its cluster
+					// was rebuilt; this edge is stale.
+					continue
+				}
+			}
+			ref := hpaNodeRef{cluster: e.cluster, index: e.index}
+			hpa.relax(graph, &cluster.nodes[e.index], ref, current.ref, current.cost+e.cost, to)
+		}
+	}
+
+	waypoints := hpa.waypoints[:0]
+	last := fallback
+	found := goalCost != hpaUnreachable
+	if found {
+		waypoints = append(waypoints, to)
+		last = goalParent
+	}
+	for ref := last; ref != hpaStartRef; {
+		n := &graph.clusters[ref.cluster].nodes[ref.index]
+		waypoints = append(waypoints, n.coord)
+		ref = n.parent
+	}
+	hpa.waypoints = waypoints
+
+	if found {
+		return to, true
+	}
+	if fallback == hpaStartRef {
+		return from, false
+	}
+	return graph.clusters[fallback.cluster].nodes[fallback.index].coord, false
+}
+
+func (hpa *HPAStar) relax(graph *hpaGraph, n *hpaNode, ref, parent hpaNodeRef, cost uint32, goal GridCoord) {
+	if n.gen == graph.gen && cost >= n.cost {
+		return
+	}
+	n.gen = graph.gen
+	n.cost = cost
+	n.parent = parent
+	priority := cost + uint32(hpa.astar.heuristic(n.coord, goal))
+	hpa.frontier.Push(int(priority), hpaFrontierItem{ref: ref, cost: cost})
+}
+
+// refine turns the abstract path waypoints into the actual path steps.
+// It returns the coord where the path ends.
+func (hpa *HPAStar) refine(graph *hpaGraph, from, to GridCoord, dst *LongGridPath) GridCoord {
+	pos := from
+	for i := len(hpa.waypoints) - 1; i >= 0; i-- {
+		next := hpa.waypoints[i]
+		if pos.Dist(next) == 1 {
+			// A direct step is always the cheapest way
+			// to get into the adjacent cell.
+			dst.Push(directionFromDelta(next.X-pos.X, next.Y-pos.Y))
+			pos = next
+			continue
+		}
+		var ok bool
+		pos, ok = hpa.refineEdge(graph.layer, pos, next, dst)
+		if !ok {
+			break
+		}
+	}
+	return pos
+}
+
+// refineEdge pushes the steps that lead from pos to the next waypoint.
+// It returns the coord where these steps end and whether it's the waypoint.
+//
+// Every abstract edge is contained inside the target cluster,
+// so the AStar search is limited to that cluster.
+// The only exception is the path start: it can be outside of that cluster,
+// but then it's adjacent to it (see the search method).
+// The search area is extended to include the start in this case.
+func (hpa *HPAStar) refineEdge(l GridLayer, pos, next GridCoord, dst *LongGridPath) (GridCoord, bool) {
+	x0, y0, x1, y1 := hpa.clusterRect(hpa.clusterIndex(next))
+	if pos.X < x0 {
+		x0 = pos.X
+	} else if pos.X >= x1 {
+		x1 = pos.X + 1
+	}
+	if pos.Y < y0 {
+		y0 = pos.Y
+	} else if pos.Y >= y1 {
+		y1 = pos.Y + 1
+	}
+
+	result := hpa.astar.buildAreaPath(hpa.grid, pos, next, l, x0, y0, x1, y1, &hpa.segment)
+	dst.bytes = append(dst.bytes, hpa.segment.bytes...)
+	return result.Finish, !result.Partial
+}
+
+// pathCost computes the path cost the same way AStar does.
+func (hpa *HPAStar) pathCost(from GridCoord, p *LongGridPath, l GridLayer) int {
+	astar := hpa.astar
+	cost := uint32(0)
+	pos := from
+	for _, b := range p.bytes {
+		d := Direction(b)
+		pos = pos.Move(d)
+		stepCost := astar.axialCost
+		if d.IsDiagonal() {
+			stepCost = astar.diagonalCost
+		}
+		cost += uint32(hpa.grid.GetCellCost(pos, l)) * stepCost
+	}
+	return astar.unscaleCost(cost)
+}
+
+func (hpa *HPAStar) markDirty(c GridCoord) {
+	g := hpa.grid
+	if uint(c.X) >= g.numCols || uint(c.Y) >= g.numRows {
+		return
+	}
+	i := hpa.clusterIndex(c)
+	if !hpa.dirty[i] {
+		hpa.dirty[i] = true
+		hpa.dirtyClusters = append(hpa.dirtyClusters, i)
+	}
+}
+
+// buildCluster finds the cluster entrances and connects them.
+// The neighbouring clusters find the same entrances on the shared borders,
+// so the graph stays consistent when only one of them is rebuilt.
+func (hpa *HPAStar) buildCluster(graph *hpaGraph, clusterIndex int) {
+	cluster := &graph.clusters[clusterIndex]
+	cluster.nodes = cluster.nodes[:0]
+
+	x0, y0, x1, y1 := hpa.clusterRect(clusterIndex)
+	g := hpa.grid
+	if x1 < int(g.numCols) {
+		hpa.addEntrances(graph, cluster, GridCoord{X: x1 - 1, Y: y0}, GridCoord{Y: 1}, y1-y0, GridCoord{X: 1})
+	}
+	if y1 < int(g.numRows) {
+		hpa.addEntrances(graph, cluster, GridCoord{X: x0, Y: y1 - 1}, GridCoord{X: 1}, x1-x0, GridCoord{Y: 1})
+	}
+	if x0 > 0 {
+		hpa.addEntrances(graph, cluster, GridCoord{X: x0, Y: y0}, GridCoord{Y: 1}, y1-y0, GridCoord{X: -1})
+	}
+	if y0 > 0 {
+		hpa.addEntrances(graph, cluster, GridCoord{X: x0, Y: y0}, GridCoord{X: 1}, x1-x0, GridCoord{Y: -1})
+	}
+
+	// Connect the entrances inside the cluster.
+	for i := range cluster.nodes {
+		n := &cluster.nodes[i]
+		hpa.clusterDijkstra(graph.layer, clusterIndex, n.coord, false, hpa.localDists)
+		for j := range cluster.nodes {
+			if i == j {
+				continue
+			}
+			other := cluster.nodes[j].coord
+			d := hpa.localDists[hpa.localIndex(clusterIndex, other)]
+			if d == hpaUnreachable {
+				continue
+			}
+			n.edges = append(n.edges, hpaEdge{
+				to:      other,
+				cost:    d,
+				cluster: int32(clusterIndex),
+				index:   int32(j),
+			})
+		}
+	}
+}
+
+// addEntrances scans the cluster border that starts at pos and goes along the step.
+// The outside offset points to the neighbouring cluster cells.
+func (hpa *HPAStar) addEntrances(graph *hpaGraph, cluster *hpaCluster, pos, step GridCoord, length int, outside GridCoord) {
+	l := graph.layer
+	g := hpa.grid
+	runStart := -1
+	for i := 0; i <= length; i++ {
+		open := false
+		if i < length {
+			inner := GridCoord{X: pos.X + step.X*i, Y: pos.Y + step.Y*i}
+			open = g.GetCellCost(inner, l) != 0 && g.GetCellCost(inner.Add(outside), l) != 0
+		}
+		if open {
+			if runStart == -1 {
+				runStart = i
+			}
+			continue
+		}
+		if runStart == -1 {
+			continue
+		}
+		runEnd := i - 1
+		if runEnd-runStart+1 < hpaLongEntranceLen {
+			hpa.addEntrance(cluster, l, pos.Add(GridCoord{X: step.X * ((runStart + runEnd) / 2), Y: step.Y * ((runStart + runEnd) / 2)}), outside)
+		} else {
+			hpa.addEntrance(cluster, l, pos.Add(GridCoord{X: step.X * runStart, Y: step.Y * runStart}), outside)
+			hpa.addEntrance(cluster, l, pos.Add(GridCoord{X: step.X * runEnd, Y: step.Y * runEnd}), outside)
+		}
+		runStart = -1
+	}
+}
+
+func (hpa *HPAStar) addEntrance(cluster *hpaCluster, l GridLayer, inner, outside GridCoord) {
+	i := cluster.find(inner)
+	if i == -1 {
+		i = len(cluster.nodes)
+		if i < cap(cluster.nodes) {
+			// Re-use the edges slice memory.
+			cluster.nodes = cluster.nodes[:i+1]
+			edges := cluster.nodes[i].edges[:0]
+			cluster.nodes[i] = hpaNode{coord: inner, edges: edges}
+		} else {
+			cluster.nodes = append(cluster.nodes, hpaNode{coord: inner})
+		}
+	}
+	outer := inner.Add(outside)
+	cost := uint32(hpa.grid.GetCellCost(outer, l)) * hpa.astar.axialCost
+	cluster.nodes[i].edges = append(cluster.nodes[i].edges, hpaEdge{
+		to:      outer,
+		cost:    cost,
+		cluster: int32(hpa.clusterIndex(outer)),
+		index:   -1,
+	})
+}
+
+// clusterDijkstra computes the path costs between the src and other cluster cells.
+// The search never leaves the cluster.
+//
+// If reversed is true, the costs of getting from other cells to src are computed.
+func (hpa *HPAStar) clusterDijkstra(l GridLayer, clusterIndex int, src GridCoord, reversed bool, dists []uint32) {
+	g := hpa.grid
+	astar := hpa.astar
+	wide := g.wideTags
+	x0, y0, x1, y1 := hpa.clusterRect(clusterIndex)
+
+	for i := range dists {
+		dists[i] = hpaUnreachable
+	}
+	frontier := hpa.localFrontier
+	frontier.Reset()
+	srcIndex := hpa.localIndex(clusterIndex, src)
+	dists[srcIndex] = 0
+	frontier.Push(0, int32(srcIndex))
+
+	for !frontier.IsEmpty() {
+		i := int(frontier.Pop())
+		current := GridCoord{X: x0 + i%hpa.clusterSize, Y: y0 + i/hpa.clusterSize}
+		currentDist := dists[i]
+
+		// In the reversed mode, it's the cost of entering the current cell.
+		enterCost := uint32(0)
+		if reversed {
+			enterCost = uint32(g.getCellCost(uint(current.X), uint(current.Y), l))
+			if enterCost == 0 {
+				continue
+			}
+		}
+
+		for dir, offset := range neighborOffsets[:astar.numNeighbors] {
+			next := current.Add(offset)
+			if next.X < x0 || next.X >= x1 || next.Y < y0 || next.Y >= y1 {
+				continue
+			}
+			nextCellCost := uint32(g.getTagCost(uint(next.X), uint(next.Y), l))
+			if nextCellCost == 0 || wide && g.isBlockedCell(uint(next.X), uint(next.Y)) {
+				continue
+			}
+			stepCost := astar.axialCost
+			if dir >= int(DirDownRight) {
+				if !g.canMoveDiagonally(uint(current.X), uint(current.Y), offset, l, astar.cornerCutting) {
+					continue
+				}
+				stepCost = astar.diagonalCost
+			}
+			newDist := currentDist + nextCellCost*stepCost
+			if reversed {
+				newDist = currentDist + enterCost*stepCost
+			}
+			j := hpa.localIndex(clusterIndex, next)
+			if newDist >= dists[j] {
+				continue
+			}
+			dists[j] = newDist
+			frontier.Push(int(newDist), int32(j))
+		}
+	}
+}
+
+func (hpa *HPAStar) clusterIndex(c GridCoord) int {
+	return (c.Y/hpa.clusterSize)*hpa.clusterCols + c.X/hpa.clusterSize
+}
+
+func (hpa *HPAStar) clusterRect(clusterIndex int) (x0, y0, x1, y1 int) {
+	x0 = (clusterIndex % hpa.clusterCols) * hpa.clusterSize
+	y0 = (clusterIndex / hpa.clusterCols) * hpa.clusterSize
+	x1 = x0 + hpa.clusterSize
+	if x1 > int(hpa.grid.numCols) {
+		x1 = int(hpa.grid.numCols)
+	}
+	y1 = y0 + hpa.clusterSize
+	if y1 > int(hpa.grid.numRows) {
+		y1 = int(hpa.grid.numRows)
+	}
+	return x0, y0, x1, y1
+}
+
+func (hpa *HPAStar) localIndex(clusterIndex int, c GridCoord) int {
+	x0 := (clusterIndex % hpa.clusterCols) * hpa.clusterSize
+	y0 := (clusterIndex / hpa.clusterCols) * hpa.clusterSize
+	return (c.Y-y0)*hpa.clusterSize + (c.X - x0)
+}
+
+func (cluster *hpaCluster) find(c GridCoord) int {
+	for i := range cluster.nodes {
+		if cluster.nodes[i].coord == c {
+			return i
+		}
+	}
+	return -1
+}
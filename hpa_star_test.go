@@ -0,0 +1,288 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkHPAStar(b *testing.B) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 1024, WorldHeight: 32 * 1024})
+	rng := rand.New(rand.NewSource(1))
+	for y := 0; y < g.NumRows(); y++ {
+		for x := 0; x < g.NumCols(); x++ {
+			if rng.Float64() < 0.2 {
+				g.SetCellTile(pathing.GridCoord{X: x, Y: y}, 1)
+			}
+		}
+	}
+	from := pathing.GridCoord{X: 1, Y: 1}
+	to := pathing.GridCoord{X: 1020, Y: 1020}
+	g.SetCellTile(from, 0)
+	g.SetCellTile(to, 0)
+
+	hpa := pathing.NewHPAStar(g, pathing.HPAStarConfig{})
+	var path pathing.LongGridPath
+	hpa.BuildLongPath(from, to, l, &path)
+
+	b.Run("build_path", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			hpa.BuildLongPath(from, to, l, &path)
+		}
+	})
+
+	b.Run("update", func(b *testing.B) {
+		cell := pathing.GridCoord{X: 500, Y: 500}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.SetCellIsBlocked(cell, i%2 == 0)
+			hpa.NotifyCellChanged(cell)
+			hpa.Update()
+		}
+	})
+}
+
+func TestHPAStar(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"A.......x.......x.......",
+		"........x.......x.......",
+		"........x.......x.......",
+		"........x...............",
+		"........x.......x.......",
+		"........x.......x.......",
+		"................x.......",
+		"........x.......x......B",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	hpa := pathing.NewHPAStar(g, pathing.HPAStarConfig{ClusterSize: 4})
+
+	var path pathing.LongGridPath
+	result := hpa.BuildLongPath(parseResult.start, parseResult.dest, l, &path)
+	if result.Partial || result.Finish != parseResult.dest {
+		t.Fatalf("expected a full path, got %v (partial=%v)", result.Finish, result.Partial)
+	}
+	if result.Cost != 36 {
+		t.Fatalf("cost mismatch:\nhave: %v\nwant: %v", result.Cost, 36)
+	}
+	checkLongPath(t, g, l, parseResult.start, result, &path)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		hpa.BuildLongPath(parseResult.start, parseResult.dest, l, &path)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocations, got %v", allocs)
+	}
+
+	// Close the second wall gap.
+	gap := pathing.GridCoord{X: 16, Y: 3}
+	g.SetCellTile(gap, 1)
+	hpa.NotifyCellChanged(gap)
+	hpa.Update()
+	result = hpa.BuildLongPath(parseResult.start, parseResult.dest, l, &path)
+	if !result.Partial {
+		t.Fatalf("expected a partial result")
+	}
+	if result.Finish.X >= 16 {
+		t.Fatalf("expected the path to end before the wall, got %v", result.Finish)
+	}
+	checkLongPath(t, g, l, parseResult.start, result, &path)
+}
+
+func TestHPAStarStaleEdge(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"A.......",
+		"........",
+		"........",
+		".......B",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	hpa := pathing.NewHPAStar(g, pathing.HPAStarConfig{ClusterSize: 4})
+
+	var path pathing.LongGridPath
+	hpa.BuildLongPath(parseResult.start, parseResult.dest, l, &path)
+
+	// Remove the right cluster entrance, but only rebuild that cluster.
+	// The left cluster keeps an edge to the removed entrance.
+	g.SetCellTile(pathing.GridCoord{X: 4, Y: 1}, 1)
+	hpa.NotifyCellChanged(pathing.GridCoord{X: 6, Y: 1})
+	hpa.Update()
+
+	result := hpa.BuildLongPath(parseResult.start, parseResult.dest, l, &path)
+	checkLongPath(t, g, l, parseResult.start, result, &path)
+}
+
+func TestHPAStarMatchesAStar(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	configs := []struct {
+		astar pathing.AStarConfig
+		hpa   pathing.HPAStarConfig
+	}{
+		{hpa: pathing.HPAStarConfig{ClusterSize: 4}},
+		{hpa: pathing.HPAStarConfig{ClusterSize: 7}},
+		{
+			astar: pathing.AStarConfig{Diagonal: true},
+			hpa:   pathing.HPAStarConfig{ClusterSize: 5, Diagonal: true},
+		},
+		{
+			astar: pathing.AStarConfig{Diagonal: true, CornerCutting: true},
+			hpa:   pathing.HPAStarConfig{ClusterSize: 8, Diagonal: true, CornerCutting: true},
+		},
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, config := range configs {
+		astar := pathing.NewAStar(config.astar)
+		var want pathing.LongGridPath
+		var have pathing.LongGridPath
+		for i := 0; i < 100; i++ {
+			g, numCols, numRows := testRandomGrid(rng, 60)
+			hpa := pathing.NewHPAStar(g, config.hpa)
+			for j := 0; j < 10; j++ {
+				from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				wantResult := astar.BuildLongPath(g, from, to, l, &want)
+				haveResult := hpa.BuildLongPath(from, to, l, &have)
+				if haveResult.Partial != wantResult.Partial {
+					t.Fatalf("test%d: %v=>%v partial flag mismatch:\nhave: %v\nwant: %v", i, from, to, haveResult.Partial, wantResult.Partial)
+				}
+				checkLongPath(t, g, l, from, haveResult, &have)
+				if haveResult.Partial {
+					continue
+				}
+				if haveResult.Cost < wantResult.Cost {
+					t.Fatalf("test%d: %v=>%v the cost is better than optimal:\nhave: %v\nwant: %v", i, from, to, haveResult.Cost, wantResult.Cost)
+				}
+			}
+		}
+	}
+}
+
+func TestHPAStarMaze(t *testing.T) {
+	// The maze corridors are long, so the abstract edges can't be
+	// refined by a pathfinder with a limited search area.
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 0, 0, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	var want pathing.LongGridPath
+	var have pathing.LongGridPath
+	for _, clusterSize := range []int{16, 32} {
+		for i := 0; i < 10; i++ {
+			g := hpaMazeGrid(rng, 65, 65)
+			hpa := pathing.NewHPAStar(g, pathing.HPAStarConfig{ClusterSize: clusterSize})
+			for j := 0; j < 10; j++ {
+				// The odd cells are never walls.
+				from := pathing.GridCoord{X: rng.Intn(32)*2 + 1, Y: rng.Intn(32)*2 + 1}
+				to := pathing.GridCoord{X: rng.Intn(32)*2 + 1, Y: rng.Intn(32)*2 + 1}
+				wantResult := astar.BuildLongPath(g, from, to, l, &want)
+				haveResult := hpa.BuildLongPath(from, to, l, &have)
+				if haveResult.Partial || wantResult.Partial {
+					t.Fatalf("cluster%d: test%d: %v=>%v unexpected partial result:\nhave: %+v\nwant: %+v", clusterSize, i, from, to, haveResult, wantResult)
+				}
+				checkLongPath(t, g, l, from, haveResult, &have)
+				// There is only one path between any two maze cells.
+				if haveResult.Cost != wantResult.Cost {
+					t.Fatalf("cluster%d: test%d: %v=>%v cost mismatch:\nhave: %v\nwant: %v", clusterSize, i, from, to, haveResult.Cost, wantResult.Cost)
+				}
+			}
+		}
+	}
+}
+
+func TestHPAStarUpdate(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, config := range []pathing.HPAStarConfig{{ClusterSize: 4}, {ClusterSize: 6, Diagonal: true}} {
+		var want pathing.LongGridPath
+		var have pathing.LongGridPath
+		for i := 0; i < 100; i++ {
+			g, numCols, numRows := testRandomGrid(rng, 40)
+			hpa := pathing.NewHPAStar(g, config)
+			from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			hpa.BuildLongPath(from, to, l, &have)
+
+			for round := 0; round < 5; round++ {
+				for j := 0; j < 5; j++ {
+					c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+					if rng.Intn(2) == 0 {
+						g.SetCellIsBlocked(c, !g.GetCellIsBlocked(c))
+					} else {
+						g.SetCellTile(c, uint8(rng.Intn(4)))
+					}
+					hpa.NotifyCellChanged(c)
+				}
+				hpa.Update()
+
+				fresh := pathing.NewHPAStar(g, config)
+				wantResult := fresh.BuildLongPath(from, to, l, &want)
+				haveResult := hpa.BuildLongPath(from, to, l, &have)
+				if haveResult != wantResult {
+					t.Fatalf("test%d: round%d: %v=>%v result mismatch:\nhave: %+v\nwant: %+v", i, round, from, to, haveResult, wantResult)
+				}
+				if have.String() != want.String() {
+					t.Fatalf("test%d: round%d: %v=>%v path mismatch:\nhave: %v\nwant: %v", i, round, from, to, have, want)
+				}
+			}
+		}
+	}
+}
+
+// hpaMazeGrid creates a perfect maze using a randomized DFS.
+// The odd cells are the maze rooms and the walls have a tile tag of 1.
+func hpaMazeGrid(rng *rand.Rand, numCols, numRows int) *pathing.Grid {
+	g := pathing.NewGrid(pathing.GridConfig{
+		WorldWidth:  uint(numCols) * 32,
+		WorldHeight: uint(numRows) * 32,
+		DefaultTile: 1,
+	})
+	start := pathing.GridCoord{X: 1, Y: 1}
+	g.SetCellTile(start, 0)
+	stack := []pathing.GridCoord{start}
+	var options []pathing.GridCoord
+	for len(stack) != 0 {
+		current := stack[len(stack)-1]
+		options = options[:0]
+		for _, offset := range [4]pathing.GridCoord{{X: 2}, {Y: 2}, {X: -2}, {Y: -2}} {
+			next := current.Add(offset)
+			if next.X <= 0 || next.Y <= 0 || next.X >= numCols-1 || next.Y >= numRows-1 {
+				continue
+			}
+			if g.GetCellTile(next) == 0 {
+				continue
+			}
+			options = append(options, next)
+		}
+		if len(options) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		next := options[rng.Intn(len(options))]
+		g.SetCellTile(pathing.GridCoord{X: (current.X + next.X) / 2, Y: (current.Y + next.Y) / 2}, 0)
+		g.SetCellTile(next, 0)
+		stack = append(stack, next)
+	}
+	return g
+}
+
+func checkLongPath(t *testing.T, g *pathing.Grid, l pathing.GridLayer, from pathing.GridCoord, result pathing.BuildLongPathResult, path *pathing.LongGridPath) {
+	t.Helper()
+
+	pos := from
+	path.Rewind()
+	for path.HasNext() {
+		pos = pos.Move(path.Next())
+		if g.GetCellCost(pos, l) == 0 {
+			t.Fatalf("%v: the path goes through a blocked %v cell", from, pos)
+		}
+	}
+	path.Rewind()
+	if pos != result.Finish {
+		t.Fatalf("%v: the path ends at %v instead of %v", from, pos, result.Finish)
+	}
+}
@@ -0,0 +1,472 @@
+package pathing
+
+// JPS implements a jump point search pathfinding algorithm.
+// You must use NewJPS() function to obtain an instance of this type.
+//
+// JPS is an A* optimization for uniform-cost grids.
+// Instead of expanding every neighbor, it "jumps" over the cells
+// that can't be a part of a better path, so only a few nodes
+// are added to the open set.
+// This makes it much faster than AStar on big open maps.
+//
+// JPS always works in the 8-directional movement mode (see AStarConfig.Diagonal)
+// with a default diagonal cost multiplier and no corner cutting.
+// The paths it builds are as optimal as the ones built by
+// the AStar in the same mode.
+//
+// JPS treats 0 as "blocked" and any other value as "passable".
+// If you need a cost-based pathfinding, use AStar instead.
+//
+// Once created, you should re-use it to build paths.
+// Do not throw the instance away after building the path once.
+type JPS struct {
+	frontier *minheap[astarCoord]
+	costmap  *coordMap
+	pathmap  *coordMap
+
+	table *JumpTable
+
+	// These fields are only valid during the BuildPath call.
+	grid       *Grid
+	layer      GridLayer
+	wide       bool
+	origin     GridCoord
+	localGoal  GridCoord
+	windowCols uint
+	windowRows uint
+}
+
+type JPSConfig struct {
+	// NumCols and NumRows are size hints for the JPS constructor.
+	// Grid.NumCols() and Grid.NumRows() methods will come in handy to initialize these.
+	// If you keep them at 0, the max amount of the working space will be allocated.
+	// It's like a size hint: the constructor may allocate a smaller working area
+	// if the grids you're going operate on are small.
+	NumCols uint
+	NumRows uint
+
+	// JumpTable enables the JPS+ mode.
+	// When BuildPath is called with the same Grid and GridLayer
+	// that were used to create this table, the precomputed jump
+	// distances are used instead of the cell scanning.
+	// For any other Grid and GridLayer combination, a normal JPS is used.
+	//
+	// It's the caller's responsibility to keep the table up-to-date.
+	// See JumpTable.Rebuild.
+	JumpTable *JumpTable
+}
+
+// NewJPS creates a ready-to-use JPS object.
+func NewJPS(config JPSConfig) *JPS {
+	if config.NumCols == 0 {
+		config.NumCols = gridMapSide
+	}
+	if config.NumRows == 0 {
+		config.NumRows = gridMapSide
+	}
+
+	coordMapCols := gridMapSide
+	if int(config.NumCols) < coordMapCols {
+		coordMapCols = int(config.NumCols)
+	}
+	coordMapRows := gridMapSide
+	if int(config.NumRows) < coordMapRows {
+		coordMapRows = int(config.NumRows)
+	}
+
+	jps := &JPS{
+		frontier: newMinheap[astarCoord](32),
+		pathmap:  newCoordMap(coordMapCols, coordMapRows),
+		costmap:  newCoordMap(coordMapCols, coordMapRows),
+		table:    config.JumpTable,
+
+		windowCols: uint(coordMapCols),
+		windowRows: uint(coordMapRows),
+	}
+
+	return jps
+}
+
+// BuildPath attempts to find a path between the two coordinates.
+// It will use a provided Grid in combination with a GridLayer.
+// The Grid is expected to store the tile tags and the GridLayer is
+// used to interpret these tags.
+//
+// The result Cost is computed as if every passable cell had a cost of 1.
+func (jps *JPS) BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildPathResult {
+	var result BuildPathResult
+	if from == to {
+		result.Finish = to
+		return result
+	}
+
+	origin := findPathOrigin(from)
+
+	localStart := from.Sub(origin)
+	localGoal := to.Sub(origin)
+
+	jps.grid = g
+	jps.layer = l
+	jps.wide = g.wideTags
+	jps.origin = origin
+	jps.localGoal = localGoal
+
+	useTable := jps.table != nil && jps.table.grid == g && jps.table.layer == l
+
+	frontier := jps.frontier
+	frontier.Reset()
+
+	pathmap := jps.pathmap
+	pathmap.Reset()
+
+	costmap := jps.costmap
+	costmap.Reset()
+
+	frontier.Push(0, astarCoord{Coord: localStart})
+	startKey := pathmap.packCoord(localStart)
+	pathmap.Set(startKey, uint32(startKey))
+	costmap.Set(startKey, 0)
+
+	shortestDist := 0xffffffff
+	var fallbackCoord GridCoord
+	var fallbackCost int32
+	foundPath := false
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+
+		if current.Coord == localGoal {
+			result.Steps = constructJumpPath(localStart, localGoal, pathmap)
+			result.Finish = to
+			result.Cost = int((current.Cost + astarCostScale/2) / astarCostScale)
+			foundPath = true
+			break
+		}
+
+		k := costmap.packCoord(current.Coord)
+		currentCost, _ := costmap.Get(k)
+		if uint32(current.Cost) > currentCost {
+			// A stale frontier entry, this node was already
+			// reached via a cheaper path.
+			continue
+		}
+
+		dist := localGoal.Dist(current.Coord)
+		if dist < shortestDist {
+			shortestDist = dist
+			fallbackCoord = current.Coord
+			fallbackCost = current.Cost
+		}
+
+		// The jumps can't be longer than this, otherwise
+		// the result path would not fit into the GridPath.
+		budget := gridPathDiagMaxLen - int(current.Weight)
+		if budget <= 0 {
+			continue
+		}
+
+		var directions [8]Direction
+		numDirections := 0
+		if current.Coord == localStart {
+			numDirections = jps.startDirections(current.Coord, &directions)
+		} else {
+			parentKey, _ := pathmap.Get(k)
+			parent := pathmap.unpackCoord(uint(parentKey))
+			numDirections = jps.prunedDirections(current.Coord, parent, &directions)
+		}
+
+		for _, dir := range directions[:numDirections] {
+			var next GridCoord
+			var ok bool
+			if useTable {
+				next, ok = jps.jumpWithTable(current.Coord, dir, budget)
+			} else {
+				next, ok = jps.jump(current.Coord, dir, budget)
+			}
+			if !ok {
+				continue
+			}
+			steps := intabs(next.X - current.Coord.X)
+			if dy := intabs(next.Y - current.Coord.Y); dy > steps {
+				steps = dy
+			}
+			stepCost := uint32(astarCostScale)
+			if dir.IsDiagonal() {
+				stepCost = jpsDiagonalCost
+			}
+			newNextCost := currentCost + stepCost*uint32(steps)
+			nextKey := costmap.packCoord(next)
+			oldNextCost, ok := costmap.Get(nextKey)
+			if ok && newNextCost >= oldNextCost {
+				continue
+			}
+			costmap.Set(nextKey, newNextCost)
+			pathmap.Set(nextKey, uint32(k))
+			priority := newNextCost + uint32(localGoal.octileDist(next, astarCostScale, jpsDiagonalCost))
+			frontier.Push(int(priority), astarCoord{
+				Coord:  next,
+				Cost:   int32(newNextCost),
+				Weight: current.Weight + int32(steps),
+			})
+		}
+	}
+
+	if !foundPath {
+		result.Steps = constructJumpPath(localStart, fallbackCoord, pathmap)
+		result.Finish = fallbackCoord.Add(origin)
+		result.Cost = int((fallbackCost + astarCostScale/2) / astarCostScale)
+		result.Partial = true
+	}
+
+	jps.grid = nil
+
+	return result
+}
+
+// jpsDiagonalCost is a scaled diagonal step cost (see astarCostScale).
+const jpsDiagonalCost = 14
+
+// walkable reports whether a local coord is passable.
+// Like in the JumpTable, everything outside of the grid is considered to be impassable.
+// The search window bounds are checked separately, see inWindow.
+func (jps *JPS) walkable(x, y int) bool {
+	cx := uint(x + jps.origin.X)
+	cy := uint(y + jps.origin.Y)
+	if cx >= jps.grid.numCols || cy >= jps.grid.numRows {
+		return false
+	}
+	if jps.wide && jps.grid.isBlockedCell(cx, cy) {
+		return false
+	}
+	return jps.grid.getTagCost(cx, cy, jps.layer) != 0
+}
+
+// inWindow reports whether a local coord is inside of the search window.
+// The jumps can't leave it, but the cells outside of it are still
+// used to detect the forced neighbors.
+func (jps *JPS) inWindow(x, y int) bool {
+	return uint(x) < jps.windowCols && uint(y) < jps.windowRows
+}
+
+func (jps *JPS) startDirections(c GridCoord, dst *[8]Direction) int {
+	n := 0
+	for dir, offset := range &neighborOffsets {
+		if !jps.walkable(c.X+offset.X, c.Y+offset.Y) {
+			continue
+		}
+		if dir >= int(DirDownRight) {
+			if !jps.walkable(c.X+offset.X, c.Y) || !jps.walkable(c.X, c.Y+offset.Y) {
+				continue
+			}
+		}
+		dst[n] = Direction(dir)
+		n++
+	}
+	return n
+}
+
+// prunedDirections collects the natural and forced neighbor directions
+// for a node that was reached from the parent node.
+func (jps *JPS) prunedDirections(c, parent GridCoord, dst *[8]Direction) int {
+	n := 0
+	push := func(dx, dy int) {
+		dst[n] = directionFromDelta(dx, dy)
+		n++
+	}
+
+	x := c.X
+	y := c.Y
+	dx := signum(x - parent.X)
+	dy := signum(y - parent.Y)
+	switch {
+	case dx != 0 && dy != 0:
+		vertical := jps.walkable(x, y+dy)
+		horizontal := jps.walkable(x+dx, y)
+		if vertical {
+			push(0, dy)
+		}
+		if horizontal {
+			push(dx, 0)
+		}
+		if vertical && horizontal {
+			push(dx, dy)
+		}
+	case dx != 0:
+		next := jps.walkable(x+dx, y)
+		down := jps.walkable(x, y+1)
+		up := jps.walkable(x, y-1)
+		if next {
+			push(dx, 0)
+			if down {
+				push(dx, 1)
+			}
+			if up {
+				push(dx, -1)
+			}
+		}
+		if down {
+			push(0, 1)
+		}
+		if up {
+			push(0, -1)
+		}
+	default:
+		next := jps.walkable(x, y+dy)
+		right := jps.walkable(x+1, y)
+		left := jps.walkable(x-1, y)
+		if next {
+			push(0, dy)
+			if right {
+				push(1, dy)
+			}
+			if left {
+				push(-1, dy)
+			}
+		}
+		if right {
+			push(1, 0)
+		}
+		if left {
+			push(-1, 0)
+		}
+	}
+
+	return n
+}
+
+// jump finds the next jump point in the given direction.
+//
+// The jump can't be longer than the budget number of steps.
+// If a budget limit is reached, the last cell is returned as if it was a jump point.
+// This makes the partial results more useful.
+func (jps *JPS) jump(c GridCoord, dir Direction, budget int) (GridCoord, bool) {
+	offset := neighborOffsets[dir]
+	if dir.IsDiagonal() {
+		return jps.jumpDiagonal(c.X+offset.X, c.Y+offset.Y, offset.X, offset.Y, budget)
+	}
+	return jps.jumpStraight(c.X+offset.X, c.Y+offset.Y, offset.X, offset.Y, budget, true)
+}
+
+// jumpStraight is an axial jump implementation.
+// If budgetPoint is false, reaching the budget limit is not a jump point;
+// the diagonal jumps use it to probe the axial directions.
+func (jps *JPS) jumpStraight(x, y, dx, dy, budget int, budgetPoint bool) (GridCoord, bool) {
+	for steps := 1; steps <= budget; steps++ {
+		if !jps.inWindow(x, y) || !jps.walkable(x, y) {
+			return GridCoord{}, false
+		}
+		c := GridCoord{X: x, Y: y}
+		if c == jps.localGoal || budgetPoint && steps == budget {
+			return c, true
+		}
+		if dx != 0 {
+			if (jps.walkable(x, y-1) && !jps.walkable(x-dx, y-1)) || (jps.walkable(x, y+1) && !jps.walkable(x-dx, y+1)) {
+				return c, true
+			}
+		} else {
+			if (jps.walkable(x-1, y) && !jps.walkable(x-1, y-dy)) || (jps.walkable(x+1, y) && !jps.walkable(x+1, y-dy)) {
+				return c, true
+			}
+		}
+		x += dx
+		y += dy
+	}
+	return GridCoord{}, false
+}
+
+func (jps *JPS) jumpDiagonal(x, y, dx, dy, budget int) (GridCoord, bool) {
+	for steps := 1; steps <= budget; steps++ {
+		if !jps.inWindow(x, y) || !jps.walkable(x, y) {
+			return GridCoord{}, false
+		}
+		c := GridCoord{X: x, Y: y}
+		if c == jps.localGoal || steps == budget {
+			return c, true
+		}
+		if _, ok := jps.jumpStraight(x+dx, y, dx, 0, budget-steps, false); ok {
+			return c, true
+		}
+		if _, ok := jps.jumpStraight(x, y+dy, 0, dy, budget-steps, false); ok {
+			return c, true
+		}
+		if !jps.walkable(x+dx, y) || !jps.walkable(x, y+dy) {
+			return GridCoord{}, false
+		}
+		x += dx
+		y += dy
+	}
+	return GridCoord{}, false
+}
+
+// jumpWithTable is like jump, but it uses the precomputed jump distances.
+func (jps *JPS) jumpWithTable(c GridCoord, dir Direction, budget int) (GridCoord, bool) {
+	offset := neighborOffsets[dir]
+	dist := int(jps.table.get(c.Add(jps.origin), dir))
+	reach := intabs(dist)
+
+	steps := 0
+	goalDelta := jps.localGoal.Sub(c)
+	if dir.IsDiagonal() {
+		if signum(goalDelta.X) == offset.X && signum(goalDelta.Y) == offset.Y {
+			// The goal is somewhere in this quadrant.
+			// If we can get to its row or column, make a jump point there.
+			m := intabs(goalDelta.X)
+			if dy := intabs(goalDelta.Y); dy < m {
+				m = dy
+			}
+			if m <= reach {
+				steps = m
+			}
+		}
+	} else {
+		onLine := (offset.X != 0 && goalDelta.Y == 0 && signum(goalDelta.X) == offset.X) ||
+			(offset.Y != 0 && goalDelta.X == 0 && signum(goalDelta.Y) == offset.Y)
+		if onLine && intabs(goalDelta.X+goalDelta.Y) <= reach {
+			steps = intabs(goalDelta.X + goalDelta.Y)
+		}
+	}
+	if steps == 0 && dist > 0 {
+		steps = dist
+	}
+	if (steps == 0 || steps > budget) && reach >= budget {
+		steps = budget
+	}
+	if steps == 0 {
+		return GridCoord{}, false
+	}
+
+	next := GridCoord{X: c.X + offset.X*steps, Y: c.Y + offset.Y*steps}
+	if uint(next.X) >= jps.windowCols || uint(next.Y) >= jps.windowRows {
+		return GridCoord{}, false
+	}
+	return next, true
+}
+
+func constructJumpPath(from, to GridCoord, pathmap *coordMap) GridPath {
+	// This is similar to constructPath, but the pathmap
+	// stores the parent jump points instead of the directions.
+	// Every jump is a straight or a diagonal line,
+	// so we can walk it step by step.
+	var result GridPath
+	pos := to
+	for pos != from {
+		v, _ := pathmap.Get(pathmap.packCoord(pos))
+		parent := pathmap.unpackCoord(uint(v))
+		d := directionFromDelta(pos.X-parent.X, pos.Y-parent.Y)
+		for pos != parent {
+			result.push(d)
+			pos = pos.reversedMove(d)
+		}
+	}
+	return result
+}
+
+func signum(x int) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
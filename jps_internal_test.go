@@ -0,0 +1,42 @@
+package pathing
+
+import (
+	"testing"
+)
+
+func TestJPSOpenFieldNodes(t *testing.T) {
+	// On an open field, JPS should only visit a few jump points.
+	// The AStar visits hundreds of nodes there.
+	g := NewGrid(GridConfig{WorldWidth: 100 * 32, WorldHeight: 100 * 32})
+	l := MakeGridLayer([8]uint8{1, 0, 0, 0, 0, 0, 0, 0})
+	tests := []struct {
+		from GridCoord
+		to   GridCoord
+	}{
+		{GridCoord{X: 5, Y: 5}, GridCoord{X: 40, Y: 30}},
+		{GridCoord{X: 50, Y: 50}, GridCoord{X: 20, Y: 60}},
+		{GridCoord{X: 90, Y: 10}, GridCoord{X: 90, Y: 40}},
+		{GridCoord{X: 50, Y: 50}, GridCoord{X: 99, Y: 99}},
+	}
+
+	jps := NewJPS(JPSConfig{})
+	jpsPlus := NewJPS(JPSConfig{JumpTable: NewJumpTable(g, l)})
+	for _, test := range tests {
+		for name, impl := range map[string]*JPS{"jps": jps, "jps+": jpsPlus} {
+			impl.BuildPath(g, test.from, test.to, l)
+			if n := testCoordMapLen(impl.pathmap); n > 20 {
+				t.Errorf("%s %v => %v: too many visited nodes (%d)", name, test.from, test.to, n)
+			}
+		}
+	}
+}
+
+func testCoordMapLen(m *coordMap) int {
+	n := 0
+	for _, e := range m.elems {
+		if e.gen == m.gen {
+			n++
+		}
+	}
+	return n
+}
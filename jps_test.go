@@ -0,0 +1,238 @@
+package pathing_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkJPS(b *testing.B) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	for i := range jpsTests {
+		test := jpsTests[i]
+		if !test.bench {
+			continue
+		}
+		numCols := len(test.path[0])
+		numRows := len(test.path)
+		parseResult := testParseGrid(b, test.path)
+		b.Run(fmt.Sprintf("%s_%dx%d", test.name, numCols, numRows), func(b *testing.B) {
+			jps := pathing.NewJPS(pathing.JPSConfig{
+				NumCols: uint(parseResult.numCols),
+				NumRows: uint(parseResult.numRows),
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				jps.BuildPath(parseResult.grid, parseResult.start, parseResult.dest, l)
+			}
+		})
+		b.Run(fmt.Sprintf("%s_%dx%d_plus", test.name, numCols, numRows), func(b *testing.B) {
+			jps := pathing.NewJPS(pathing.JPSConfig{
+				NumCols:   uint(parseResult.numCols),
+				NumRows:   uint(parseResult.numRows),
+				JumpTable: pathing.NewJumpTable(parseResult.grid, l),
+			})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				jps.BuildPath(parseResult.grid, parseResult.start, parseResult.dest, l)
+			}
+		})
+	}
+}
+
+func TestJPS(t *testing.T) {
+	for i := range jpsTests {
+		runPathfindTest(t, jpsTests[i], func(cols, rows uint) pathBuilder {
+			return pathing.NewJPS(pathing.JPSConfig{
+				NumCols: cols,
+				NumRows: rows,
+			})
+		})
+	}
+}
+
+func TestJPSMatchesAStar(t *testing.T) {
+	// JPS and JPS+ should always find a path of the same cost as
+	// A* in the diagonal mode does.
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 0, 0, 0, 0, 0, 0})
+	astar := pathing.NewAStar(pathing.AStarConfig{Diagonal: true})
+	jps := pathing.NewJPS(pathing.JPSConfig{})
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 1000; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 30)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		g.SetCellTile(from, 0)
+		g.SetCellTile(to, 0)
+
+		jpsPlus := pathing.NewJPS(pathing.JPSConfig{
+			JumpTable: pathing.NewJumpTable(g, l),
+		})
+
+		want := astar.BuildPath(g, from, to, l)
+		for _, impl := range []*pathing.JPS{jps, jpsPlus} {
+			have := impl.BuildPath(g, from, to, l)
+			if have.Partial != want.Partial {
+				t.Fatalf("test%d: %v=>%v partial flag mismatch:\nhave: %v\nwant: %v", i, from, to, have.Partial, want.Partial)
+			}
+			if have.Partial {
+				continue
+			}
+			if have.Cost != want.Cost {
+				t.Fatalf("test%d: %v=>%v cost mismatch:\nhave: %v\nwant: %v", i, from, to, have.Cost, want.Cost)
+			}
+			pos := from
+			for have.Steps.HasNext() {
+				pos = pos.Move(have.Steps.Next())
+				if g.GetCellCost(pos, l) == 0 {
+					t.Fatalf("test%d: %v=>%v path goes through a blocked %v cell", i, from, to, pos)
+				}
+			}
+			if pos != to {
+				t.Fatalf("test%d: %v=>%v path ends at %v", i, from, to, pos)
+			}
+		}
+	}
+}
+
+func TestJumpTableRebuild(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		"...A......",
+		"..........",
+		"..........",
+		".....B....",
+		"..........",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	table := pathing.NewJumpTable(g, l)
+	jps := pathing.NewJPS(pathing.JPSConfig{
+		JumpTable: table,
+	})
+
+	result := jps.BuildPath(g, parseResult.start, parseResult.dest, l)
+	if result.Cost != 4 || result.Partial {
+		t.Fatalf("unexpected result before the grid change: cost=%d partial=%v", result.Cost, result.Partial)
+	}
+
+	// Wall off the destination.
+	for y := 0; y < parseResult.numRows; y++ {
+		g.SetCellTile(pathing.GridCoord{X: 4, Y: y}, 1)
+	}
+	table.Rebuild()
+
+	result = jps.BuildPath(g, parseResult.start, parseResult.dest, l)
+	if !result.Partial {
+		t.Fatalf("expected a partial result after the grid change")
+	}
+}
+
+var jpsTests = []pathfindTestCase{
+	{
+		name: "trivial_diagonal",
+		path: []string{
+			"..........",
+			"...A......",
+			".... .....",
+			"..... ....",
+			".....$....",
+			"..........",
+		},
+		cost:  4,
+		bench: true,
+	},
+
+	{
+		name: "no_corner_cutting1",
+		path: []string{
+			"........",
+			"...A   .",
+			"..xxxx .",
+			"....x. .",
+			"....x$..",
+			"........",
+		},
+		cost: 6,
+	},
+
+	{
+		name: "no_corner_cutting2",
+		path: []string{
+			"A.x.....",
+			" x......",
+			" .......",
+			".     $.",
+		},
+		cost: 8,
+	},
+
+	{
+		name: "zigzag",
+		path: []string{
+			"A       ",
+			"xxxxxxx ",
+			"        ",
+			" xxxxxxx",
+			"   $....",
+		},
+		cost:  21,
+		bench: true,
+	},
+
+	{
+		name: "ignore_costs",
+		path: []string{
+			"..........",
+			".A..wwww..",
+			".. .wwww..",
+			"... WWWW $",
+			"..........",
+		},
+		cost: 9,
+	},
+
+	{
+		name: "open_field",
+		path: []string{
+			"..........x.....................",
+			"..........x.....................",
+			"..........x.....................",
+			"..........x.....................",
+			"................................",
+			"..............x.................",
+			"..............x.................",
+			"..A   ........x.................",
+			"....x. .........................",
+			"....x.. ........................",
+			"....x... .......................",
+			"....x.... ......................",
+			".......... .....................",
+			"........... ....................",
+			"............ .........xxxxxxxx..",
+			"............. ..................",
+			"..............   ...............",
+			"...............x. ..............",
+			"...............x.. .............",
+			"...............x... ............",
+			"....x............... ...........",
+			"....x................      $....",
+		},
+		cost:  31,
+		bench: true,
+	},
+
+	{
+		name: "distlimit",
+		path: []string{
+			"A                                     .................................B",
+		},
+		cost:    37,
+		partial: true,
+		bench:   true,
+	},
+}
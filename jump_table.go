@@ -0,0 +1,127 @@
+package pathing
+
+// JumpTable stores the precomputed jump distances for the JPS+ algorithm.
+// You must use NewJumpTable() function to obtain an instance of this type.
+//
+// The table is bound to a specific Grid and GridLayer pair.
+// It stores 8 distances per cell (one per Direction), so it
+// needs 16 bytes per Grid cell.
+//
+// The table is not updated automatically.
+// If the grid is changed (e.g. SetCellTile or SetCellIsBlocked are called),
+// use Rebuild method to recompute the distances.
+type JumpTable struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols int
+	numRows int
+
+	// A positive value N means that there is a jump point N steps away.
+	// A value of -N (or 0) means that there are N steps until the wall.
+	dists []int16
+}
+
+// NewJumpTable creates a jump table for the given grid and layer.
+// See JPSConfig.JumpTable to learn how to use it.
+//
+// The grid should not have more than 32767 columns or rows.
+func NewJumpTable(g *Grid, l GridLayer) *JumpTable {
+	if g.numCols > 0x7fff || g.numRows > 0x7fff {
+		panic("pathing: the grid is too big for a JumpTable")
+	}
+	t := &JumpTable{
+		grid:    g,
+		layer:   l,
+		numCols: int(g.numCols),
+		numRows: int(g.numRows),
+		dists:   make([]int16, g.numCols*g.numRows*8),
+	}
+	t.Rebuild()
+	return t
+}
+
+// Rebuild recomputes the jump distances.
+// It's an O(n) operation, where n is a number of grid cells.
+//
+// Note that the grid size is not expected to change.
+func (t *JumpTable) Rebuild() {
+	// Axial distances go first as diagonal distances depend on them.
+	for dir := DirRight; dir <= DirUp; dir++ {
+		t.rebuildDirection(dir)
+	}
+	for dir := DirDownRight; dir <= DirUpRight; dir++ {
+		t.rebuildDirection(dir)
+	}
+}
+
+func (t *JumpTable) rebuildDirection(dir Direction) {
+	offset := neighborOffsets[dir]
+
+	// The cells are traversed in such an order that
+	// the next cell in the given direction is always computed first.
+	x0, x1, xstep := 0, t.numCols, 1
+	if offset.X > 0 {
+		x0, x1, xstep = t.numCols-1, -1, -1
+	}
+	y0, y1, ystep := 0, t.numRows, 1
+	if offset.Y > 0 {
+		y0, y1, ystep = t.numRows-1, -1, -1
+	}
+
+	for y := y0; y != y1; y += ystep {
+		for x := x0; x != x1; x += xstep {
+			next := GridCoord{X: x + offset.X, Y: y + offset.Y}
+			v := int16(0)
+			switch {
+			case !t.walkable(next.X, next.Y):
+				// The wall is right there.
+			case dir.IsDiagonal() && (!t.walkable(x+offset.X, y) || !t.walkable(x, y+offset.Y)):
+				// Corner cutting is not allowed.
+			case t.isJumpPoint(next, dir, offset):
+				v = 1
+			default:
+				v = t.get(next, dir)
+				if v > 0 {
+					v++
+				} else {
+					v--
+				}
+			}
+			t.dists[t.index(GridCoord{X: x, Y: y}, dir)] = v
+		}
+	}
+}
+
+func (t *JumpTable) isJumpPoint(c GridCoord, dir Direction, offset GridCoord) bool {
+	x := c.X
+	y := c.Y
+	switch dir {
+	case DirRight, DirLeft:
+		dx := offset.X
+		return (t.walkable(x, y-1) && !t.walkable(x-dx, y-1)) || (t.walkable(x, y+1) && !t.walkable(x-dx, y+1))
+	case DirDown, DirUp:
+		dy := offset.Y
+		return (t.walkable(x-1, y) && !t.walkable(x-1, y-dy)) || (t.walkable(x+1, y) && !t.walkable(x+1, y-dy))
+	default:
+		// A diagonal jump point is a cell that has an axial
+		// jump point in one of the diagonal components directions.
+		return t.get(c, directionFromDelta(offset.X, 0)) > 0 ||
+			t.get(c, directionFromDelta(0, offset.Y)) > 0
+	}
+}
+
+func (t *JumpTable) walkable(x, y int) bool {
+	return t.grid.GetCellCost(GridCoord{X: x, Y: y}, t.layer) != 0
+}
+
+func (t *JumpTable) index(c GridCoord, dir Direction) int {
+	return (c.Y*t.numCols+c.X)*8 + int(dir)
+}
+
+func (t *JumpTable) get(c GridCoord, dir Direction) int16 {
+	if uint(c.X) >= uint(t.numCols) || uint(c.Y) >= uint(t.numRows) {
+		return 0
+	}
+	return t.dists[t.index(c, dir)]
+}
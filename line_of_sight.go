@@ -0,0 +1,170 @@
+package pathing
+
+import (
+	"math"
+)
+
+// RaycastResult is a Grid.Raycast() method return value.
+type RaycastResult struct {
+	// Hit reports whether the ray was stopped by a blocking cell.
+	Hit bool
+
+	// Cell is the first blocking cell the ray entered.
+	// It's only meaningful if Hit is true.
+	// This cell can be outside of the grid bounds.
+	Cell GridCoord
+
+	// X and Y is a world position where the ray ends.
+	// If Hit is true, it's where the ray enters the blocking cell.
+	// Otherwise it's the ray destination.
+	X float64
+	Y float64
+}
+
+// HasLineOfSight reports whether there are no blocking cells
+// on the line that connects the from and to cell centers.
+// A blocking cell is a cell that has a cost of 0 in the given layer.
+//
+// Every cell touched by the line is checked (a supercover line).
+// If the line goes exactly through the cells corner,
+// both adjacent cells should be passable.
+//
+// The endpoints themselves are not checked, so a unit standing on
+// a blocked cell can still see its target.
+// The cells outside of the grid are blocking.
+func (g *Grid) HasLineOfSight(from, to GridCoord, l GridLayer) bool {
+	dx := to.X - from.X
+	dy := to.Y - from.Y
+	nx := intabs(dx)
+	ny := intabs(dy)
+	stepX := 1
+	if dx < 0 {
+		stepX = -1
+	}
+	stepY := 1
+	if dy < 0 {
+		stepY = -1
+	}
+
+	x := from.X
+	y := from.Y
+	ix := 0
+	iy := 0
+	for ix < nx || iy < ny {
+		decision := (1+2*ix)*ny - (1+2*iy)*nx
+		switch {
+		case decision == 0:
+			// The line goes through the corner.
+			if g.GetCellCost(GridCoord{X: x + stepX, Y: y}, l) == 0 {
+				return false
+			}
+			if g.GetCellCost(GridCoord{X: x, Y: y + stepY}, l) == 0 {
+				return false
+			}
+			x += stepX
+			y += stepY
+			ix++
+			iy++
+		case decision < 0:
+			x += stepX
+			ix++
+		default:
+			y += stepY
+			iy++
+		}
+		if ix == nx && iy == ny {
+			break
+		}
+		if g.GetCellCost(GridCoord{X: x, Y: y}, l) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Raycast walks the grid cells along the ray that goes from
+// one world position to another.
+// The cells are mapped using the same rules as PosToCoord does.
+//
+// The walk stops at the first blocking cell (a cell with a cost of 0 in the given layer).
+// The cells outside of the grid are blocking.
+// The cell that contains the ray origin is never considered to be blocking,
+// but the destination cell is checked like any other cell.
+//
+// If the ray goes exactly through the cells corner, both adjacent cells are checked.
+func (g *Grid) Raycast(fromX, fromY, toX, toY float64, l GridLayer) RaycastResult {
+	result := RaycastResult{X: toX, Y: toY}
+
+	pos := g.PosToCoord(fromX, fromY)
+	end := g.PosToCoord(toX, toY)
+
+	dirX := toX - fromX
+	dirY := toY - fromY
+
+	// The ray is parametrized as from+dir*t, t is in [0, 1].
+	// The tmax values are the t values of the next cell boundaries.
+	stepX, tmaxX, tdeltaX := raycastAxis(pos.X, fromX, dirX, g.fcellWidth)
+	stepY, tmaxY, tdeltaY := raycastAxis(pos.Y, fromY, dirY, g.fcellHeight)
+
+	n := intabs(end.X-pos.X) + intabs(end.Y-pos.Y)
+	for n > 0 {
+		var t float64
+		switch {
+		case math.Abs(tmaxX-tmaxY) < raycastEpsilon:
+			t = tmaxX
+			if c := (GridCoord{X: pos.X + stepX, Y: pos.Y}); g.GetCellCost(c, l) == 0 {
+				return raycastHit(result, c, fromX, fromY, dirX, dirY, t)
+			}
+			if c := (GridCoord{X: pos.X, Y: pos.Y + stepY}); g.GetCellCost(c, l) == 0 {
+				return raycastHit(result, c, fromX, fromY, dirX, dirY, t)
+			}
+			pos.X += stepX
+			pos.Y += stepY
+			tmaxX += tdeltaX
+			tmaxY += tdeltaY
+			n -= 2
+		case tmaxX < tmaxY:
+			t = tmaxX
+			pos.X += stepX
+			tmaxX += tdeltaX
+			n--
+		default:
+			t = tmaxY
+			pos.Y += stepY
+			tmaxY += tdeltaY
+			n--
+		}
+		if t > 1 {
+			// Can only happen due to the float precision issues.
+			break
+		}
+		if g.GetCellCost(pos, l) == 0 {
+			return raycastHit(result, pos, fromX, fromY, dirX, dirY, t)
+		}
+	}
+
+	return result
+}
+
+// raycastEpsilon is used to detect the corner crossings
+// despite the float precision issues.
+const raycastEpsilon = 1e-9
+
+func raycastAxis(cell int, from, dir, cellSize float64) (step int, tmax, tdelta float64) {
+	switch {
+	case dir > 0:
+		return 1, (float64(cell+1)*cellSize - from) / dir, cellSize / dir
+	case dir < 0:
+		return -1, (float64(cell)*cellSize - from) / dir, -cellSize / dir
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+func raycastHit(result RaycastResult, c GridCoord, fromX, fromY, dirX, dirY, t float64) RaycastResult {
+	result.Hit = true
+	result.Cell = c
+	result.X = fromX + dirX*t
+	result.Y = fromY + dirY*t
+	return result
+}
@@ -0,0 +1,224 @@
+package pathing_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestHasLineOfSight(t *testing.T) {
+	tests := []struct {
+		name string
+		m    []string
+		want bool
+	}{
+		{
+			name: "adjacent",
+			m: []string{
+				"AB",
+			},
+			want: true,
+		},
+		{
+			name: "straight",
+			m: []string{
+				"A.....B",
+			},
+			want: true,
+		},
+		{
+			name: "straight_blocked",
+			m: []string{
+				"A..x..B",
+			},
+			want: false,
+		},
+		{
+			name: "sloped",
+			m: []string{
+				"A.xxxx",
+				"x....x",
+				"xxx..B",
+			},
+			want: true,
+		},
+		{
+			name: "sloped_blocked",
+			m: []string{
+				"A.....",
+				"..x...",
+				".....B",
+			},
+			want: false,
+		},
+		{
+			name: "diagonal",
+			m: []string{
+				"A..",
+				".x.",
+				"..B",
+			},
+			want: false,
+		},
+		{
+			name: "diagonal_corner",
+			m: []string{
+				"Ax.",
+				"...",
+				"..B",
+			},
+			want: false,
+		},
+		{
+			name: "diagonal_clear",
+			m: []string{
+				"A.x",
+				"...",
+				"x.B",
+			},
+			want: true,
+		},
+		{
+			name: "blocked_endpoints",
+			m: []string{
+				"x..",
+				"...",
+				"..x",
+			},
+			want: true,
+		},
+	}
+
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parseResult := testParseGrid(t, test.m)
+			from := parseResult.start
+			to := parseResult.dest
+			if test.name == "blocked_endpoints" {
+				to = pathing.GridCoord{X: 2, Y: 2}
+			}
+			if have := parseResult.grid.HasLineOfSight(from, to, l); have != test.want {
+				t.Fatalf("%v=>%v: have %v, want %v", from, to, have, test.want)
+			}
+			if have := parseResult.grid.HasLineOfSight(to, from, l); have != test.want {
+				t.Fatalf("%v=>%v (reversed): have %v, want %v", to, from, have, test.want)
+			}
+		})
+	}
+}
+
+func TestRaycast(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		"..........",
+		".....x....",
+		"..........",
+		"..........",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+
+	tests := []struct {
+		fromX, fromY float64
+		toX, toY     float64
+		hit          bool
+		cell         pathing.GridCoord
+		x, y         float64
+	}{
+		{
+			fromX: 16, fromY: 16,
+			toX: 300, toY: 16,
+			x: 300, y: 16,
+		},
+		{
+			fromX: 16, fromY: 80,
+			toX: 300, toY: 80,
+			hit:  true,
+			cell: pathing.GridCoord{X: 5, Y: 2},
+			x:    160, y: 80,
+		},
+		{
+			fromX: 300, fromY: 90,
+			toX: 16, toY: 90,
+			hit:  true,
+			cell: pathing.GridCoord{X: 5, Y: 2},
+			x:    192, y: 90,
+		},
+		{
+			fromX: 176, fromY: 150,
+			toX: 176, toY: 10,
+			hit:  true,
+			cell: pathing.GridCoord{X: 5, Y: 2},
+			x:    176, y: 96,
+		},
+		{
+			// Hits the grid boundary.
+			fromX: 100, fromY: 150,
+			toX: 100, toY: 1000,
+			hit:  true,
+			cell: pathing.GridCoord{X: 3, Y: 5},
+			x:    100, y: 160,
+		},
+		{
+			// The corner case.
+			fromX: 128, fromY: 32,
+			toX: 224, toY: 128,
+			hit:  true,
+			cell: pathing.GridCoord{X: 5, Y: 2},
+			x:    160, y: 64,
+		},
+		{
+			fromX: 20, fromY: 20,
+			toX: 20, toY: 20,
+			x: 20, y: 20,
+		},
+	}
+
+	for _, test := range tests {
+		result := g.Raycast(test.fromX, test.fromY, test.toX, test.toY, l)
+		if result.Hit != test.hit {
+			t.Fatalf("(%v,%v)=>(%v,%v): hit mismatch:\nhave: %v\nwant: %v", test.fromX, test.fromY, test.toX, test.toY, result.Hit, test.hit)
+		}
+		if result.Hit && result.Cell != test.cell {
+			t.Fatalf("(%v,%v)=>(%v,%v): cell mismatch:\nhave: %v\nwant: %v", test.fromX, test.fromY, test.toX, test.toY, result.Cell, test.cell)
+		}
+		if math.Abs(result.X-test.x) > 0.001 || math.Abs(result.Y-test.y) > 0.001 {
+			t.Fatalf("(%v,%v)=>(%v,%v): pos mismatch:\nhave: (%v,%v)\nwant: (%v,%v)", test.fromX, test.fromY, test.toX, test.toY, result.X, result.Y, test.x, test.y)
+		}
+	}
+}
+
+func TestRaycastMatchesLineOfSight(t *testing.T) {
+	// A ray between the cell centers should agree with HasLineOfSight.
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 500; i++ {
+		numCols := rng.Intn(20) + 2
+		numRows := rng.Intn(20) + 2
+		g := pathing.NewGrid(pathing.GridConfig{
+			WorldWidth:  uint(numCols) * 32,
+			WorldHeight: uint(numRows) * 32,
+		})
+		for y := 0; y < numRows; y++ {
+			for x := 0; x < numCols; x++ {
+				if rng.Float64() < 0.2 {
+					g.SetCellTile(pathing.GridCoord{X: x, Y: y}, 1)
+				}
+			}
+		}
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		g.SetCellTile(to, 0)
+		fromX, fromY := g.CoordToPos(from)
+		toX, toY := g.CoordToPos(to)
+		result := g.Raycast(fromX, fromY, toX, toY, l)
+		if result.Hit == g.HasLineOfSight(from, to, l) {
+			t.Fatalf("test%d: %v=>%v: raycast hit=%v (%v) disagrees with the line of sight", i, from, to, result.Hit, result.Cell)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package pathing
+
+import (
+	"strings"
+)
+
+// LongGridPath is like GridPath, but it has no length limit.
+// The BuildLongPath methods are used to construct such paths.
+//
+// Every step takes 1 byte of the backing storage.
+// The storage is re-used when a path is rebuilt,
+// so you want to keep the LongGridPath object around instead
+// of creating a new one for every path building request.
+//
+// Unlike GridPath, this path has a reference semantics:
+// a copy of the object shares the backing storage with the original.
+type LongGridPath struct {
+	bytes []byte
+	pos   int
+}
+
+// MakeLongGridPath creates an empty path that will use buf as its storage.
+// The buf contents are discarded, but its capacity will be used.
+func MakeLongGridPath(buf []byte) LongGridPath {
+	return LongGridPath{bytes: buf[:0]}
+}
+
+// String returns a debug-print version of the path.
+// It's not intended to be used a fast path-to-string method.
+func (p LongGridPath) String() string {
+	parts := make([]string, len(p.bytes))
+	for i, b := range p.bytes {
+		parts[i] = Direction(b).String()
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Len returns the path length.
+// It's not affected by the iterator state; the result is always
+// a total path length regardless of the progress.
+func (p *LongGridPath) Len() int {
+	return len(p.bytes)
+}
+
+// HasNext reports whether there are more steps inside this path.
+// Use Next() to extract the next path segment if there are any.
+func (p *LongGridPath) HasNext() bool {
+	return p.pos < len(p.bytes)
+}
+
+// Rewind resets the iterator and allows you to traverse it again.
+func (p *LongGridPath) Rewind() {
+	p.pos = 0
+}
+
+// Peek returns the next path step without advancing the iterator.
+// It returns DirNone if there are no more steps.
+func (p *LongGridPath) Peek() Direction {
+	if p.pos < len(p.bytes) {
+		return Direction(p.bytes[p.pos])
+	}
+	return DirNone
+}
+
+// Next returns the next path step and advances the iterator.
+func (p *LongGridPath) Next() Direction {
+	d := p.Peek()
+	p.pos++
+	return d
+}
+
+// Skip consumes n next path steps.
+func (p *LongGridPath) Skip(n int) {
+	p.pos += n
+}
+
+// Reset removes all steps from the path.
+// The backing storage is kept for the re-use.
+func (p *LongGridPath) Reset() {
+	p.bytes = p.bytes[:0]
+	p.pos = 0
+}
+
+// Push adds a step to the end of the path.
+func (p *LongGridPath) Push(d Direction) {
+	p.bytes = append(p.bytes, byte(d))
+}
+
+// BuildLongPathResult is a BuildLongPath() method return value.
+//
+// It's identical to the BuildPathResult, except for the path steps
+// that are written to the provided LongGridPath.
+type BuildLongPathResult struct {
+	// Finish is where the constructed path ends.
+	Finish GridCoord
+
+	// Cost is a path final movement cost.
+	// See BuildPathResult.Cost comment.
+	Cost int
+
+	// Whether this is a partial path result.
+	// Since the long path search is not limited by the distance,
+	// this only happens if the destination can't be reached.
+	Partial bool
+}
+
+func constructLongPath(from, to GridCoord, pathmap *coordMap, dst *LongGridPath) {
+	// The first pass is needed to find the path length.
+	n := 0
+	for pos := to; pos != from; n++ {
+		d, _ := pathmap.Get(pathmap.packCoord(pos))
+		pos = pos.reversedMove(Direction(d))
+	}
+
+	if cap(dst.bytes) < n {
+		dst.bytes = make([]byte, n)
+	}
+	dst.bytes = dst.bytes[:n]
+	dst.pos = 0
+
+	// Now fill the path in reversed order.
+	pos := to
+	for i := n - 1; i >= 0; i-- {
+		d, _ := pathmap.Get(pathmap.packCoord(pos))
+		dst.bytes[i] = byte(d)
+		pos = pos.reversedMove(Direction(d))
+	}
+}
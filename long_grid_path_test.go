@@ -0,0 +1,161 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+type longPathBuilder interface {
+	BuildLongPath(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer, dst *pathing.LongGridPath) pathing.BuildLongPathResult
+}
+
+func TestLongGridPath(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	buf := make([]byte, 0, 16)
+	for i := 0; i < 100; i++ {
+		size := r.Intn(300)
+		directions := []pathing.Direction{}
+		p := pathing.MakeLongGridPath(buf)
+		for j := 0; j < size; j++ {
+			d := pathing.Direction(r.Intn(8))
+			directions = append(directions, d)
+			p.Push(d)
+		}
+		if p.Len() != size {
+			t.Fatalf("test%d: len mismatch: have %d, want %d", i, p.Len(), size)
+		}
+		for k := 0; k < 2; k++ {
+			reconstructed := []pathing.Direction{}
+			for p.HasNext() {
+				reconstructed = append(reconstructed, p.Next())
+			}
+			if len(directions) != 0 && !reflect.DeepEqual(directions, reconstructed) {
+				t.Fatalf("test%d paths mismatch", i)
+			}
+			if p.Peek() != pathing.DirNone {
+				t.Fatalf("test%d: Peek() after the last step is not DirNone", i)
+			}
+			p.Rewind()
+		}
+	}
+}
+
+func TestLongGridPathString(t *testing.T) {
+	tests := []struct {
+		steps []pathing.Direction
+		want  string
+	}{
+		{nil, "{}"},
+		{[]pathing.Direction{pathing.DirLeft}, "{Left}"},
+		{[]pathing.Direction{pathing.DirLeft, pathing.DirUpRight, pathing.DirDown}, "{Left,UpRight,Down}"},
+	}
+	for _, test := range tests {
+		var p pathing.LongGridPath
+		for _, d := range test.steps {
+			p.Push(d)
+		}
+		p.Next()
+		if p.String() != test.want {
+			t.Fatalf("results mismatched:\nhave: %q\nwant: %q", p.String(), test.want)
+		}
+	}
+}
+
+func TestBuildLongPath(t *testing.T) {
+	// A serpentine map that requires a very long path:
+	//
+	//	A.........
+	//	xxxxxxxxx.
+	//	..........
+	//	.xxxxxxxxx
+	//	..........
+	//	...
+	const numCols = 300
+	const numRows = 301
+	g := pathing.NewGrid(pathing.GridConfig{
+		WorldWidth:  numCols * 32,
+		WorldHeight: numRows * 32,
+	})
+	for y := 1; y < numRows; y += 2 {
+		for x := 0; x < numCols; x++ {
+			if (y%4 == 1 && x == numCols-1) || (y%4 == 3 && x == 0) {
+				continue
+			}
+			g.SetCellTile(pathing.GridCoord{X: x, Y: y}, 1)
+		}
+	}
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	from := pathing.GridCoord{X: 0, Y: 0}
+	to := pathing.GridCoord{X: numCols - 1, Y: numRows - 1}
+	wantLen := (numRows/2)*numCols + numRows/2 + numCols - 1
+
+	impls := map[string]longPathBuilder{
+		"astar":          pathing.NewAStar(pathing.AStarConfig{}),
+		"astar_diagonal": pathing.NewAStar(pathing.AStarConfig{Diagonal: true}),
+		"bfs":            pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}),
+		"bfs_diagonal":   pathing.NewGreedyBFS(pathing.GreedyBFSConfig{Diagonal: true}),
+	}
+	for name, impl := range impls {
+		var p pathing.LongGridPath
+		result := impl.BuildLongPath(g, from, to, l, &p)
+		if result.Partial || result.Finish != to {
+			t.Fatalf("%s: failed to build a path (finish=%v)", name, result.Finish)
+		}
+		if name == "astar" && (p.Len() != wantLen || result.Cost != wantLen) {
+			t.Fatalf("%s: path is not optimal: have len=%d cost=%d, want %d", name, p.Len(), result.Cost, wantLen)
+		}
+		if p.Len() < wantLen*2/3 {
+			t.Fatalf("%s: path is too short (%d)", name, p.Len())
+		}
+		pos := from
+		for p.HasNext() {
+			pos = pos.Move(p.Next())
+			if g.GetCellCost(pos, l) == 0 {
+				t.Fatalf("%s: path goes through a blocked %v cell", name, pos)
+			}
+		}
+		if pos != to {
+			t.Fatalf("%s: path ends at %v", name, pos)
+		}
+
+		allocs := testing.AllocsPerRun(5, func() {
+			impl.BuildLongPath(g, from, to, l, &p)
+		})
+		if allocs != 0 {
+			t.Fatalf("%s: a path re-building allocates (%v allocs)", name, allocs)
+		}
+	}
+}
+
+func TestBuildLongPathUnreachable(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		"...A......",
+		".....xxxxx",
+		".....x....",
+		".....x..B.",
+	})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+
+	impls := map[string]longPathBuilder{
+		"astar": pathing.NewAStar(pathing.AStarConfig{}),
+		"bfs":   pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}),
+	}
+	for name, impl := range impls {
+		var p pathing.LongGridPath
+		result := impl.BuildLongPath(parseResult.grid, parseResult.start, parseResult.dest, l, &p)
+		if !result.Partial {
+			t.Fatalf("%s: expected a partial result", name)
+		}
+		if want := (pathing.GridCoord{X: 8, Y: 1}); result.Finish != want {
+			t.Fatalf("%s: finish mismatch: have %v, want %v", name, result.Finish, want)
+		}
+		if p.Len() != 5 {
+			t.Fatalf("%s: expected a fallback path of 5 steps, got %d", name, p.Len())
+		}
+	}
+}
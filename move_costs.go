@@ -0,0 +1,57 @@
+package pathing
+
+// moveCosts describes the grid movement rules that are shared
+// between the pathfinders that support the diagonal mode.
+//
+// In the diagonal mode, the costs are scaled by astarCostScale
+// to keep the computations integral; use unscaleCost to get the
+// actual path cost.
+type moveCosts struct {
+	numNeighbors  int
+	cornerCutting bool
+	axialCost     uint32
+	diagonalCost  uint32
+}
+
+// makeMoveCosts interprets the Diagonal, DiagonalCost and CornerCutting
+// config options. See AStarConfig for their description.
+func makeMoveCosts(diagonal bool, diagonalCost float64, cornerCutting bool) moveCosts {
+	if !diagonal {
+		return moveCosts{
+			numNeighbors: 4,
+			axialCost:    1,
+		}
+	}
+
+	if diagonalCost == 0 {
+		diagonalCost = 1.4
+	}
+	if diagonalCost < 1 {
+		diagonalCost = 1
+	}
+	return moveCosts{
+		numNeighbors:  8,
+		cornerCutting: cornerCutting,
+		axialCost:     astarCostScale,
+		diagonalCost:  uint32(diagonalCost*astarCostScale + 0.5),
+	}
+}
+
+func (c *moveCosts) heuristic(a, b GridCoord) int {
+	if c.numNeighbors == 4 {
+		return a.Dist(b)
+	}
+	// A diagonal step is never more expensive than two axial steps.
+	diagonalCost := c.diagonalCost
+	if diagonalCost > 2*c.axialCost {
+		diagonalCost = 2 * c.axialCost
+	}
+	return a.octileDist(b, int(c.axialCost), int(diagonalCost))
+}
+
+func (c *moveCosts) unscaleCost(cost uint32) int {
+	if c.axialCost == 1 {
+		return int(cost)
+	}
+	return int((cost + c.axialCost/2) / c.axialCost)
+}
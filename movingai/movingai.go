@@ -0,0 +1,233 @@
+// Package movingai implements the Moving AI Lab benchmark formats support.
+//
+// The benchmark maps (.map files) and scenarios (.scen files)
+// can be found at https://movingai.com/benchmarks/grids.html
+//
+// The scenario runner can be used to compare the path lengths
+// with the optimal ones and to measure the pathfinding times.
+package movingai
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/pathing"
+)
+
+// The tile tags used by the loaded grids.
+const (
+	TagGround      uint8 = 0 // '.' and 'G'
+	TagOutOfBounds uint8 = 1 // '@' and 'O'
+	TagTrees       uint8 = 2 // 'T'
+	TagSwamp       uint8 = 3 // 'S'
+	TagWater       uint8 = 4 // 'W'
+)
+
+// Legend maps the map file terrain characters to the tile tags.
+var Legend = map[rune]uint8{
+	'.': TagGround,
+	'G': TagGround,
+	'@': TagOutOfBounds,
+	'O': TagOutOfBounds,
+	'T': TagTrees,
+	'S': TagSwamp,
+	'W': TagWater,
+}
+
+// Layer is a layer that matches the benchmark rules:
+// only the ground and swamp tiles are passable.
+var Layer = pathing.MakeGridLayer([8]uint8{
+	TagGround: 1,
+	TagSwamp:  1,
+})
+
+// Map is a loaded Moving AI map.
+type Map struct {
+	// Type is a map type as specified in the file header (usually "octile").
+	Type string
+
+	// Grid is the loaded grid, see Legend and Layer.
+	Grid *pathing.Grid
+}
+
+// LoadMap reads a .map file.
+// See ParseMap for more details.
+func LoadMap(filename string, config pathing.GridConfig) (*Map, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := ParseMap(f, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return m, nil
+}
+
+// ParseMap reads a map in the Moving AI .map format.
+//
+// The config cell size is used as is, but the world size is
+// derived from the map dimensions.
+func ParseMap(r io.Reader, config pathing.GridConfig) (*Map, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+
+	m := &Map{}
+	width := -1
+	height := -1
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "map" {
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		var err error
+		switch key {
+		case "type":
+			m.Type = value
+		case "width":
+			width, err = strconv.Atoi(value)
+		case "height":
+			height, err = strconv.Atoi(value)
+		default:
+			err = fmt.Errorf("unexpected %q header line", line)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("movingai: line %d: %w", lineNum, err)
+		}
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("movingai: missing or invalid map dimensions")
+	}
+
+	lines := make([]string, 0, height)
+	for len(lines) < height && s.Scan() {
+		lines = append(lines, strings.TrimRight(s.Text(), "\r"))
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("movingai: %w", err)
+	}
+	if len(lines) != height {
+		return nil, fmt.Errorf("movingai: expected %d map lines, found %d", height, len(lines))
+	}
+	if len(lines[0]) != width {
+		return nil, fmt.Errorf("movingai: expected %d map columns, found %d", width, len(lines[0]))
+	}
+
+	g, err := pathing.ParseGridASCII(lines, Legend, config)
+	if err != nil {
+		return nil, fmt.Errorf("movingai: %w", err)
+	}
+	m.Grid = g
+	return m, nil
+}
+
+// Scenario is a single pathfinding problem from the .scen file.
+type Scenario struct {
+	Bucket int
+
+	// Map is a map file name, as specified in the scenario file.
+	Map string
+
+	MapWidth  int
+	MapHeight int
+
+	Start pathing.GridCoord
+	Goal  pathing.GridCoord
+
+	// Optimal is an optimal path length.
+	// A diagonal step length is sqrt(2) and the corner cutting is not allowed.
+	Optimal float64
+}
+
+// LoadScenarios reads a .scen file.
+// See ParseScenarios for more details.
+func LoadScenarios(filename string) ([]Scenario, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scenarios, err := ParseScenarios(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return scenarios, nil
+}
+
+// ParseScenarios reads the scenarios in the Moving AI .scen format.
+// Only the version 1 format is supported.
+func ParseScenarios(r io.Reader) ([]Scenario, error) {
+	s := bufio.NewScanner(r)
+
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, fmt.Errorf("movingai: %w", err)
+		}
+		return nil, fmt.Errorf("movingai: empty scenario file")
+	}
+	if header := strings.TrimSpace(s.Text()); header != "version 1" && header != "version 1.0" {
+		return nil, fmt.Errorf("movingai: unsupported %q scenario version", header)
+	}
+
+	var scenarios []Scenario
+	lineNum := 1
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		scenario, err := parseScenario(line)
+		if err != nil {
+			return nil, fmt.Errorf("movingai: line %d: %w", lineNum, err)
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("movingai: %w", err)
+	}
+	return scenarios, nil
+}
+
+func parseScenario(line string) (Scenario, error) {
+	var scenario Scenario
+	fields := strings.Fields(line)
+	if len(fields) != 9 {
+		return scenario, fmt.Errorf("expected 9 fields, found %d", len(fields))
+	}
+	ints := [...]*int{
+		&scenario.Bucket,
+		nil, // The map name
+		&scenario.MapWidth,
+		&scenario.MapHeight,
+		&scenario.Start.X,
+		&scenario.Start.Y,
+		&scenario.Goal.X,
+		&scenario.Goal.Y,
+	}
+	for i, dst := range ints {
+		if dst == nil {
+			continue
+		}
+		v, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return scenario, fmt.Errorf("field %d: %w", i+1, err)
+		}
+		*dst = v
+	}
+	scenario.Map = fields[1]
+	optimal, err := strconv.ParseFloat(fields[8], 64)
+	if err != nil {
+		return scenario, fmt.Errorf("field 9: %w", err)
+	}
+	scenario.Optimal = optimal
+	return scenario, nil
+}
@@ -0,0 +1,166 @@
+package movingai_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+	"github.com/quasilyte/pathing/movingai"
+)
+
+func TestLoadMap(t *testing.T) {
+	m, err := movingai.LoadMap("testdata/arena.map", pathing.GridConfig{CellWidth: 4, CellHeight: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "octile" {
+		t.Fatalf("map type mismatch: %q", m.Type)
+	}
+	if m.Grid.NumCols() != 8 || m.Grid.NumRows() != 5 {
+		t.Fatalf("grid size mismatch: %dx%d", m.Grid.NumCols(), m.Grid.NumRows())
+	}
+	have := strings.Join(m.Grid.FormatASCII(map[rune]uint8{'.': 0, '@': 1, 'T': 2}), "\n")
+	want := strings.Join([]string{
+		"@@@@@@@@",
+		"@......@",
+		"@.TTT..@",
+		"@......@",
+		"@@@@@@@@",
+	}, "\n")
+	if have != want {
+		t.Fatalf("grid mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
+
+func TestParseMapErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"bad_header", "type octile\nsize 4\nmap\n", `movingai: line 2: unexpected "size 4" header line`},
+		{"no_size", "type octile\nmap\n", `movingai: missing or invalid map dimensions`},
+		{"bad_size", "type octile\nheight x\nwidth 2\nmap\n..\n", `movingai: line 2: strconv.Atoi: parsing "x": invalid syntax`},
+		{"few_lines", "type octile\nheight 3\nwidth 2\nmap\n..\n..\n", `movingai: expected 3 map lines, found 2`},
+		{"bad_width", "type octile\nheight 2\nwidth 2\nmap\n...\n...\n", `movingai: expected 2 map columns, found 3`},
+		{"uneven_lines", "type octile\nheight 2\nwidth 2\nmap\n..\n...\n", `movingai: pathing: parse ASCII grid: line 2: expected 2 runes, found 3`},
+		{"bad_terrain", "type octile\nheight 2\nwidth 2\nmap\n..\n.?\n", `movingai: pathing: parse ASCII grid: line 2: column 2: '?' is not in the legend`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := movingai.ParseMap(strings.NewReader(test.data), pathing.GridConfig{})
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("error mismatch:\nhave: %v\nwant: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestParseScenariosErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"empty", "", `movingai: empty scenario file`},
+		{"version", "version 2\n", `movingai: unsupported "version 2" scenario version`},
+		{"few_fields", "version 1\n0\ta.map\t8\t5\t1\t1\t6\t1\n", `movingai: line 2: expected 9 fields, found 8`},
+		{"bad_int", "version 1\n0\ta.map\t8\t5\tx\t1\t6\t1\t5\n", `movingai: line 2: field 5: strconv.Atoi: parsing "x": invalid syntax`},
+		{"bad_float", "version 1\n0\ta.map\t8\t5\t1\t1\t6\t1\tx\n", `movingai: line 2: field 9: strconv.ParseFloat: parsing "x": invalid syntax`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			_, err := movingai.ParseScenarios(strings.NewReader(test.data))
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("error mismatch:\nhave: %v\nwant: %s", err, test.err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	m, err := movingai.LoadMap("testdata/arena.map", pathing.GridConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenarios, err := movingai.LoadScenarios("testdata/arena.map.scen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) != 4 {
+		t.Fatalf("expected 4 scenarios, got %d", len(scenarios))
+	}
+	wantScenario := movingai.Scenario{
+		Map:       "arena.map",
+		MapWidth:  8,
+		MapHeight: 5,
+		Start:     pathing.GridCoord{X: 1, Y: 1},
+		Goal:      pathing.GridCoord{X: 6, Y: 3},
+		Optimal:   6.41421356,
+	}
+	if scenarios[1] != wantScenario {
+		t.Fatalf("scenario mismatch:\nhave: %+v\nwant: %+v", scenarios[1], wantScenario)
+	}
+
+	tests := []struct {
+		name       string
+		pathfinder movingai.Pathfinder
+		lengths    []float64
+		maxSubopt  float64
+	}{
+		{
+			name:       "astar",
+			pathfinder: pathing.NewAStar(pathing.AStarConfig{}),
+			lengths:    []float64{5, 7, 5, 0},
+			maxSubopt:  7 / 6.41421356,
+		},
+		{
+			name:       "astar_diagonal",
+			pathfinder: pathing.NewAStar(pathing.AStarConfig{Diagonal: true}),
+			lengths:    []float64{5, 5 + math.Sqrt2, 5, 0},
+			maxSubopt:  1,
+		},
+		{
+			name:       "greedy_bfs_diagonal",
+			pathfinder: pathing.NewGreedyBFS(pathing.GreedyBFSConfig{Diagonal: true}),
+			lengths:    []float64{5, 5 + math.Sqrt2, 5, 0},
+			maxSubopt:  1,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			report, err := movingai.Run(m.Grid, scenarios, test.pathfinder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.NumFailed != 1 {
+				t.Fatalf("expected 1 failed scenario, got %d", report.NumFailed)
+			}
+			for i, r := range report.Results {
+				if r.Found != (test.lengths[i] != 0) {
+					t.Fatalf("scenario %d: unexpected found flag: %v", i, r.Found)
+				}
+				if math.Abs(r.Length-test.lengths[i]) > 1e-6 {
+					t.Fatalf("scenario %d: length mismatch:\nhave: %v\nwant: %v", i, r.Length, test.lengths[i])
+				}
+			}
+			if math.Abs(report.MaxSuboptimality-test.maxSubopt) > 1e-6 {
+				t.Fatalf("max suboptimality mismatch:\nhave: %v\nwant: %v", report.MaxSuboptimality, test.maxSubopt)
+			}
+			if !strings.HasPrefix(report.String(), "scenarios: 4 (failed: 1), suboptimality: mean") {
+				t.Fatalf("unexpected report summary: %s", report.String())
+			}
+		})
+	}
+
+	small := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32, WorldHeight: 32})
+	_, err = movingai.Run(small, scenarios, pathing.NewAStar(pathing.AStarConfig{}))
+	wantErr := "movingai: scenario 0: 8x5 map is expected, got 1x1 grid"
+	if err == nil || err.Error() != wantErr {
+		t.Fatalf("error mismatch:\nhave: %v\nwant: %s", err, wantErr)
+	}
+}
@@ -0,0 +1,134 @@
+package movingai
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+// Pathfinder is implemented by the pathing.AStar and pathing.GreedyBFS.
+//
+// The benchmark paths are often longer than the GridPath limit,
+// so the long paths API is used.
+type Pathfinder interface {
+	BuildLongPath(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer, dst *pathing.LongGridPath) pathing.BuildLongPathResult
+}
+
+// ScenarioResult is a single scenario run result.
+type ScenarioResult struct {
+	Scenario Scenario
+
+	// Found reports whether the goal was reached.
+	Found bool
+
+	// Length is the found path length.
+	// It's measured the same way as the Scenario.Optimal value.
+	Length float64
+
+	// Suboptimality is a Length to Scenario.Optimal ratio.
+	// A value of 1 means that the path is optimal.
+	// It's 0 if the path was not found.
+	Suboptimality float64
+
+	// Duration is the time it took to build the path.
+	Duration time.Duration
+}
+
+// Report is a Run() function return value.
+type Report struct {
+	Results []ScenarioResult
+
+	// NumFailed is a number of scenarios where the goal was not reached.
+	NumFailed int
+
+	// MeanSuboptimality and MaxSuboptimality are
+	// computed over the scenarios that were solved.
+	MeanSuboptimality float64
+	MaxSuboptimality  float64
+
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+}
+
+// String returns a human-readable report summary.
+func (r *Report) String() string {
+	meanDuration := time.Duration(0)
+	if len(r.Results) != 0 {
+		meanDuration = r.TotalDuration / time.Duration(len(r.Results))
+	}
+	return fmt.Sprintf("scenarios: %d (failed: %d), suboptimality: mean %.4f, max %.4f, time: total %v, mean %v, max %v",
+		len(r.Results), r.NumFailed, r.MeanSuboptimality, r.MaxSuboptimality,
+		r.TotalDuration, meanDuration, r.MaxDuration)
+}
+
+// Run executes all scenarios using the given pathfinder.
+// The grid is expected to be loaded from the scenarios map,
+// the Layer is used as a grid layer.
+//
+// Note that the optimal lengths assume the 8-directional movement,
+// so a pathfinder in the 4-directional mode will report suboptimal paths.
+func Run(g *pathing.Grid, scenarios []Scenario, pathfinder Pathfinder) (*Report, error) {
+	report := &Report{
+		Results: make([]ScenarioResult, 0, len(scenarios)),
+	}
+
+	var path pathing.LongGridPath
+	numSolved := 0
+	totalSuboptimality := 0.0
+	for i, scenario := range scenarios {
+		if scenario.MapWidth != g.NumCols() || scenario.MapHeight != g.NumRows() {
+			return nil, fmt.Errorf("movingai: scenario %d: %dx%d map is expected, got %dx%d grid",
+				i, scenario.MapWidth, scenario.MapHeight, g.NumCols(), g.NumRows())
+		}
+
+		startTime := time.Now()
+		result := pathfinder.BuildLongPath(g, scenario.Start, scenario.Goal, Layer, &path)
+		elapsed := time.Since(startTime)
+
+		r := ScenarioResult{
+			Scenario: scenario,
+			Found:    !result.Partial,
+			Duration: elapsed,
+		}
+		if r.Found {
+			r.Length = pathLength(&path)
+			r.Suboptimality = 1
+			if scenario.Optimal != 0 {
+				r.Suboptimality = r.Length / scenario.Optimal
+			}
+			numSolved++
+			totalSuboptimality += r.Suboptimality
+			if r.Suboptimality > report.MaxSuboptimality {
+				report.MaxSuboptimality = r.Suboptimality
+			}
+		} else {
+			report.NumFailed++
+		}
+		report.TotalDuration += elapsed
+		if elapsed > report.MaxDuration {
+			report.MaxDuration = elapsed
+		}
+		report.Results = append(report.Results, r)
+	}
+	if numSolved != 0 {
+		report.MeanSuboptimality = totalSuboptimality / float64(numSolved)
+	}
+
+	return report, nil
+}
+
+func pathLength(path *pathing.LongGridPath) float64 {
+	numAxial := 0
+	numDiagonal := 0
+	path.Rewind()
+	for path.HasNext() {
+		if path.Next().IsDiagonal() {
+			numDiagonal++
+		} else {
+			numAxial++
+		}
+	}
+	return float64(numAxial) + float64(numDiagonal)*math.Sqrt2
+}
@@ -0,0 +1,173 @@
+package pathing
+
+// BuildPathToAnyResult is a BuildPathToAny() method return value.
+type BuildPathToAnyResult struct {
+	BuildPathResult
+
+	// GoalIndex is an index of the reached goal inside the goals slice.
+	// It's -1 for the partial results.
+	GoalIndex int
+}
+
+// pathGoals describes a multi-goal search target.
+// Only one of the fields is set.
+type pathGoals struct {
+	coords []GridCoord
+	match  func(GridCoord) bool
+}
+
+func (goals *pathGoals) reached(c GridCoord) bool {
+	if goals.match != nil {
+		return goals.match(c)
+	}
+	return goals.index(c) != -1
+}
+
+func (goals *pathGoals) index(c GridCoord) int {
+	for i, goal := range goals.coords {
+		if goal == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// BuildPathToAny is like BuildPath, but it finds a path to the nearest of the goals.
+// The result GoalIndex tells which of the goals was reached.
+//
+// The goals are checked linearly, so this method is intended for
+// a relatively small number of goals.
+// Just like with BuildPath, the goals that are too far away can't be reached.
+func (astar *AStar) BuildPathToAny(g *Grid, from GridCoord, goals []GridCoord, l GridLayer) BuildPathToAnyResult {
+	target := pathGoals{coords: goals}
+	var result BuildPathToAnyResult
+	result.BuildPathResult = astar.buildPathToGoals(g, from, &target, l)
+	result.GoalIndex = -1
+	if !result.Partial {
+		result.GoalIndex = target.index(result.Finish)
+	}
+	return result
+}
+
+// BuildPathToMatch is like BuildPath, but its destination is the nearest cell
+// for which the predicate returns true. The reached cell is stored in the result Finish.
+//
+// There is no heuristic to guide the search, so it works like a Dijkstra algorithm:
+// the cells are visited in the order of their path cost.
+// The predicate argument is a grid coordinate.
+// It can be called more than once for the same cell, so it should be cheap and pure.
+//
+// If no matching cell was found, a partial result with an empty path is returned.
+func (astar *AStar) BuildPathToMatch(g *Grid, from GridCoord, predicate func(GridCoord) bool, l GridLayer) BuildPathResult {
+	target := pathGoals{match: predicate}
+	return astar.buildPathToGoals(g, from, &target, l)
+}
+
+func (astar *AStar) buildPathToGoals(g *Grid, from GridCoord, goals *pathGoals, l GridLayer) BuildPathResult {
+	var result BuildPathResult
+	if goals.reached(from) {
+		result.Finish = from
+		return result
+	}
+	if goals.match == nil && len(goals.coords) == 0 {
+		result.Finish = from
+		result.Partial = true
+		return result
+	}
+
+	origin := findPathOrigin(from)
+	localStart := from.Sub(origin)
+
+	astar.costmap.Reset()
+	astar.pathmap.Reset()
+
+	finish, cost, found := astar.search(g, l, origin, localStart, GridCoord{}, goals, int32(gridPathLimit(astar.numNeighbors)), astar.costmap, astar.pathmap)
+	result.Steps = constructPath(localStart, finish, astar.pathmap)
+	result.Finish = finish.Add(origin)
+	result.Cost = astar.unscaleCost(uint32(cost))
+	result.Partial = !found
+
+	return result
+}
+
+// goalsHeuristic returns the heuristic value for the nearest goal.
+// A min of the admissible heuristics is admissible too.
+func (astar *AStar) goalsHeuristic(goals *pathGoals, c GridCoord) int {
+	if goals.match != nil {
+		return 0
+	}
+	h := astar.heuristic(goals.coords[0], c)
+	for _, goal := range goals.coords[1:] {
+		if v := astar.heuristic(goal, c); v < h {
+			h = v
+		}
+	}
+	return h
+}
+
+// BuildPathToAny is like BuildPath, but it finds a path to one of the goals.
+// The result GoalIndex tells which of the goals was reached.
+//
+// The search is greedy, so the reached goal is not guaranteed to be the nearest one.
+// See AStar.BuildPathToAny for other notes.
+func (bfs *GreedyBFS) BuildPathToAny(g *Grid, from GridCoord, goals []GridCoord, l GridLayer) BuildPathToAnyResult {
+	target := pathGoals{coords: goals}
+	var result BuildPathToAnyResult
+	result.BuildPathResult = bfs.buildPathToGoals(g, from, &target, l)
+	result.GoalIndex = -1
+	if !result.Partial {
+		result.GoalIndex = target.index(result.Finish)
+	}
+	return result
+}
+
+// BuildPathToMatch is like BuildPath, but its destination is the nearest cell
+// for which the predicate returns true. The reached cell is stored in the result Finish.
+//
+// There is no heuristic to guide the search, so it works like a plain breadth-first search:
+// the cells are visited in the order of their distance in steps.
+// See AStar.BuildPathToMatch for other notes.
+func (bfs *GreedyBFS) BuildPathToMatch(g *Grid, from GridCoord, predicate func(GridCoord) bool, l GridLayer) BuildPathResult {
+	target := pathGoals{match: predicate}
+	return bfs.buildPathToGoals(g, from, &target, l)
+}
+
+func (bfs *GreedyBFS) buildPathToGoals(g *Grid, from GridCoord, goals *pathGoals, l GridLayer) BuildPathResult {
+	var result BuildPathResult
+	if goals.reached(from) {
+		result.Finish = from
+		return result
+	}
+	if goals.match == nil && len(goals.coords) == 0 {
+		result.Finish = from
+		result.Partial = true
+		return result
+	}
+
+	origin := findPathOrigin(from)
+	localStart := from.Sub(origin)
+
+	bfs.coordMap.Reset()
+
+	finish, found := bfs.search(g, l, origin, localStart, GridCoord{}, goals, gridPathLimit(bfs.numNeighbors), bfs.numNeighbors == 8, bfs.coordMap)
+	result.Steps = constructPath(localStart, finish, bfs.coordMap)
+	result.Finish = finish.Add(origin)
+	result.Cost = result.Steps.Len()
+	result.Partial = !found
+
+	return result
+}
+
+// goalsDist returns the distance to the nearest goal.
+func (bfs *GreedyBFS) goalsDist(goals *pathGoals, c GridCoord) int {
+	if goals.match != nil {
+		return 0
+	}
+	d := bfs.dist(goals.coords[0], c)
+	for _, goal := range goals.coords[1:] {
+		if v := bfs.dist(goal, c); v < d {
+			d = v
+		}
+	}
+	return d
+}
@@ -0,0 +1,220 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestBuildPathToAny(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..........",
+		".A..x.....",
+		"....x.....",
+		"wwwwx.....",
+		"..........",
+	})
+	g := parseResult.grid
+	from := parseResult.start
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	bfs := pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})
+
+	tests := []struct {
+		goals     []pathing.GridCoord
+		goalIndex int
+		cost      int
+	}{
+		{goals: []pathing.GridCoord{{X: 1, Y: 1}, {X: 9, Y: 4}}, goalIndex: 0, cost: 0},
+		{goals: []pathing.GridCoord{{X: 6, Y: 1}}, goalIndex: 0, cost: 7},
+		{goals: []pathing.GridCoord{{X: 9, Y: 4}, {X: 6, Y: 1}}, goalIndex: 1, cost: 7},
+		// Going through the water is cheaper than the detour.
+		{goals: []pathing.GridCoord{{X: 9, Y: 1}, {X: 0, Y: 4}}, goalIndex: 1, cost: 6},
+	}
+	for i, test := range tests {
+		result := astar.BuildPathToAny(g, from, test.goals, l)
+		if result.Partial || result.GoalIndex != test.goalIndex || result.Cost != test.cost {
+			t.Fatalf("test%d: have goal=%d cost=%d partial=%v, want goal=%d cost=%d",
+				i, result.GoalIndex, result.Cost, result.Partial, test.goalIndex, test.cost)
+		}
+		checkMultiGoalPath(t, g, l, from, result.BuildPathResult)
+		if result.Finish != test.goals[result.GoalIndex] {
+			t.Fatalf("test%d: finish mismatch: %v", i, result.Finish)
+		}
+
+		bfsResult := bfs.BuildPathToAny(g, from, test.goals, l)
+		if bfsResult.Partial || bfsResult.Finish != test.goals[bfsResult.GoalIndex] {
+			t.Fatalf("test%d: bfs: goal is not reached", i)
+		}
+		checkMultiGoalPath(t, g, l, from, bfsResult.BuildPathResult)
+	}
+
+	unreachable := []pathing.GridCoord{{X: 4, Y: 1}, {X: 100, Y: 100}}
+	for _, result := range []pathing.BuildPathToAnyResult{astar.BuildPathToAny(g, from, unreachable, l), bfs.BuildPathToAny(g, from, unreachable, l)} {
+		if !result.Partial || result.GoalIndex != -1 {
+			t.Fatalf("expected a partial result, got a path to %v", result.Finish)
+		}
+		checkMultiGoalPath(t, g, l, from, result.BuildPathResult)
+	}
+	if result := astar.BuildPathToAny(g, from, nil, l); !result.Partial || result.Finish != from {
+		t.Fatalf("expected a partial result for an empty goals list")
+	}
+
+	goals := tests[3].goals
+	allocs := testing.AllocsPerRun(10, func() {
+		astar.BuildPathToAny(g, from, goals, l)
+		bfs.BuildPathToAny(g, from, goals, l)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocs, got %v", allocs)
+	}
+}
+
+func TestBuildPathToMatch(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"o.........",
+		".A..x...o.",
+		"....x.....",
+		"wwwwx.....",
+		"..........",
+	})
+	g := parseResult.grid
+	from := parseResult.start
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 3, 0, 0, 0, 0})
+
+	isTree := func(c pathing.GridCoord) bool {
+		return g.GetCellTile(c) == 2
+	}
+
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	result := astar.BuildPathToMatch(g, from, isTree, l)
+	if result.Partial || result.Finish != (pathing.GridCoord{X: 0, Y: 0}) || result.Cost != 2 {
+		t.Fatalf("astar: unexpected result: %v cost=%d partial=%v", result.Finish, result.Cost, result.Partial)
+	}
+	checkMultiGoalPath(t, g, l, from, result)
+
+	bfs := pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})
+	result = bfs.BuildPathToMatch(g, from, isTree, l)
+	if result.Partial || result.Finish != (pathing.GridCoord{X: 0, Y: 0}) || result.Steps.Len() != 2 {
+		t.Fatalf("bfs: unexpected result: %v steps=%s partial=%v", result.Finish, result.Steps, result.Partial)
+	}
+	checkMultiGoalPath(t, g, l, from, result)
+
+	never := func(c pathing.GridCoord) bool { return false }
+	for _, result := range []pathing.BuildPathResult{astar.BuildPathToMatch(g, from, never, l), bfs.BuildPathToMatch(g, from, never, l)} {
+		if !result.Partial || result.Finish != from || result.Steps.Len() != 0 {
+			t.Fatalf("expected an empty partial result, got a path to %v", result.Finish)
+		}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		astar.BuildPathToMatch(g, from, isTree, l)
+		bfs.BuildPathToMatch(g, from, isTree, l)
+	})
+	if allocs != 0 {
+		t.Fatalf("expected zero allocs, got %v", allocs)
+	}
+}
+
+func TestBuildPathToAnyOptimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	for i := 0; i < 200; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 40)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		if g.GetCellCost(from, l) == 0 {
+			continue
+		}
+		goals := make([]pathing.GridCoord, rng.Intn(5)+1)
+		for j := range goals {
+			goals[j] = pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		}
+
+		// The goal is reached if there is a short enough path to it.
+		wantCost := -1
+		for _, goal := range goals {
+			result := astar.BuildPath(g, from, goal, l)
+			if result.Partial || result.Cost > 40 {
+				continue
+			}
+			if wantCost == -1 || result.Cost < wantCost {
+				wantCost = result.Cost
+			}
+		}
+		if wantCost == -1 {
+			continue
+		}
+
+		have := astar.BuildPathToAny(g, from, goals, l)
+		if have.Partial || have.Cost != wantCost {
+			t.Fatalf("test%d: %v->%v: have cost=%d partial=%v, want cost=%d", i, from, goals, have.Cost, have.Partial, wantCost)
+		}
+		if have.Finish != goals[have.GoalIndex] {
+			t.Fatalf("test%d: goal index mismatch", i)
+		}
+		checkMultiGoalPath(t, g, l, from, have.BuildPathResult)
+
+		isGoal := func(c pathing.GridCoord) bool {
+			for _, goal := range goals {
+				if goal == c {
+					return true
+				}
+			}
+			return false
+		}
+		haveMatch := astar.BuildPathToMatch(g, from, isGoal, l)
+		if haveMatch.Partial || haveMatch.Cost != wantCost {
+			t.Fatalf("test%d: %v->%v: match: have cost=%d partial=%v, want cost=%d", i, from, goals, haveMatch.Cost, haveMatch.Partial, wantCost)
+		}
+		checkMultiGoalPath(t, g, l, from, haveMatch)
+	}
+}
+
+func TestBuildPathToAnySingleGoal(t *testing.T) {
+	// A single goal search goes through the generic search loop,
+	// while BuildPath may use a specialized one.
+	// Their results should be identical.
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	bfs := pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})
+	for i := 0; i < 300; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 80)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		goals := []pathing.GridCoord{to}
+
+		want := astar.BuildPath(g, from, to, l)
+		have := astar.BuildPathToAny(g, from, goals, l).BuildPathResult
+		if have != want {
+			t.Fatalf("test%d: astar %v->%v:\nhave: %+v\nwant: %+v", i, from, to, have, want)
+		}
+
+		want = bfs.BuildPath(g, from, to, l)
+		have = bfs.BuildPathToAny(g, from, goals, l).BuildPathResult
+		if have != want {
+			t.Fatalf("test%d: bfs %v->%v:\nhave: %+v\nwant: %+v", i, from, to, have, want)
+		}
+	}
+}
+
+func checkMultiGoalPath(t *testing.T, g *pathing.Grid, l pathing.GridLayer, from pathing.GridCoord, result pathing.BuildPathResult) {
+	t.Helper()
+
+	pos := from
+	steps := result.Steps
+	steps.Rewind()
+	for steps.HasNext() {
+		pos = pos.Move(steps.Next())
+		if g.GetCellCost(pos, l) == 0 {
+			t.Fatalf("%v: the path %s goes through %v", from, result.Steps, pos)
+		}
+	}
+	if pos != result.Finish {
+		t.Fatalf("%v: the path %s ends at %v instead of %v", from, result.Steps, pos, result.Finish)
+	}
+}
@@ -0,0 +1,310 @@
+package pathing
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// ErrPathServiceClosed is returned for the requests that were
+// submitted after the PathService was closed.
+var ErrPathServiceClosed = errors.New("pathing: path service is closed")
+
+// LongPathBuilder is implemented by the unbounded grid pathfinders
+// of this package (AStar and GreedyBFS).
+type LongPathBuilder interface {
+	BuildLongPath(g *Grid, from, to GridCoord, l GridLayer, dst *LongGridPath) BuildLongPathResult
+}
+
+// timeSlicedPathBuilder is implemented by the pathfinders
+// that can run their search step by step.
+// The service uses it to check the request cancellation during the search.
+type timeSlicedPathBuilder interface {
+	Start(g *Grid, from, to GridCoord, l GridLayer)
+	Step(maxNodes int) bool
+	Result(dst *LongGridPath) BuildLongPathResult
+}
+
+// PathService runs the path requests on a pool of worker goroutines.
+// You must use NewPathService() function to obtain an instance of this type.
+//
+// The pathfinder objects are not safe for concurrent use,
+// so every worker owns its own pathfinder instance.
+// All workers read the same Grid; while the service is running,
+// the grid should only be modified inside the UpdateGrid callback.
+//
+// The PathService methods are safe for concurrent use.
+type PathService struct {
+	grid   *Grid
+	gridMu sync.RWMutex
+
+	requests chan *pathJob
+	workers  sync.WaitGroup
+
+	closeMu sync.RWMutex
+	closed  bool
+
+	stepNodes    int
+	panicHandler func(v any)
+}
+
+type PathServiceConfig struct {
+	// NumWorkers is a number of the worker goroutines.
+	//
+	// If left unset (0), runtime.GOMAXPROCS(0) is used.
+	NumWorkers int
+
+	// QueueSize is a capacity of the pending requests queue.
+	// When the queue is full, Submit blocks.
+	//
+	// If left unset (0), a default value of 64 is used.
+	QueueSize int
+
+	// NewPathfinder is called once per worker to create its pathfinder.
+	//
+	// If left unset (nil), an AStar with default config is used.
+	NewPathfinder func() LongPathBuilder
+
+	// StepNodes is a number of nodes the pathfinder expands
+	// between the request cancellation checks.
+	// It's only used for the pathfinders that support
+	// the time-sliced search (see AStar.Step).
+	//
+	// If left unset (0), a default value of 256 is used.
+	StepNodes int
+
+	// PanicHandler is called with the recovered value
+	// if a SubmitFunc callback panics.
+	// The service keeps running after that.
+	//
+	// If left unset (nil), the callback panics are not recovered
+	// and they crash the program as usual.
+	PanicHandler func(v any)
+}
+
+// PathRequest describes a single path building task.
+type PathRequest struct {
+	From  GridCoord
+	To    GridCoord
+	Layer GridLayer
+}
+
+// PathResponse is a path request execution result.
+type PathResponse struct {
+	BuildLongPathResult
+
+	// Path is owned by the response receiver.
+	Path LongGridPath
+
+	// Err is non-nil if the request was not executed.
+	// It's either a context error or ErrPathServiceClosed.
+	Err error
+}
+
+// PathFuture is a handle to the submitted path request.
+type PathFuture struct {
+	done     chan struct{}
+	response PathResponse
+}
+
+// Done returns a channel that is closed when the response is ready.
+func (f *PathFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the response is ready and then returns it.
+func (f *PathFuture) Wait() PathResponse {
+	<-f.done
+	return f.response
+}
+
+type pathJob struct {
+	ctx          context.Context
+	request      PathRequest
+	future       *PathFuture
+	callback     func(PathResponse)
+	panicHandler func(v any)
+
+	// A job can be finished by both the worker and the cancellation watcher.
+	// Only the first response is used.
+	once sync.Once
+}
+
+func (job *pathJob) finish(resp PathResponse) {
+	finished := false
+	job.once.Do(func() {
+		finished = true
+		job.future.response = resp
+		close(job.future.done)
+	})
+	if finished && job.callback != nil {
+		job.runCallback(resp)
+	}
+}
+
+func (job *pathJob) runCallback(resp PathResponse) {
+	if job.panicHandler != nil {
+		defer func() {
+			if v := recover(); v != nil {
+				job.panicHandler(v)
+			}
+		}()
+	}
+	job.callback(resp)
+}
+
+func (job *pathJob) isFinished() bool {
+	select {
+	case <-job.future.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// watchContext finishes the job as soon as its context is done,
+// even if the job is still waiting in the queue.
+func (job *pathJob) watchContext() {
+	select {
+	case <-job.ctx.Done():
+		job.finish(PathResponse{Err: job.ctx.Err()})
+	case <-job.future.done:
+	}
+}
+
+// NewPathService creates a service for the given grid and starts its workers.
+// Use Close to stop them.
+func NewPathService(g *Grid, config PathServiceConfig) *PathService {
+	if config.NumWorkers <= 0 {
+		config.NumWorkers = runtime.GOMAXPROCS(0)
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 64
+	}
+	if config.NewPathfinder == nil {
+		config.NewPathfinder = func() LongPathBuilder {
+			return NewAStar(AStarConfig{})
+		}
+	}
+	if config.StepNodes <= 0 {
+		config.StepNodes = 256
+	}
+
+	s := &PathService{
+		grid:         g,
+		requests:     make(chan *pathJob, config.QueueSize),
+		stepNodes:    config.StepNodes,
+		panicHandler: config.PanicHandler,
+	}
+	s.workers.Add(config.NumWorkers)
+	for i := 0; i < config.NumWorkers; i++ {
+		go s.runWorker(config.NewPathfinder())
+	}
+	return s
+}
+
+// Submit adds the request to the queue and returns its future.
+//
+// The request is canceled if the ctx is done before the path is built;
+// the response Err is set to the context error in this case.
+// The future is completed right away, even if the request is still in the queue.
+// If the queue is full, Submit blocks until there is a free slot or the ctx is done.
+func (s *PathService) Submit(ctx context.Context, req PathRequest) *PathFuture {
+	return s.submit(ctx, req, nil)
+}
+
+// SubmitFunc is like Submit, but the callback is called with the response.
+// The callback is executed by the worker goroutine (or by the caller goroutine
+// if the request is rejected right away), so it should not block for long.
+// A canceled request callback is executed by a separate goroutine.
+//
+// See PathServiceConfig.PanicHandler to learn how the callback panics are handled.
+func (s *PathService) SubmitFunc(ctx context.Context, req PathRequest, callback func(PathResponse)) {
+	s.submit(ctx, req, callback)
+}
+
+func (s *PathService) submit(ctx context.Context, req PathRequest, callback func(PathResponse)) *PathFuture {
+	job := &pathJob{
+		ctx:          ctx,
+		request:      req,
+		future:       &PathFuture{done: make(chan struct{})},
+		callback:     callback,
+		panicHandler: s.panicHandler,
+	}
+
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		job.finish(PathResponse{Err: ErrPathServiceClosed})
+		return job.future
+	}
+	select {
+	case s.requests <- job:
+		if ctx.Done() != nil {
+			go job.watchContext()
+		}
+	case <-ctx.Done():
+		job.finish(PathResponse{Err: ctx.Err()})
+	}
+	return job.future
+}
+
+// UpdateGrid runs f while no path requests are being executed.
+// Use it to modify the shared grid while the service is running.
+func (s *PathService) UpdateGrid(f func(g *Grid)) {
+	s.gridMu.Lock()
+	defer s.gridMu.Unlock()
+	f(s.grid)
+}
+
+// Close stops accepting new requests and waits until
+// all workers finish the already queued ones.
+// It's safe to call Close several times.
+func (s *PathService) Close() {
+	s.closeMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.requests)
+	}
+	s.closeMu.Unlock()
+
+	s.workers.Wait()
+}
+
+func (s *PathService) runWorker(pathfinder LongPathBuilder) {
+	defer s.workers.Done()
+
+	sliced, _ := pathfinder.(timeSlicedPathBuilder)
+	for job := range s.requests {
+		if job.isFinished() {
+			// Canceled while it was in the queue.
+			continue
+		}
+		if err := job.ctx.Err(); err != nil {
+			job.finish(PathResponse{Err: err})
+			continue
+		}
+		var resp PathResponse
+		s.gridMu.RLock()
+		if sliced != nil {
+			resp.BuildLongPathResult, resp.Err = s.buildSliced(job, sliced, &resp.Path)
+		} else {
+			resp.BuildLongPathResult = pathfinder.BuildLongPath(s.grid, job.request.From, job.request.To, job.request.Layer, &resp.Path)
+		}
+		s.gridMu.RUnlock()
+		job.finish(resp)
+	}
+}
+
+func (s *PathService) buildSliced(job *pathJob, pathfinder timeSlicedPathBuilder, dst *LongGridPath) (BuildLongPathResult, error) {
+	req := job.request
+	pathfinder.Start(s.grid, req.From, req.To, req.Layer)
+	for !pathfinder.Step(s.stepNodes) {
+		if err := job.ctx.Err(); err != nil {
+			return BuildLongPathResult{}, err
+		}
+	}
+	return pathfinder.Result(dst), nil
+}
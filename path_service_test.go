@@ -0,0 +1,218 @@
+package pathing_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestPathService(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	g, numCols, numRows := testRandomGrid(rng, 60)
+
+	configs := []pathing.PathServiceConfig{
+		{NumWorkers: 4},
+		{NumWorkers: 2, QueueSize: 1, StepNodes: 3},
+		{
+			NumWorkers: 3,
+			NewPathfinder: func() pathing.LongPathBuilder {
+				return pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})
+			},
+		},
+	}
+	for i, config := range configs {
+		var want pathing.LongPathBuilder = pathing.NewAStar(pathing.AStarConfig{})
+		if config.NewPathfinder != nil {
+			want = config.NewPathfinder()
+		}
+
+		service := pathing.NewPathService(g, config)
+		requests := make([]pathing.PathRequest, 50)
+		futures := make([]*pathing.PathFuture, len(requests))
+		for j := range requests {
+			requests[j] = pathing.PathRequest{
+				From:  pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)},
+				To:    pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)},
+				Layer: l,
+			}
+			futures[j] = service.Submit(context.Background(), requests[j])
+		}
+
+		var wantPath pathing.LongGridPath
+		for j, f := range futures {
+			resp := f.Wait()
+			if resp.Err != nil {
+				t.Fatalf("config%d: request%d: unexpected error: %v", i, j, resp.Err)
+			}
+			req := requests[j]
+			wantResult := want.BuildLongPath(g, req.From, req.To, l, &wantPath)
+			if resp.BuildLongPathResult != wantResult {
+				t.Fatalf("config%d: request%d: result mismatch:\nhave: %+v\nwant: %+v", i, j, resp.BuildLongPathResult, wantResult)
+			}
+			if resp.Path.String() != wantPath.String() {
+				t.Fatalf("config%d: request%d: path mismatch:\nhave: %v\nwant: %v", i, j, resp.Path.String(), wantPath.String())
+			}
+		}
+		service.Close()
+	}
+}
+
+func TestPathServiceCancel(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 100, WorldHeight: 32 * 100})
+	service := pathing.NewPathService(g, pathing.PathServiceConfig{NumWorkers: 1})
+	defer service.Close()
+
+	req := pathing.PathRequest{
+		From:  pathing.GridCoord{X: 0, Y: 0},
+		To:    pathing.GridCoord{X: 99, Y: 99},
+		Layer: l,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	resp := service.Submit(ctx, req).Wait()
+	if !errors.Is(resp.Err, context.Canceled) {
+		t.Fatalf("expected a canceled request, got %v", resp.Err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	service.SubmitFunc(context.Background(), req, func(resp pathing.PathResponse) {
+		defer wg.Done()
+		if resp.Err != nil || resp.Partial || resp.Finish != req.To {
+			t.Errorf("unexpected callback response: %+v (err=%v)", resp.BuildLongPathResult, resp.Err)
+		}
+	})
+	wg.Wait()
+}
+
+func TestPathServiceCancelQueued(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 30, WorldHeight: 32 * 30})
+	service := pathing.NewPathService(g, pathing.PathServiceConfig{NumWorkers: 1})
+	defer service.Close()
+
+	req := pathing.PathRequest{
+		From:  pathing.GridCoord{X: 0, Y: 0},
+		To:    pathing.GridCoord{X: 29, Y: 29},
+		Layer: l,
+	}
+
+	// Keep the only worker busy: it can't run the request
+	// while the grid is being updated.
+	updating := make(chan struct{})
+	release := make(chan struct{})
+	go service.UpdateGrid(func(g *pathing.Grid) {
+		close(updating)
+		<-release
+	})
+	<-updating
+	busy := service.Submit(context.Background(), req)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	queued := service.Submit(ctx, req)
+	callbackErr := make(chan error, 1)
+	service.SubmitFunc(ctx, req, func(resp pathing.PathResponse) {
+		callbackErr <- resp.Err
+	})
+	cancel()
+
+	select {
+	case <-queued.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the canceled request future is not completed")
+	}
+	if resp := queued.Wait(); !errors.Is(resp.Err, context.Canceled) {
+		t.Fatalf("expected a canceled request, got %v", resp.Err)
+	}
+	select {
+	case err := <-callbackErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected a canceled callback request, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the canceled request callback is not called")
+	}
+
+	close(release)
+	if resp := busy.Wait(); resp.Err != nil || resp.Finish != req.To {
+		t.Fatalf("unexpected response: %+v (err=%v)", resp.BuildLongPathResult, resp.Err)
+	}
+}
+
+func TestPathServicePanicHandler(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 30, WorldHeight: 32 * 30})
+	panics := make(chan any, 10)
+	service := pathing.NewPathService(g, pathing.PathServiceConfig{
+		NumWorkers: 1,
+		PanicHandler: func(v any) {
+			panics <- v
+		},
+	})
+
+	req := pathing.PathRequest{
+		From:  pathing.GridCoord{X: 0, Y: 0},
+		To:    pathing.GridCoord{X: 29, Y: 29},
+		Layer: l,
+	}
+	for i := 0; i < 3; i++ {
+		service.SubmitFunc(context.Background(), req, func(resp pathing.PathResponse) {
+			panic("callback panic")
+		})
+	}
+	// The worker is still alive after the callback panics.
+	if resp := service.Submit(context.Background(), req).Wait(); resp.Err != nil || resp.Finish != req.To {
+		t.Fatalf("unexpected response: %+v (err=%v)", resp.BuildLongPathResult, resp.Err)
+	}
+	service.Close()
+
+	if len(panics) != 3 {
+		t.Fatalf("expected 3 recovered panics, got %d", len(panics))
+	}
+	if v := <-panics; v != "callback panic" {
+		t.Fatalf("unexpected panic value: %v", v)
+	}
+}
+
+func TestPathServiceUpdateGrid(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := pathing.NewGrid(pathing.GridConfig{WorldWidth: 32 * 30, WorldHeight: 32 * 30})
+	service := pathing.NewPathService(g, pathing.PathServiceConfig{NumWorkers: 4})
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	futures := make([]*pathing.PathFuture, 0, 200)
+	for i := 0; i < cap(futures); i++ {
+		if i%10 == 0 {
+			c := pathing.GridCoord{X: rng.Intn(30), Y: rng.Intn(30)}
+			service.UpdateGrid(func(g *pathing.Grid) {
+				g.SetCellTile(c, uint8(rng.Intn(4)))
+			})
+		}
+		req := pathing.PathRequest{
+			From:  pathing.GridCoord{X: rng.Intn(30), Y: rng.Intn(30)},
+			To:    pathing.GridCoord{X: rng.Intn(30), Y: rng.Intn(30)},
+			Layer: l,
+		}
+		futures = append(futures, service.Submit(context.Background(), req))
+	}
+	for i, f := range futures {
+		if resp := f.Wait(); resp.Err != nil {
+			t.Fatalf("request%d: unexpected error: %v", i, resp.Err)
+		}
+	}
+
+	service.Close()
+	service.Close()
+	resp := service.Submit(context.Background(), pathing.PathRequest{Layer: l}).Wait()
+	if resp.Err != pathing.ErrPathServiceClosed {
+		t.Fatalf("expected ErrPathServiceClosed, got %v", resp.Err)
+	}
+}
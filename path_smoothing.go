@@ -0,0 +1,61 @@
+package pathing
+
+// Pos is a world position.
+// Use Grid.CoordToPos and Grid.PosToCoord to map it to the grid cells.
+type Pos struct {
+	X float64
+	Y float64
+}
+
+// SmoothPath turns a grid path into a minimal list of waypoints
+// by removing the redundant path corners (this is also known as string pulling).
+// Every pair of consecutive waypoints has a clear line of sight,
+// see Grid.HasLineOfSight.
+//
+// The waypoints are the world positions of the cell centers.
+// The first waypoint is the path start and the last one is the path finish.
+// The waypoints are appended to dst and the extended slice is returned.
+//
+// The layer is only used to check whether a cell is passable:
+// the smoothed path may go through the cells that are more expensive
+// than the original path cells.
+func SmoothPath(g *Grid, result BuildPathResult, l GridLayer, dst []Pos) []Pos {
+	// The path start is not stored in the result, but it
+	// can be found by taking all steps in the reversed order.
+	var cells [gridPathMaxLen + 1]GridCoord
+	n := result.Steps.Len()
+	if n > gridPathMaxLen {
+		// Only MakeGridPath can create such path;
+		// its extra steps are not stored anyway.
+		n = gridPathMaxLen
+	}
+	cells[n] = result.Finish
+	for i := n - 1; i >= 0; i-- {
+		cells[i] = cells[i+1].reversedMove(result.Steps.get(byte(n - 1 - i)))
+	}
+	return smoothCells(g, cells[:n+1], l, dst)
+}
+
+func smoothCells(g *Grid, cells []GridCoord, l GridLayer, dst []Pos) []Pos {
+	dst = appendCellPos(g, dst, cells[0])
+	if len(cells) == 1 {
+		return dst
+	}
+
+	anchor := 0
+	for i := anchor + 2; i < len(cells); i++ {
+		if g.HasLineOfSight(cells[anchor], cells[i], l) {
+			continue
+		}
+		// The previous cell is the last one that is visible from the anchor.
+		anchor = i - 1
+		dst = appendCellPos(g, dst, cells[anchor])
+	}
+
+	return appendCellPos(g, dst, cells[len(cells)-1])
+}
+
+func appendCellPos(g *Grid, dst []Pos, c GridCoord) []Pos {
+	x, y := g.CoordToPos(c)
+	return append(dst, Pos{X: x, Y: y})
+}
@@ -0,0 +1,158 @@
+package pathing_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestSmoothPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         []string
+		steps     []pathing.Direction
+		waypoints []pathing.GridCoord
+	}{
+		{
+			name: "straight",
+			m: []string{
+				"A.....",
+			},
+			steps:     []pathing.Direction{pathing.DirRight, pathing.DirRight, pathing.DirRight},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 3, Y: 0}},
+		},
+		{
+			name: "staircase",
+			m: []string{
+				"A......",
+				".......",
+				".......",
+			},
+			steps: []pathing.Direction{
+				pathing.DirRight, pathing.DirRight, pathing.DirDown, pathing.DirRight,
+				pathing.DirRight, pathing.DirDown, pathing.DirRight, pathing.DirRight,
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 6, Y: 2}},
+		},
+		{
+			name: "corner",
+			m: []string{
+				"A.....",
+				"xxxx..",
+				"xxxx..",
+			},
+			steps: []pathing.Direction{
+				pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight,
+				pathing.DirDown, pathing.DirRight, pathing.DirDown,
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 5, Y: 2}},
+		},
+		{
+			name: "two_corners",
+			m: []string{
+				"A.......",
+				"x.xxxxx.",
+				"x.......",
+				"xxxxxx..",
+			},
+			steps: []pathing.Direction{
+				pathing.DirRight, pathing.DirDown, pathing.DirDown,
+				pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight, pathing.DirRight,
+				pathing.DirDown,
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 2}, {X: 6, Y: 2}, {X: 6, Y: 3}},
+		},
+		{
+			name: "empty",
+			m: []string{
+				"...",
+				".A.",
+				"...",
+			},
+			waypoints: []pathing.GridCoord{{X: 1, Y: 1}},
+		},
+	}
+
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parseResult := testParseGrid(t, test.m)
+			g := parseResult.grid
+			finish := parseResult.start
+			for _, d := range test.steps {
+				finish = finish.Move(d)
+			}
+			result := pathing.BuildPathResult{
+				Steps:  pathing.MakeGridPath(test.steps...),
+				Finish: finish,
+			}
+			waypoints := pathing.SmoothPath(g, result, l, nil)
+			if len(waypoints) != len(test.waypoints) {
+				t.Fatalf("waypoints count mismatch:\nhave: %v\nwant: %v", waypoints, test.waypoints)
+			}
+			for i, c := range test.waypoints {
+				x, y := g.CoordToPos(c)
+				if waypoints[i] != (pathing.Pos{X: x, Y: y}) {
+					t.Fatalf("waypoint[%d] mismatch:\nhave: %v\nwant: %v", i, waypoints[i], c)
+				}
+			}
+
+			buf := make([]pathing.Pos, 0, 8)
+			allocs := testing.AllocsPerRun(10, func() {
+				pathing.SmoothPath(g, result, l, buf[:0])
+			})
+			if allocs != 0 {
+				t.Fatalf("expected zero allocations, got %v", allocs)
+			}
+		})
+	}
+}
+
+func TestSmoothPathMaxLen(t *testing.T) {
+	// A goal that is one step beyond the max path length is not reachable.
+	// The partial path is as long as possible, so its every step is stored.
+	const dist = 57
+	m := []string{
+		strings.Repeat("x", dist+3),
+		"x" + strings.Repeat(".", dist+1) + "x",
+		strings.Repeat("x", dist+3),
+	}
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	parseResult := testParseGrid(t, m)
+	g := parseResult.grid
+	from := pathing.GridCoord{X: 1, Y: 1}
+	cellPos := func(c pathing.GridCoord) pathing.Pos {
+		x, y := g.CoordToPos(c)
+		return pathing.Pos{X: x, Y: y}
+	}
+
+	pathfinders := []struct {
+		name string
+		impl interface {
+			BuildPath(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer) pathing.BuildPathResult
+		}
+	}{
+		{"astar", pathing.NewAStar(pathing.AStarConfig{})},
+		{"greedy_bfs", pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})},
+	}
+	for _, pathfinder := range pathfinders {
+		for _, to := range []pathing.GridCoord{{X: from.X + dist - 1, Y: 1}, {X: from.X + dist, Y: 1}} {
+			result := pathfinder.impl.BuildPath(g, from, to, l)
+			wantPartial := to.X-from.X > 56
+			if result.Partial != wantPartial {
+				t.Fatalf("%s: %v=>%v partial flag mismatch: have %v, want %v", pathfinder.name, from, to, result.Partial, wantPartial)
+			}
+			if result.Steps.Len() != 56 {
+				t.Fatalf("%s: %v=>%v path length mismatch: have %d, want 56", pathfinder.name, from, to, result.Steps.Len())
+			}
+			waypoints := pathing.SmoothPath(g, result, l, nil)
+			wantWaypoints := []pathing.Pos{cellPos(from), cellPos(result.Finish)}
+			if !reflect.DeepEqual(waypoints, wantWaypoints) {
+				t.Fatalf("%s: %v=>%v waypoints mismatch:\nhave: %v\nwant: %v", pathfinder.name, from, to, waypoints, wantWaypoints)
+			}
+		}
+	}
+}
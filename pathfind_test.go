@@ -3,6 +3,7 @@ package pathing_test
 import (
 	"bytes"
 	"fmt"
+	"math/rand"
 	"strings"
 	"testing"
 
@@ -247,3 +248,32 @@ type pathfindTestCase struct {
 	partial bool
 	bench   bool
 }
+
+type testTileSetter interface {
+	SetCellTile(c pathing.GridCoord, tileTag uint8)
+}
+
+// testRandomGrid creates a grid of up to maxSize+1 cells per side
+// with some of its cells set to a random tile tag (see testRandomTiles).
+func testRandomGrid(rng *rand.Rand, maxSize int) (*pathing.Grid, int, int) {
+	numCols := rng.Intn(maxSize) + 2
+	numRows := rng.Intn(maxSize) + 2
+	g := pathing.NewGrid(pathing.GridConfig{
+		WorldWidth:  uint(numCols) * 32,
+		WorldHeight: uint(numRows) * 32,
+	})
+	testRandomTiles(rng, g, numCols, numRows)
+	return g, numCols, numRows
+}
+
+// testRandomTiles sets up to 35% of the grid cells to a random [0-3] tile tag.
+func testRandomTiles(rng *rand.Rand, g testTileSetter, numCols, numRows int) {
+	density := rng.Float64() * 0.35
+	for y := 0; y < numRows; y++ {
+		for x := 0; x < numCols; x++ {
+			if rng.Float64() < density {
+				g.SetCellTile(pathing.GridCoord{X: x, Y: y}, uint8(rng.Intn(4)))
+			}
+		}
+	}
+}
@@ -47,9 +47,11 @@ func (q *priorityQueue[T]) IsEmpty() bool {
 
 func (q *priorityQueue[T]) Push(priority int, value T) {
 	// No bound checks since compiler knows that i will never exceed 64.
-	// We also get a cool truncation of values above 64 to store them
-	// in our biggest bucket.
-	i := uint(priority) & 0b111111
+	// The values above 63 are stored in our biggest bucket.
+	i := uint(priority)
+	if i > 63 {
+		i = 63
+	}
 	q.buckets[i] = append(q.buckets[i], value)
 	q.mask |= 1 << i
 }
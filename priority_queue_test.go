@@ -79,6 +79,23 @@ func TestPriorityQueue(t *testing.T) {
 		ensureEmpty(t, q)
 	}
 
+	{
+		// The priorities above 63 should not wrap around into the low buckets.
+		q := newPriorityQueue[int]()
+		q.Push(100, 100)
+		q.Push(64, 64)
+		q.Push(10, 10)
+		if v := q.Pop(); v != 10 {
+			t.Fatalf("expected 10 to be popped first, got %d", v)
+		}
+		for !q.IsEmpty() {
+			if v := q.Pop(); v < 63 {
+				t.Fatalf("unexpected %d value", v)
+			}
+		}
+		ensureEmpty(t, q)
+	}
+
 	for i := 0; i < 64; i++ {
 		r := rand.New(rand.NewSource(time.Now().Unix()))
 		var values []int
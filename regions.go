@@ -0,0 +1,278 @@
+package pathing
+
+// Regions labels the connected components of the grid.
+// You must use NewRegions() function to obtain an instance of this type.
+//
+// Two passable cells belong to the same region if there is a path between them.
+// It can be used to check whether a destination is reachable
+// before building a path: a failed BuildPath explores every reachable cell
+// before returning a partial result.
+//
+// The cells are connected in 4 directions. The diagonal movement
+// can't connect the cells that are not connected already, since
+// a diagonal step requires at least one passable adjacent axial cell.
+//
+// The index is bound to a specific Grid and GridLayer pair.
+// It needs 4 bytes per Grid cell.
+//
+// When the grid is changed (e.g. SetCellTile or SetCellIsBlocked are called),
+// use NotifyCellChanged and Update methods to recompute the affected regions.
+type Regions struct {
+	grid  *Grid
+	layer GridLayer
+
+	numCols int
+	numRows int
+
+	// labels are the cell region IDs; 0 is used for the impassable cells.
+	labels []uint32
+
+	// sizes are indexed by the region ID.
+	// The free IDs are re-used when the new regions are created.
+	sizes   []int
+	freeIDs []uint32
+
+	queue   []uint32
+	changed []GridCoord
+}
+
+// NewRegions creates a regions index for the given grid and layer.
+func NewRegions(g *Grid, l GridLayer) *Regions {
+	r := &Regions{
+		grid:    g,
+		layer:   l,
+		numCols: int(g.numCols),
+		numRows: int(g.numRows),
+		labels:  make([]uint32, g.numCols*g.numRows),
+	}
+	r.Rebuild()
+	return r
+}
+
+// Rebuild labels the regions of the entire grid.
+// It's an O(n) operation, where n is a number of grid cells.
+//
+// Note that the grid size is not expected to change.
+func (r *Regions) Rebuild() {
+	r.changed = r.changed[:0]
+	r.freeIDs = r.freeIDs[:0]
+	// The ID 0 is reserved for the impassable cells.
+	r.sizes = append(r.sizes[:0], 0)
+	for i := range r.labels {
+		r.labels[i] = 0
+	}
+	for i := range r.labels {
+		if r.labels[i] != 0 || !r.isPassable(i) {
+			continue
+		}
+		id := r.newRegion()
+		r.sizes[id] = r.fill(i, 0, id)
+	}
+}
+
+// Region returns the region ID of the given cell.
+// The impassable and out-of-bounds cells have an ID of 0.
+//
+// The IDs are only stable until the next Update or Rebuild call.
+func (r *Regions) Region(c GridCoord) int {
+	if uint(c.X) >= uint(r.numCols) || uint(c.Y) >= uint(r.numRows) {
+		return 0
+	}
+	return int(r.labels[c.Y*r.numCols+c.X])
+}
+
+// SameRegion reports whether there is a path between the two cells.
+// It's always false if any of the cells is impassable.
+func (r *Regions) SameRegion(a, b GridCoord) bool {
+	id := r.Region(a)
+	return id != 0 && id == r.Region(b)
+}
+
+// NumRegions returns the number of the grid regions.
+func (r *Regions) NumRegions() int {
+	return len(r.sizes) - 1 - len(r.freeIDs)
+}
+
+// NotifyCellChanged marks the cell as changed.
+// The regions are not updated until Update is called.
+//
+// Call this method after the cell tile or its blocked bit are changed.
+func (r *Regions) NotifyCellChanged(c GridCoord) {
+	if uint(c.X) >= uint(r.numCols) || uint(c.Y) >= uint(r.numRows) {
+		return
+	}
+	r.changed = append(r.changed, c)
+}
+
+// Update recomputes the regions affected by the changed cells.
+//
+// A cell that becomes passable merges the adjacent regions,
+// the smaller regions are relabeled.
+// A cell that becomes impassable may split its region;
+// the search stops as soon as all of its neighbors are found to be connected,
+// so it's usually cheap unless the region is actually split.
+func (r *Regions) Update() {
+	for _, c := range r.changed {
+		i := c.Y*r.numCols + c.X
+		passable := r.isPassable(i)
+		if passable == (r.labels[i] != 0) {
+			continue
+		}
+		if passable {
+			r.addCell(c, i)
+		} else {
+			r.removeCell(c, i)
+		}
+	}
+	r.changed = r.changed[:0]
+}
+
+func (r *Regions) addCell(c GridCoord, i int) {
+	// The biggest adjacent region absorbs the others.
+	var id uint32
+	for _, offset := range neighborOffsets[:4] {
+		if other := uint32(r.Region(c.Add(offset))); other != 0 && r.sizes[other] > r.sizes[id] {
+			id = other
+		}
+	}
+	if id == 0 {
+		id = r.newRegion()
+	}
+	r.labels[i] = id
+	r.sizes[id]++
+	for _, offset := range neighborOffsets[:4] {
+		next := c.Add(offset)
+		other := uint32(r.Region(next))
+		if other == 0 || other == id {
+			continue
+		}
+		r.sizes[id] += r.fill(next.Y*r.numCols+next.X, other, id)
+		r.freeRegion(other)
+	}
+}
+
+func (r *Regions) removeCell(c GridCoord, i int) {
+	id := r.labels[i]
+	r.labels[i] = 0
+	r.sizes[id]--
+
+	var neighbors [4]int
+	numNeighbors := 0
+	for _, offset := range neighborOffsets[:4] {
+		next := c.Add(offset)
+		if r.Region(next) == int(id) {
+			neighbors[numNeighbors] = next.Y*r.numCols + next.X
+			numNeighbors++
+		}
+	}
+	if numNeighbors == 0 {
+		r.freeRegion(id)
+		return
+	}
+
+	// Try to reach all other neighbors from the every neighbor.
+	// If that succeeds, the region is not split.
+	// Otherwise, the visited part becomes a new region.
+	for k := 0; k < numNeighbors-1; k++ {
+		start := neighbors[k]
+		if r.labels[start] != id {
+			// Already moved to a new region.
+			continue
+		}
+		newID := r.newRegion()
+		if r.fillUntilFound(start, id, newID, neighbors[k+1:numNeighbors]) {
+			// Connected: undo the relabeling.
+			for _, j := range r.queue {
+				r.labels[j] = id
+			}
+			r.freeRegion(newID)
+			return
+		}
+		r.sizes[newID] = len(r.queue)
+		r.sizes[id] -= len(r.queue)
+	}
+}
+
+// fill relabels the region that contains the cell i.
+// It returns the number of relabeled cells.
+func (r *Regions) fill(i int, from, to uint32) int {
+	r.queue = append(r.queue[:0], uint32(i))
+	r.labels[i] = to
+	for k := 0; k < len(r.queue); k++ {
+		r.pushNeighbors(int(r.queue[k]), from, to)
+	}
+	return len(r.queue)
+}
+
+// fillUntilFound is like fill, but it stops as soon
+// as all of the targets cells are relabeled.
+// It reports whether the targets were reached.
+//
+// The relabeled cells are left in the r.queue.
+func (r *Regions) fillUntilFound(i int, from, to uint32, targets []int) bool {
+	r.queue = append(r.queue[:0], uint32(i))
+	r.labels[i] = to
+	for k := 0; k < len(r.queue); k++ {
+		r.pushNeighbors(int(r.queue[k]), from, to)
+		found := true
+		for _, j := range targets {
+			if r.labels[j] == from {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Regions) pushNeighbors(i int, from, to uint32) {
+	x := i % r.numCols
+	y := i / r.numCols
+	if x+1 < r.numCols {
+		r.push(i+1, from, to)
+	}
+	if x > 0 {
+		r.push(i-1, from, to)
+	}
+	if y+1 < r.numRows {
+		r.push(i+r.numCols, from, to)
+	}
+	if y > 0 {
+		r.push(i-r.numCols, from, to)
+	}
+}
+
+func (r *Regions) push(i int, from, to uint32) {
+	if r.labels[i] != from {
+		return
+	}
+	if from == 0 && !r.isPassable(i) {
+		return
+	}
+	r.labels[i] = to
+	r.queue = append(r.queue, uint32(i))
+}
+
+func (r *Regions) newRegion() uint32 {
+	if n := len(r.freeIDs); n != 0 {
+		id := r.freeIDs[n-1]
+		r.freeIDs = r.freeIDs[:n-1]
+		return id
+	}
+	r.sizes = append(r.sizes, 0)
+	return uint32(len(r.sizes) - 1)
+}
+
+func (r *Regions) freeRegion(id uint32) {
+	r.sizes[id] = 0
+	r.freeIDs = append(r.freeIDs, id)
+}
+
+func (r *Regions) isPassable(i int) bool {
+	x := uint(i % r.numCols)
+	y := uint(i / r.numCols)
+	return r.grid.getCellCost(x, y, r.layer) != 0
+}
@@ -0,0 +1,150 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkRegionsUpdate(b *testing.B) {
+	parseResult := testParseGrid(b, []string{
+		"................................",
+		"................................",
+		"........x.......................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+		"................................",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	r := pathing.NewRegions(g, l)
+	c := pathing.GridCoord{X: 20, Y: 12}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.SetCellIsBlocked(c, i%2 == 0)
+		r.NotifyCellChanged(c)
+		r.Update()
+	}
+}
+
+func TestRegions(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"..x...",
+		"..x.x.",
+		"xxx.x.",
+		"....x.",
+	})
+	g := parseResult.grid
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	r := pathing.NewRegions(g, l)
+
+	if n := r.NumRegions(); n != 2 {
+		t.Fatalf("expected 2 regions, got %d", n)
+	}
+
+	tests := []struct {
+		a, b pathing.GridCoord
+		want bool
+	}{
+		{pathing.GridCoord{X: 0, Y: 0}, pathing.GridCoord{X: 1, Y: 1}, true},
+		{pathing.GridCoord{X: 0, Y: 0}, pathing.GridCoord{X: 3, Y: 0}, false},
+		{pathing.GridCoord{X: 3, Y: 0}, pathing.GridCoord{X: 0, Y: 3}, true},
+		{pathing.GridCoord{X: 5, Y: 3}, pathing.GridCoord{X: 0, Y: 3}, true},
+		{pathing.GridCoord{X: 2, Y: 0}, pathing.GridCoord{X: 2, Y: 0}, false},
+		{pathing.GridCoord{X: 0, Y: 0}, pathing.GridCoord{X: -1, Y: 0}, false},
+	}
+	check := func() {
+		t.Helper()
+		for _, test := range tests {
+			if have := r.SameRegion(test.a, test.b); have != test.want {
+				t.Fatalf("SameRegion(%v, %v): have %v, want %v", test.a, test.b, have, test.want)
+			}
+		}
+	}
+	check()
+
+	// Split the bigger region: the right column becomes
+	// a separate region and its top cell is isolated.
+	wall := pathing.GridCoord{X: 4, Y: 3}
+	g.SetCellTile(pathing.GridCoord{X: 5, Y: 0}, 1)
+	r.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 0})
+	g.SetCellIsBlocked(pathing.GridCoord{X: 5, Y: 2}, true)
+	r.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 2})
+	r.Update()
+	tests[3].want = false
+	check()
+	if n := r.NumRegions(); n != 4 {
+		t.Fatalf("expected 4 regions, got %d", n)
+	}
+
+	// Merge the left regions and the right column parts.
+	g.SetCellTile(pathing.GridCoord{X: 2, Y: 0}, 0)
+	r.NotifyCellChanged(pathing.GridCoord{X: 2, Y: 0})
+	g.SetCellIsBlocked(pathing.GridCoord{X: 5, Y: 2}, false)
+	r.NotifyCellChanged(pathing.GridCoord{X: 5, Y: 2})
+	r.Update()
+	tests[1].want = true
+	tests[4].want = true
+	check()
+	if !r.SameRegion(pathing.GridCoord{X: 5, Y: 1}, pathing.GridCoord{X: 5, Y: 3}) {
+		t.Fatalf("expected the right column to be connected")
+	}
+	if n := r.NumRegions(); n != 2 {
+		t.Fatalf("expected 2 regions, got %d", n)
+	}
+	if r.Region(wall) != 0 {
+		t.Fatalf("expected %v to be impassable", wall)
+	}
+}
+
+func TestRegionsUpdate(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for i := 0; i < 200; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 40)
+		r := pathing.NewRegions(g, l)
+		for j := 0; j < 10; j++ {
+			numChanges := rng.Intn(5) + 1
+			for k := 0; k < numChanges; k++ {
+				c := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+				g.SetCellIsBlocked(c, !g.GetCellIsBlocked(c))
+				r.NotifyCellChanged(c)
+			}
+			r.Update()
+
+			want := pathing.NewRegions(g, l)
+			if r.NumRegions() != want.NumRegions() {
+				t.Fatalf("test%d: regions count mismatch: have %d, want %d", i, r.NumRegions(), want.NumRegions())
+			}
+			// The IDs can be different, but the partitioning should be the same.
+			mapping := make(map[int]int)
+			for y := 0; y < numRows; y++ {
+				for x := 0; x < numCols; x++ {
+					c := pathing.GridCoord{X: x, Y: y}
+					haveID := r.Region(c)
+					wantID := want.Region(c)
+					if (haveID == 0) != (wantID == 0) {
+						t.Fatalf("test%d: %v passability mismatch", i, c)
+					}
+					if id, ok := mapping[haveID]; ok && id != wantID {
+						t.Fatalf("test%d: %v region mismatch", i, c)
+					}
+					mapping[haveID] = wantID
+				}
+			}
+		}
+	}
+}
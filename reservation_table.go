@@ -0,0 +1,103 @@
+package pathing
+
+// ReservationTable stores the space-time cell reservations.
+// You must use NewReservationTable() function to obtain an instance of this type.
+//
+// Every reservation is a (cell, tick) pair that belongs to some agent.
+// The agent IDs are arbitrary integers provided by the caller.
+//
+// The CooperativeAStar uses this table to plan the agent's path
+// around the cells reserved by the other agents.
+// The tick is a discrete time step; a single path step takes exactly 1 tick.
+type ReservationTable struct {
+	cells map[reservationKey]int
+}
+
+type reservationKey struct {
+	coord GridCoord
+	tick  int
+}
+
+// NewReservationTable creates an empty reservation table.
+func NewReservationTable() *ReservationTable {
+	return &ReservationTable{
+		cells: make(map[reservationKey]int),
+	}
+}
+
+// Reset removes all reservations.
+func (t *ReservationTable) Reset() {
+	for k := range t.cells {
+		delete(t.cells, k)
+	}
+}
+
+// Reserve marks the cell as occupied by the agent during the tick.
+// It reports whether the reservation succeeded: it fails if
+// the cell is already reserved by another agent.
+func (t *ReservationTable) Reserve(agent int, c GridCoord, tick int) bool {
+	k := reservationKey{coord: c, tick: tick}
+	if other, ok := t.cells[k]; ok && other != agent {
+		return false
+	}
+	t.cells[k] = agent
+	return true
+}
+
+// ReservePath reserves all cells of the path for the agent.
+// The from cell is reserved for the tick, the first path step
+// is reserved for tick+1 and so on.
+// A DirNone step (a wait action) reserves the same cell again.
+//
+// The path iterator state is not affected.
+//
+// The last path cell is not reserved past the path end.
+// If the agent is going to stay there, use Reserve to
+// keep it occupied for the next ticks.
+//
+// It reports whether all reservations succeeded.
+// The conflicting cells are left reserved by their original owners.
+func (t *ReservationTable) ReservePath(agent int, from GridCoord, tick int, path *LongGridPath) bool {
+	ok := t.Reserve(agent, from, tick)
+	pos := from
+	for i, b := range path.bytes {
+		pos = pos.Move(Direction(b))
+		if !t.Reserve(agent, pos, tick+i+1) {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Release removes all reservations of the agent.
+// It's an O(n) operation, where n is a number of reservations.
+func (t *ReservationTable) Release(agent int) {
+	for k, owner := range t.cells {
+		if owner == agent {
+			delete(t.cells, k)
+		}
+	}
+}
+
+// ReservedBy returns the agent that reserved the cell during the tick.
+// The second result is false if the cell is free.
+func (t *ReservationTable) ReservedBy(c GridCoord, tick int) (int, bool) {
+	agent, ok := t.cells[reservationKey{coord: c, tick: tick}]
+	return agent, ok
+}
+
+func (t *ReservationTable) reservedByOther(agent int, c GridCoord, tick int) bool {
+	other, ok := t.cells[reservationKey{coord: c, tick: tick}]
+	return ok && other != agent
+}
+
+// isSwap reports whether moving from a to b during the tick
+// makes the agent pass through another agent that moves from b to a.
+func (t *ReservationTable) isSwap(agent int, a, b GridCoord, tick int) bool {
+	other, ok := t.cells[reservationKey{coord: b, tick: tick}]
+	if !ok || other == agent {
+		return false
+	}
+	next, ok := t.cells[reservationKey{coord: a, tick: tick + 1}]
+	return ok && next == other
+}
@@ -0,0 +1,187 @@
+package pathing
+
+// PathBuilder is implemented by all bounded pathfinders
+// of this package (AStar, GreedyBFS and JPS).
+type PathBuilder interface {
+	BuildPath(g *Grid, from, to GridCoord, l GridLayer) BuildPathResult
+}
+
+// RouteBuilder connects the partial path results to build longer routes.
+// You must use NewRouteBuilder() function to obtain an instance of this type.
+//
+// It calls the pathfinder BuildPath method again and again, starting
+// from the previous result Finish coord, until the destination is reached.
+// If the partial results stop getting closer to the destination,
+// the building process is stopped and the route is marked as unreachable.
+//
+// Unlike BuildLongPath, this approach requires no extra memory,
+// but the route it builds can be suboptimal.
+//
+// Once created, you should re-use it to build routes.
+type RouteBuilder struct {
+	pathfinder  PathBuilder
+	maxSegments int
+	maxStalls   int
+
+	finishes []GridCoord
+}
+
+type RouteBuilderConfig struct {
+	// Pathfinder is used to build every route segment.
+	// This field is required.
+	Pathfinder PathBuilder
+
+	// MaxSegments limits the number of route segments (BuildPath calls).
+	// A route that hits this limit is partial, but it's not
+	// reported as unreachable.
+	//
+	// If left unset (0), a default value of 64 is used.
+	MaxSegments int
+
+	// MaxStalls is a number of consecutive segments that are allowed
+	// to end no closer to the destination than the previous best result.
+	// Once this limit is exceeded, the destination is considered to be unreachable.
+	//
+	// If left unset (0), a default value of 2 is used.
+	MaxStalls int
+}
+
+// Route is a sequence of connected path segments.
+//
+// The segments slice is re-used by the RouteBuilder,
+// so you want to keep the Route object around.
+type Route struct {
+	Segments []GridPath
+}
+
+// Len returns the total number of steps in all route segments.
+func (r *Route) Len() int {
+	n := 0
+	for i := range r.Segments {
+		n += r.Segments[i].Len()
+	}
+	return n
+}
+
+// AppendTo pushes all route steps to the end of dst path.
+func (r *Route) AppendTo(dst *LongGridPath) {
+	for _, p := range r.Segments {
+		p.Rewind()
+		for p.HasNext() {
+			dst.Push(p.Next())
+		}
+	}
+}
+
+// BuildRouteResult is a BuildRoute() method return value.
+type BuildRouteResult struct {
+	// Finish is where the constructed route ends.
+	Finish GridCoord
+
+	// Cost is a sum of all route segments costs.
+	Cost int
+
+	// Whether this is a partial route result.
+	// This happens if the destination is unreachable or
+	// if MaxSegments limit was reached.
+	Partial bool
+
+	// Unreachable is set if the route building was stopped
+	// due to the lack of progress.
+	// Either the destination can't be reached at all,
+	// or reaching it requires a detour that is too long for a
+	// single BuildPath call.
+	Unreachable bool
+}
+
+// NewRouteBuilder creates a ready-to-use RouteBuilder object.
+func NewRouteBuilder(config RouteBuilderConfig) *RouteBuilder {
+	if config.Pathfinder == nil {
+		panic("pathing: RouteBuilderConfig.Pathfinder is nil")
+	}
+	if config.MaxSegments == 0 {
+		config.MaxSegments = 64
+	}
+	if config.MaxStalls == 0 {
+		config.MaxStalls = 2
+	}
+
+	b := &RouteBuilder{
+		pathfinder:  config.Pathfinder,
+		maxSegments: config.MaxSegments,
+		maxStalls:   config.MaxStalls,
+		finishes:    make([]GridCoord, 0, 8),
+	}
+
+	return b
+}
+
+// BuildRoute attempts to build a route between the two coordinates.
+// See BuildPath method comment to learn about the Grid and GridLayer params.
+//
+// The route segments are written to dst; its previous contents are discarded.
+func (b *RouteBuilder) BuildRoute(g *Grid, from, to GridCoord, l GridLayer, dst *Route) BuildRouteResult {
+	var result BuildRouteResult
+	dst.Segments = dst.Segments[:0]
+
+	finishes := b.finishes[:0]
+	finishes = append(finishes, from)
+
+	pos := from
+	bestDist := from.Dist(to)
+	stalls := 0
+	for pos != to {
+		if len(dst.Segments) >= b.maxSegments {
+			result.Partial = true
+			break
+		}
+
+		segment := b.pathfinder.BuildPath(g, pos, to, l)
+		if segment.Steps.Len() == 0 {
+			// Can't move anywhere from here.
+			result.Partial = true
+			result.Unreachable = true
+			break
+		}
+		dst.Segments = append(dst.Segments, segment.Steps)
+		result.Cost += segment.Cost
+		pos = segment.Finish
+		if !segment.Partial {
+			break
+		}
+
+		if routeContains(finishes, pos) {
+			// We're going in circles.
+			result.Partial = true
+			result.Unreachable = true
+			break
+		}
+		finishes = append(finishes, pos)
+
+		if dist := pos.Dist(to); dist < bestDist {
+			bestDist = dist
+			stalls = 0
+		} else {
+			stalls++
+			if stalls > b.maxStalls {
+				result.Partial = true
+				result.Unreachable = true
+				break
+			}
+		}
+	}
+
+	result.Finish = pos
+	b.finishes = finishes[:0]
+
+	return result
+}
+
+func routeContains(coords []GridCoord, c GridCoord) bool {
+	for _, x := range coords {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package pathing_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+)
+
+func TestRouteBuilder(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	pathfinders := map[string]pathing.PathBuilder{
+		"astar": pathing.NewAStar(pathing.AStarConfig{}),
+		"bfs":   pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}),
+		"jps":   pathing.NewJPS(pathing.JPSConfig{}),
+	}
+
+	parseResult := testParseGrid(t, []string{
+		"A" + strings.Repeat(".", 298) + "B",
+	})
+	for name, pathfinder := range pathfinders {
+		b := pathing.NewRouteBuilder(pathing.RouteBuilderConfig{
+			Pathfinder: pathfinder,
+		})
+		var route pathing.Route
+		result := b.BuildRoute(parseResult.grid, parseResult.start, parseResult.dest, l, &route)
+		if result.Partial || result.Unreachable {
+			t.Fatalf("%s: unexpected partial result", name)
+		}
+		if result.Finish != parseResult.dest {
+			t.Fatalf("%s: finish mismatch:\nhave: %v\nwant: %v", name, result.Finish, parseResult.dest)
+		}
+		if route.Len() != 299 || result.Cost != 299 {
+			t.Fatalf("%s: expected 299 steps and cost, have %d steps and %d cost", name, route.Len(), result.Cost)
+		}
+		// JPS paths may contain the diagonal steps, so its segments are shorter.
+		wantSegments := 6
+		if name == "jps" {
+			wantSegments = 9
+		}
+		if len(route.Segments) != wantSegments {
+			t.Fatalf("%s: expected %d segments, have %d", name, wantSegments, len(route.Segments))
+		}
+
+		var p pathing.LongGridPath
+		route.AppendTo(&p)
+		pos := parseResult.start
+		for p.HasNext() {
+			pos = pos.Move(p.Next())
+		}
+		if pos != parseResult.dest {
+			t.Fatalf("%s: route ends at %v", name, pos)
+		}
+
+		allocs := testing.AllocsPerRun(5, func() {
+			b.BuildRoute(parseResult.grid, parseResult.start, parseResult.dest, l, &route)
+		})
+		if allocs != 0 {
+			t.Fatalf("%s: a route re-building allocates (%v allocs)", name, allocs)
+		}
+	}
+}
+
+func TestRouteBuilderUnreachable(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	tests := []struct {
+		name   string
+		path   []string
+		finish pathing.GridCoord
+	}{
+		{
+			name: "walled",
+			path: []string{
+				"A" + strings.Repeat(".", 100) + "x.B",
+			},
+			finish: pathing.GridCoord{X: 100},
+		},
+		{
+			name: "dead_end",
+			path: []string{
+				"......................................................................xxx",
+				"A.....................................................................x.B",
+				"......................................................................xxx",
+			},
+			finish: pathing.GridCoord{X: 69, Y: 1},
+		},
+	}
+
+	for _, test := range tests {
+		parseResult := testParseGrid(t, test.path)
+		b := pathing.NewRouteBuilder(pathing.RouteBuilderConfig{
+			Pathfinder: pathing.NewAStar(pathing.AStarConfig{}),
+		})
+		var route pathing.Route
+		result := b.BuildRoute(parseResult.grid, parseResult.start, parseResult.dest, l, &route)
+		if !result.Partial || !result.Unreachable {
+			t.Fatalf("%s: expected an unreachable result", test.name)
+		}
+		if result.Finish != test.finish {
+			t.Fatalf("%s: finish mismatch:\nhave: %v\nwant: %v", test.name, result.Finish, test.finish)
+		}
+	}
+}
+
+func TestRouteBuilderMaxSegments(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 0, 0, 0, 0})
+	parseResult := testParseGrid(t, []string{
+		"A" + strings.Repeat(".", 298) + "B",
+	})
+	b := pathing.NewRouteBuilder(pathing.RouteBuilderConfig{
+		Pathfinder:  pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}),
+		MaxSegments: 2,
+	})
+	var route pathing.Route
+	result := b.BuildRoute(parseResult.grid, parseResult.start, parseResult.dest, l, &route)
+	if !result.Partial || result.Unreachable {
+		t.Fatalf("expected a partial, but not unreachable result")
+	}
+	if len(route.Segments) != 2 || route.Len() != 112 {
+		t.Fatalf("expected 2 segments of 112 steps, have %d of %d steps", len(route.Segments), route.Len())
+	}
+	if want := (pathing.GridCoord{X: 112}); result.Finish != want {
+		t.Fatalf("finish mismatch:\nhave: %v\nwant: %v", result.Finish, want)
+	}
+}
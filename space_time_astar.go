@@ -0,0 +1,174 @@
+package pathing
+
+// spaceTimeAStar is a space-time A* search shared by CooperativeAStar and CBS.
+//
+// Every search state is a (cell, tick) pair: the agent can
+// move into one of the axial neighbors or wait in place.
+// The true distance to the goal (see FlowField) is used as a heuristic.
+type spaceTimeAStar struct {
+	frontier *minheap[spaceTimeCoord]
+
+	// These are indexed by the space-time state.
+	// A state is valid only if its stamp matches the current generation.
+	costs   []uint32
+	actions []uint8
+	stamps  []uint32
+	gen     uint32
+
+	// The states cover a box of cells around the search start.
+	// The agent can't get further than maxTicks steps away,
+	// so the box doesn't need to be bigger than 2*maxTicks+1 cells.
+	boxCols  int
+	boxRows  int
+	maxTicks int
+	origin   GridCoord
+}
+
+type spaceTimeCoord struct {
+	Coord GridCoord
+	Tick  int32
+	Cost  int32
+}
+
+// spaceTimeRules describe the agent-specific search constraints.
+type spaceTimeRules interface {
+	// canEnter reports whether the agent can move from one cell to another
+	// arriving there at the tick. A wait action has identical from and to.
+	canEnter(from, to GridCoord, tick int) bool
+
+	// canFinish reports whether the agent can stay at its goal since the tick.
+	canFinish(goal GridCoord, tick int) bool
+}
+
+func newSpaceTimeAStar(boxCols, boxRows, maxTicks int) *spaceTimeAStar {
+	numStates := boxCols * boxRows * (maxTicks + 1)
+	return &spaceTimeAStar{
+		frontier: newMinheap[spaceTimeCoord](64),
+		costs:    make([]uint32, numStates),
+		actions:  make([]uint8, numStates),
+		stamps:   make([]uint32, numStates),
+		boxCols:  boxCols,
+		boxRows:  boxRows,
+		maxTicks: maxTicks,
+	}
+}
+
+// search finds a path from one cell to another.
+// The step cost is the entered cell cost; every wait action costs 1.
+//
+// If windowed is true, the first state that reaches maxTicks is returned
+// (this is a partial result, unless it's at the goal).
+// Otherwise such states are never expanded.
+//
+// The path actions can be retrieved with actionAt.
+func (s *spaceTimeAStar) search(g *Grid, l GridLayer, from, to GridCoord, h *FlowField, rules spaceTimeRules, windowed bool) (spaceTimeCoord, bool) {
+	s.origin = GridCoord{
+		X: spaceTimeBoxStart(from.X, s.maxTicks, s.boxCols, int(g.numCols)),
+		Y: spaceTimeBoxStart(from.Y, s.maxTicks, s.boxRows, int(g.numRows)),
+	}
+
+	s.gen++
+	if s.gen == 0 {
+		// Avoid the collisions with the old stamps after an overflow.
+		for i := range s.stamps {
+			s.stamps[i] = 0
+		}
+		s.gen = 1
+	}
+
+	frontier := s.frontier
+	frontier.Reset()
+	frontier.Push(0, spaceTimeCoord{Coord: from})
+	s.setState(s.stateIndex(from, 0), 0, uint8(DirNone))
+
+	maxTicks := int32(s.maxTicks)
+	for !frontier.IsEmpty() {
+		current := frontier.Pop()
+
+		if current.Coord == to && rules.canFinish(to, int(current.Tick)) {
+			return current, true
+		}
+		if current.Tick == maxTicks {
+			if windowed {
+				return current, true
+			}
+			continue
+		}
+
+		i := s.stateIndex(current.Coord, int(current.Tick))
+		if s.costs[i] < uint32(current.Cost) {
+			// A cheaper way to this state was found after this node was pushed.
+			continue
+		}
+
+		nextTick := current.Tick + 1
+		for dir := DirRight; dir <= DirNone; dir++ {
+			if dir > DirUp && dir < DirNone {
+				// Only the axial moves and the wait action are allowed.
+				continue
+			}
+			next := current.Coord.Move(dir)
+			stepCost := uint32(1)
+			if dir != DirNone {
+				cellCost := g.GetCellCost(next, l)
+				if cellCost == 0 {
+					continue
+				}
+				stepCost = uint32(cellCost)
+			}
+			if !rules.canEnter(current.Coord, next, int(nextTick)) {
+				continue
+			}
+			dist, ok := h.Distance(next)
+			if !ok {
+				continue
+			}
+			newCost := uint32(current.Cost) + stepCost
+			j := s.stateIndex(next, int(nextTick))
+			if s.stamps[j] == s.gen && newCost >= s.costs[j] {
+				continue
+			}
+			s.setState(j, newCost, uint8(dir))
+			frontier.Push(int(newCost)+dist, spaceTimeCoord{
+				Coord: next,
+				Tick:  nextTick,
+				Cost:  int32(newCost),
+			})
+		}
+	}
+
+	return spaceTimeCoord{}, false
+}
+
+// actionAt returns the action that led to the (pos, tick) state
+// during the last search.
+func (s *spaceTimeAStar) actionAt(pos GridCoord, tick int) Direction {
+	return Direction(s.actions[s.stateIndex(pos, tick)])
+}
+
+func (s *spaceTimeAStar) setState(i int, cost uint32, action uint8) {
+	s.stamps[i] = s.gen
+	s.costs[i] = cost
+	s.actions[i] = action
+}
+
+func (s *spaceTimeAStar) stateIndex(pos GridCoord, tick int) int {
+	x := pos.X - s.origin.X
+	y := pos.Y - s.origin.Y
+	return (tick*s.boxRows+y)*s.boxCols + x
+}
+
+// spaceTimeBoxStart returns the first box cell position along one axis.
+// The box contains all grid cells that are reachable in maxTicks steps.
+func spaceTimeBoxStart(pos, maxTicks, boxSize, gridSize int) int {
+	start := pos - maxTicks
+	if start+boxSize > gridSize {
+		// The box is clamped by the grid border,
+		// so it can be shifted back.
+		start = gridSize - boxSize
+	}
+	if start < 0 {
+		start = 0
+	}
+	return start
+}
@@ -0,0 +1,195 @@
+package pathing
+
+import (
+	"math"
+)
+
+// ThetaStar implements a Theta* any-angle pathfinding algorithm.
+// You must use NewThetaStar() function to obtain an instance of this type.
+//
+// It's an A* variation that allows the path to go in any direction
+// as long as there is a line of sight (see Grid.HasLineOfSight).
+// The result is a list of waypoints instead of the grid steps.
+//
+// The layer is only used to check whether a cell is passable:
+// the tile costs are ignored and the path length is minimized instead.
+//
+// Like BuildLongPath, it's not limited by the GridPath max length
+// and it will search the entire grid if needed.
+//
+// Once created, you should re-use it to build paths.
+type ThetaStar struct {
+	frontier *minheap[thetaCoord]
+	costmap  *coordMap
+	pathmap  *coordMap
+}
+
+type ThetaStarConfig struct {
+	// NumCols and NumRows are size hints for the ThetaStar constructor.
+	// The working space is allocated for the entire grid, so it's
+	// a good idea to set these to Grid.NumCols() and Grid.NumRows().
+	// If the grid is bigger than that, the working space is re-allocated.
+	NumCols uint
+	NumRows uint
+}
+
+// AnyAnglePathResult is a ThetaStar.BuildPath() method return value.
+type AnyAnglePathResult struct {
+	// Waypoints are the world positions of the path corner cells centers.
+	// The first waypoint is the path start and the last one is the path finish.
+	Waypoints []Pos
+
+	// Finish is where the constructed path ends.
+	Finish GridCoord
+
+	// Length is a path length measured in cells.
+	Length float64
+
+	// Whether this is a partial path result.
+	// This only happens if the destination can't be reached.
+	Partial bool
+}
+
+type thetaCoord struct {
+	Coord GridCoord
+	Cost  uint32
+}
+
+// thetaCostScale is used to store the fractional
+// path lengths as integers.
+const thetaCostScale = 1024
+
+// NewThetaStar creates a ready-to-use ThetaStar object.
+func NewThetaStar(config ThetaStarConfig) *ThetaStar {
+	return &ThetaStar{
+		frontier: newMinheap[thetaCoord](64),
+		costmap:  newCoordMap(int(config.NumCols), int(config.NumRows)),
+		pathmap:  newCoordMap(int(config.NumCols), int(config.NumRows)),
+	}
+}
+
+// BuildPath attempts to find an any-angle path between the two coordinates.
+// See AStar.BuildPath comment to learn about the Grid and GridLayer params.
+//
+// The waypoints are appended to dst; the extended slice
+// is returned as a part of the result.
+//
+// If the destination is unreachable, a path to the
+// closest reachable cell is returned.
+func (theta *ThetaStar) BuildPath(g *Grid, from, to GridCoord, l GridLayer, dst []Pos) AnyAnglePathResult {
+	var result AnyAnglePathResult
+	if from == to || uint(from.X) >= g.numCols || uint(from.Y) >= g.numRows {
+		result.Waypoints = appendCellPos(g, dst, from)
+		result.Finish = from
+		result.Partial = from != to
+		return result
+	}
+
+	theta.costmap = resizeCoordMap(theta.costmap, int(g.numCols), int(g.numRows))
+	theta.pathmap = resizeCoordMap(theta.pathmap, int(g.numCols), int(g.numRows))
+	costmap := theta.costmap
+	pathmap := theta.pathmap
+	costmap.Reset()
+	pathmap.Reset()
+
+	frontier := theta.frontier
+	frontier.Reset()
+
+	wide := g.wideTags
+
+	startKey := pathmap.packCoord(from)
+	costmap.Set(startKey, 0)
+	pathmap.Set(startKey, uint32(startKey))
+	frontier.Push(0, thetaCoord{Coord: from})
+
+	found := false
+	fallbackCoord := from
+	fallbackDist := thetaDist(from, to)
+	for !frontier.IsEmpty() {
+		item := frontier.Pop()
+		current := item.Coord
+		currentKey := pathmap.packCoord(current)
+		currentCost, _ := costmap.Get(currentKey)
+		if item.Cost > currentCost {
+			// A stale frontier entry, this node was already
+			// reached via a cheaper path.
+			continue
+		}
+		if current == to {
+			found = true
+			break
+		}
+
+		if dist := thetaDist(current, to); dist < fallbackDist {
+			fallbackDist = dist
+			fallbackCoord = current
+		}
+
+		parentKey, _ := pathmap.Get(currentKey)
+		parent := pathmap.unpackCoord(uint(parentKey))
+		parentCost, _ := costmap.Get(uint(parentKey))
+		for dir, offset := range &neighborOffsets {
+			next := current.Add(offset)
+			cx := uint(next.X)
+			cy := uint(next.Y)
+			if cx >= g.numCols || cy >= g.numRows {
+				continue
+			}
+			if g.getTagCost(cx, cy, l) == 0 || wide && g.isBlockedCell(cx, cy) {
+				continue
+			}
+			if dir >= int(DirDownRight) && !g.canMoveDiagonally(uint(current.X), uint(current.Y), offset, l, false) {
+				continue
+			}
+
+			// Try to connect the next cell to the current cell parent directly.
+			newParentKey := parentKey
+			newCost := parentCost + thetaDist(parent, next)
+			if !g.HasLineOfSight(parent, next, l) {
+				newParentKey = uint32(currentKey)
+				newCost = currentCost + thetaDist(current, next)
+			}
+
+			k := pathmap.packCoord(next)
+			if oldCost, ok := costmap.Get(k); ok && newCost >= oldCost {
+				continue
+			}
+			costmap.Set(k, newCost)
+			pathmap.Set(k, newParentKey)
+			frontier.Push(int(newCost+thetaDist(next, to)), thetaCoord{Coord: next, Cost: newCost})
+		}
+	}
+
+	finish := to
+	if !found {
+		finish = fallbackCoord
+	}
+	finishCost, _ := costmap.Get(pathmap.packCoord(finish))
+
+	// Collect the waypoints in the reversed order and then reverse them.
+	start := len(dst)
+	for pos := finish; ; {
+		dst = appendCellPos(g, dst, pos)
+		if pos == from {
+			break
+		}
+		k, _ := pathmap.Get(pathmap.packCoord(pos))
+		pos = pathmap.unpackCoord(uint(k))
+	}
+	for i, j := start, len(dst)-1; i < j; i, j = i+1, j-1 {
+		dst[i], dst[j] = dst[j], dst[i]
+	}
+
+	result.Waypoints = dst
+	result.Finish = finish
+	result.Length = float64(finishCost) / thetaCostScale
+	result.Partial = !found
+
+	return result
+}
+
+func thetaDist(a, b GridCoord) uint32 {
+	dx := float64(a.X - b.X)
+	dy := float64(a.Y - b.Y)
+	return uint32(math.Sqrt(dx*dx+dy*dy)*thetaCostScale + 0.5)
+}
@@ -0,0 +1,146 @@
+package pathing_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+func BenchmarkThetaStar(b *testing.B) {
+	parseResult := testParseGrid(b, []string{
+		"A.........x.........",
+		"..........x.........",
+		"..........x.........",
+		"..........x.........",
+		"....................",
+		"..........x.........",
+		"..........x.........",
+		"..........x........B",
+	})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	theta := pathing.NewThetaStar(pathing.ThetaStarConfig{
+		NumCols: uint(parseResult.numCols),
+		NumRows: uint(parseResult.numRows),
+	})
+	buf := make([]pathing.Pos, 0, 8)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		theta.BuildPath(parseResult.grid, parseResult.start, parseResult.dest, l, buf[:0])
+	}
+}
+
+func TestThetaStar(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         []string
+		waypoints []pathing.GridCoord
+		partial   bool
+	}{
+		{
+			name: "straight",
+			m: []string{
+				"A....B",
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 5, Y: 0}},
+		},
+		{
+			name: "any_angle",
+			m: []string{
+				"A.......",
+				"........",
+				".......B",
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 7, Y: 2}},
+		},
+		{
+			name: "wall",
+			m: []string{
+				"A...x...",
+				"....x...",
+				"....x...",
+				"........",
+				"....x..B",
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 4, Y: 3}, {X: 7, Y: 4}},
+		},
+		{
+			name: "unreachable",
+			m: []string{
+				"A..x..",
+				"...x.B",
+				"...x..",
+			},
+			waypoints: []pathing.GridCoord{{X: 0, Y: 0}, {X: 2, Y: 1}},
+			partial:   true,
+		},
+	}
+
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	theta := pathing.NewThetaStar(pathing.ThetaStarConfig{})
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			parseResult := testParseGrid(t, test.m)
+			g := parseResult.grid
+			result := theta.BuildPath(g, parseResult.start, parseResult.dest, l, nil)
+			if result.Partial != test.partial {
+				t.Fatalf("partial flag mismatch:\nhave: %v\nwant: %v", result.Partial, test.partial)
+			}
+			want := make([]pathing.Pos, len(test.waypoints))
+			for i, c := range test.waypoints {
+				x, y := g.CoordToPos(c)
+				want[i] = pathing.Pos{X: x, Y: y}
+			}
+			if len(result.Waypoints) != len(want) {
+				t.Fatalf("waypoints mismatch:\nhave: %v\nwant: %v", result.Waypoints, want)
+			}
+			for i := range want {
+				if result.Waypoints[i] != want[i] {
+					t.Fatalf("waypoints mismatch:\nhave: %v\nwant: %v", result.Waypoints, want)
+				}
+			}
+			if result.Finish != test.waypoints[len(test.waypoints)-1] {
+				t.Fatalf("finish mismatch:\nhave: %v\nwant: %v", result.Finish, test.waypoints[len(test.waypoints)-1])
+			}
+		})
+	}
+}
+
+func TestThetaStarRandom(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 1, 1, 1, 1, 1, 1})
+	theta := pathing.NewThetaStar(pathing.ThetaStarConfig{})
+	astar := pathing.NewAStar(pathing.AStarConfig{Diagonal: true})
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var longPath pathing.LongGridPath
+	var buf []pathing.Pos
+	for i := 0; i < 300; i++ {
+		g, numCols, numRows := testRandomGrid(rng, 30)
+		from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+		g.SetCellTile(to, 0)
+
+		want := astar.BuildLongPath(g, from, to, l, &longPath)
+		result := theta.BuildPath(g, from, to, l, buf[:0])
+		buf = result.Waypoints
+		if result.Partial != want.Partial {
+			t.Fatalf("test%d: %v=>%v partial flag mismatch:\nhave: %v\nwant: %v", i, from, to, result.Partial, want.Partial)
+		}
+
+		length := 0.0
+		for j := 1; j < len(result.Waypoints); j++ {
+			a := g.PosToCoord(result.Waypoints[j-1].X, result.Waypoints[j-1].Y)
+			b := g.PosToCoord(result.Waypoints[j].X, result.Waypoints[j].Y)
+			if !g.HasLineOfSight(a, b, l) {
+				t.Fatalf("test%d: %v=>%v: no line of sight between %v and %v", i, from, to, a, b)
+			}
+			length += math.Hypot(float64(a.X-b.X), float64(a.Y-b.Y))
+		}
+		if math.Abs(length-result.Length) > 0.01 {
+			t.Fatalf("test%d: %v=>%v length mismatch:\nhave: %v\nwant: %v", i, from, to, result.Length, length)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="terrain" tilewidth="16" tileheight="8" tilecount="4" columns="4">
+ <image source="terrain.png" width="64" height="8"/>
+ <tile id="0">
+  <properties>
+   <property name="pathing" type="int" value="0"/>
+  </properties>
+ </tile>
+ <tile id="1">
+  <properties>
+   <property name="pathing" type="int" value="2"/>
+  </properties>
+ </tile>
+ <tile id="2">
+  <properties>
+   <property name="name" value="water"/>
+   <property name="pathing" type="int" value="1"/>
+  </properties>
+ </tile>
+</tileset>
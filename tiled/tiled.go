@@ -0,0 +1,280 @@
+// Package tiled implements a Tiled map editor files loader.
+//
+// It reads a tile layer from a .tmx (XML) or .tmj (JSON) map file
+// and converts it into a pathing.Grid.
+//
+// Only the orthogonal finite maps are supported.
+package tiled
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/quasilyte/pathing"
+)
+
+// Config is a LoadFile() function parameter.
+// See field comments for more details.
+type Config struct {
+	// Layer is a name of the tile layer to load.
+	// The layers inside the groups are considered too.
+	//
+	// If left unset (empty), the first tile layer is used.
+	Layer string
+
+	// GIDs maps the tile global IDs to the grid cell values.
+	//
+	// A cell value is the same as in pathing.ParseGridASCII legend:
+	// the lower bits are the tile tag and the pathing.BlockedCellFlag
+	// bit marks the cell as blocked (see pathing.IsValidCellValue).
+	//
+	// The GID flip flags are ignored: all flipped and
+	// rotated variants of a tile have the same GID.
+	//
+	// The empty cells (GID 0) are mapped to 0 unless
+	// this map contains an explicit GID 0 mapping.
+	GIDs map[uint32]uint8
+
+	// Property is a name of the custom tile property that
+	// specifies the grid cell value (see GIDs).
+	// The property should be an integer (or a string holding an integer).
+	//
+	// The property is only checked if the tile is not mapped via GIDs.
+	// If left unset (empty), the tile properties are ignored.
+	Property string
+
+	// WideTags enables the pathing.GridConfig WideTags mode
+	// for the loaded grid, so the tile tags can be in [0, 15] range.
+	WideTags bool
+}
+
+// Result is a LoadFile() function return value.
+type Result struct {
+	// Grid is the loaded grid.
+	Grid *pathing.Grid
+
+	// GridConfig is a config that was used to create the Grid.
+	// The cell size is taken from the map tile size.
+	GridConfig pathing.GridConfig
+
+	// UnmappedGIDs lists the tile GIDs that had no mapping.
+	// These cells get a value of 0.
+	// The GIDs are sorted and every GID is reported only once.
+	UnmappedGIDs []uint32
+}
+
+// LoadFile reads a tile layer from the Tiled map file.
+//
+// The file format is selected by its extension:
+// ".tmx" for XML and ".tmj" (or ".json") for JSON.
+// The external tilesets are loaded relative to the map file.
+func LoadFile(filename string, config Config) (*Result, error) {
+	for gid, v := range config.GIDs {
+		if !pathing.IsValidCellValue(v, config.WideTags) {
+			return nil, fmt.Errorf("tiled: GID %d value %d is not a valid cell value", gid, v)
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *tiledMap
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".tmx":
+		m, err = decodeTMX(data)
+	case ".tmj", ".json":
+		m, err = decodeTMJ(data)
+	default:
+		return nil, fmt.Errorf("tiled: %s: unsupported file extension %q", filename, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tiled: %s: %w", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	for i := range m.tilesets {
+		ts := &m.tilesets[i]
+		if ts.source == "" {
+			continue
+		}
+		if err := loadExternalTileset(filepath.Join(dir, ts.source), ts); err != nil {
+			return nil, fmt.Errorf("tiled: %s: %w", filename, err)
+		}
+	}
+
+	result, err := m.buildGrid(config)
+	if err != nil {
+		return nil, fmt.Errorf("tiled: %s: %w", filename, err)
+	}
+	return result, nil
+}
+
+// The GID bits that encode the tile flipping and rotation.
+const gidFlagsMask = 0xf0000000
+
+type tiledMap struct {
+	orientation string
+	infinite    bool
+	width       int
+	height      int
+	tileWidth   int
+	tileHeight  int
+
+	tilesets []tileset
+	layers   []tileLayer
+}
+
+type tileset struct {
+	firstGID uint32
+	source   string
+
+	// tiles maps a local tile ID to its properties.
+	tiles map[uint32][]property
+}
+
+type property struct {
+	name  string
+	value string
+}
+
+type tileLayer struct {
+	name string
+	gids []uint32
+}
+
+func loadExternalTileset(filename string, ts *tileset) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".tsx":
+		err = decodeTSX(data, ts)
+	case ".tsj", ".json":
+		err = decodeTSJ(data, ts)
+	default:
+		return fmt.Errorf("%s: unsupported tileset file extension %q", filename, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+func (m *tiledMap) buildGrid(config Config) (*Result, error) {
+	if m.orientation != "orthogonal" {
+		return nil, fmt.Errorf("unsupported %q map orientation", m.orientation)
+	}
+	if m.infinite {
+		return nil, fmt.Errorf("infinite maps are not supported")
+	}
+	if m.width <= 0 || m.height <= 0 || m.tileWidth <= 0 || m.tileHeight <= 0 {
+		return nil, fmt.Errorf("invalid %dx%d map with %dx%d tiles", m.width, m.height, m.tileWidth, m.tileHeight)
+	}
+
+	var layer *tileLayer
+	for i := range m.layers {
+		if config.Layer == "" || m.layers[i].name == config.Layer {
+			layer = &m.layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		if config.Layer == "" {
+			return nil, fmt.Errorf("no tile layers found")
+		}
+		return nil, fmt.Errorf("tile layer %q not found", config.Layer)
+	}
+	if len(layer.gids) != m.width*m.height {
+		return nil, fmt.Errorf("layer %q: expected %d tiles, found %d", layer.name, m.width*m.height, len(layer.gids))
+	}
+
+	sort.Slice(m.tilesets, func(i, j int) bool {
+		return m.tilesets[i].firstGID < m.tilesets[j].firstGID
+	})
+
+	result := &Result{
+		GridConfig: pathing.GridConfig{
+			WorldWidth:  uint(m.width * m.tileWidth),
+			WorldHeight: uint(m.height * m.tileHeight),
+			CellWidth:   uint(m.tileWidth),
+			CellHeight:  uint(m.tileHeight),
+			WideTags:    config.WideTags,
+		},
+	}
+	g := pathing.NewGrid(result.GridConfig)
+
+	// Most maps use only a few distinct tiles,
+	// so the mapping results are cached.
+	cache := make(map[uint32]uint8)
+	unmapped := make(map[uint32]struct{})
+	for i, gid := range layer.gids {
+		gid &^= gidFlagsMask
+		v, ok := cache[gid]
+		if !ok {
+			var mapped bool
+			var err error
+			v, mapped, err = m.mapGID(gid, config)
+			if err != nil {
+				return nil, fmt.Errorf("layer %q: %w", layer.name, err)
+			}
+			if !mapped {
+				unmapped[gid] = struct{}{}
+			}
+			cache[gid] = v
+		}
+		g.SetCellValue(pathing.GridCoord{X: i % m.width, Y: i / m.width}, v)
+	}
+
+	result.Grid = g
+	if len(unmapped) != 0 {
+		result.UnmappedGIDs = make([]uint32, 0, len(unmapped))
+		for gid := range unmapped {
+			result.UnmappedGIDs = append(result.UnmappedGIDs, gid)
+		}
+		sort.Slice(result.UnmappedGIDs, func(i, j int) bool {
+			return result.UnmappedGIDs[i] < result.UnmappedGIDs[j]
+		})
+	}
+	return result, nil
+}
+
+func (m *tiledMap) mapGID(gid uint32, config Config) (uint8, bool, error) {
+	if v, ok := config.GIDs[gid]; ok {
+		return v, true, nil
+	}
+	if gid == 0 {
+		// An empty cell.
+		return 0, true, nil
+	}
+	if config.Property == "" {
+		return 0, false, nil
+	}
+
+	// Tilesets are sorted by their first GID; find the last
+	// tileset that can contain this GID.
+	i := sort.Search(len(m.tilesets), func(i int) bool {
+		return m.tilesets[i].firstGID > gid
+	}) - 1
+	if i < 0 {
+		return 0, false, nil
+	}
+	ts := &m.tilesets[i]
+	for _, p := range ts.tiles[gid-ts.firstGID] {
+		if p.name != config.Property {
+			continue
+		}
+		v, err := strconv.ParseUint(p.value, 10, 8)
+		if err != nil || !pathing.IsValidCellValue(uint8(v), config.WideTags) {
+			return 0, false, fmt.Errorf("GID %d: %q property value %q is not a valid cell value", gid, p.name, p.value)
+		}
+		return uint8(v), true, nil
+	}
+	return 0, false, nil
+}
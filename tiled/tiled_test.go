@@ -0,0 +1,224 @@
+package tiled_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/pathing"
+	"github.com/quasilyte/pathing/tiled"
+)
+
+var testLegend = map[rune]uint8{
+	'.': 0,
+	'w': 1,
+	'f': 2,
+	'r': 3,
+	'#': 1 | pathing.BlockedCellFlag,
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		layer    string
+		config   tiled.Config
+		want     []string
+		unmapped []uint32
+	}{
+		{
+			filename: "level.tmx",
+			config: tiled.Config{
+				GIDs:     map[uint32]uint8{4: 3},
+				Property: "pathing",
+			},
+			want: []string{
+				"..ff..",
+				".wwf..",
+				"...#..",
+				"rr....",
+			},
+			unmapped: []uint32{7},
+		},
+		{
+			filename: "level.tmx",
+			config: tiled.Config{
+				Layer: "ground_zlib",
+				GIDs:  map[uint32]uint8{1: 0, 2: 2},
+			},
+			want: []string{
+				"..ff..",
+				"...f..",
+				"......",
+				"......",
+			},
+			unmapped: []uint32{3, 4, 6, 7},
+		},
+		{
+			filename: "level.tmx",
+			config: tiled.Config{
+				Layer:    "ground",
+				GIDs:     map[uint32]uint8{0: 1, 2: 3},
+				Property: "pathing",
+			},
+			want: []string{
+				"..rr..",
+				".wwr..",
+				"...#..",
+				"...w..",
+			},
+			unmapped: []uint32{4, 7},
+		},
+		{
+			filename: "level.tmj",
+			config: tiled.Config{
+				GIDs:     map[uint32]uint8{4: 3},
+				Property: "pathing",
+			},
+			want: []string{
+				"..ff..",
+				".wwf..",
+				"...#..",
+				"rr....",
+			},
+			unmapped: []uint32{7},
+		},
+		{
+			filename: "level.tmj",
+			config: tiled.Config{
+				Layer:    "ground_gzip",
+				Property: "pathing",
+			},
+			want: []string{
+				"..ff..",
+				".wwf..",
+				"...#..",
+				"......",
+			},
+			unmapped: []uint32{4, 7},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.filename+"/"+test.config.Layer, func(t *testing.T) {
+			result, err := tiled.LoadFile(filepath.Join("testdata", test.filename), test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have := strings.Join(result.Grid.FormatASCII(testLegend), "\n")
+			want := strings.Join(test.want, "\n")
+			if have != want {
+				t.Fatalf("grid mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+			}
+			if !reflect.DeepEqual(result.UnmappedGIDs, test.unmapped) {
+				t.Fatalf("unmapped GIDs mismatch:\nhave: %v\nwant: %v", result.UnmappedGIDs, test.unmapped)
+			}
+			wantConfig := pathing.GridConfig{
+				WorldWidth:  6 * 16,
+				WorldHeight: 4 * 8,
+				CellWidth:   16,
+				CellHeight:  8,
+			}
+			if result.GridConfig != wantConfig {
+				t.Fatalf("grid config mismatch:\nhave: %+v\nwant: %+v", result.GridConfig, wantConfig)
+			}
+			if x, y := result.Grid.CoordToPos(pathing.GridCoord{X: 1, Y: 1}); x != 24 || y != 12 {
+				t.Fatalf("cell size mismatch: (1,1) pos is %v,%v", x, y)
+			}
+		})
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	const tmxTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<map orientation="%ORIENTATION%" width="2" height="1" tilewidth="16" tileheight="16" infinite="%INFINITE%">
+ <tileset firstgid="1">
+  <tile id="0"><properties><property name="pathing" value="%VALUE%"/></properties></tile>
+ </tileset>
+ <layer name="ground" width="2" height="1">
+  <data encoding="%ENCODING%">%DATA%</data>
+ </layer>
+</map>
+`
+	tests := []struct {
+		name     string
+		filename string
+		replacer *strings.Replacer
+		config   tiled.Config
+		err      string
+	}{
+		{
+			name:     "bad_extension",
+			filename: "map.txt",
+			err:      `unsupported file extension ".txt"`,
+		},
+		{
+			name:   "missing_layer",
+			config: tiled.Config{Layer: "walls"},
+			err:    `tile layer "walls" not found`,
+		},
+		{
+			name:     "isometric",
+			replacer: strings.NewReplacer("%ORIENTATION%", "isometric"),
+			err:      `unsupported "isometric" map orientation`,
+		},
+		{
+			name:     "infinite",
+			replacer: strings.NewReplacer("%INFINITE%", "1"),
+			err:      `infinite maps are not supported`,
+		},
+		{
+			name:     "bad_property",
+			replacer: strings.NewReplacer("%VALUE%", "wall"),
+			config:   tiled.Config{Property: "pathing"},
+			err:      `layer "ground": GID 1: "pathing" property value "wall" is not a valid cell value`,
+		},
+		{
+			name:     "bad_data_size",
+			replacer: strings.NewReplacer("%DATA%", "1,1,1"),
+			err:      `layer "ground": expected 2 tiles, found 3`,
+		},
+		{
+			name:     "bad_encoding",
+			replacer: strings.NewReplacer("%ENCODING%", "hex"),
+			err:      `layer "ground": unsupported "hex" data encoding`,
+		},
+		{
+			name:   "bad_gid_value",
+			config: tiled.Config{GIDs: map[uint32]uint8{1: 16}},
+			err:    `GID 1 value 16 is not a valid cell value`,
+		},
+	}
+
+	defaults := strings.NewReplacer(
+		"%ORIENTATION%", "orthogonal",
+		"%INFINITE%", "0",
+		"%VALUE%", "1",
+		"%ENCODING%", "csv",
+		"%DATA%", "1,0",
+	)
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			data := tmxTemplate
+			if test.replacer != nil {
+				data = test.replacer.Replace(data)
+			}
+			data = defaults.Replace(data)
+			filename := test.filename
+			if filename == "" {
+				filename = "map.tmx"
+			}
+			filename = filepath.Join(t.TempDir(), filename)
+			if err := os.WriteFile(filename, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := tiled.LoadFile(filename, test.config)
+			if err == nil || !strings.HasSuffix(err.Error(), test.err) {
+				t.Fatalf("error mismatch:\nhave: %v\nwant: ...%s", err, test.err)
+			}
+		})
+	}
+}
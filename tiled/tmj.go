@@ -0,0 +1,148 @@
+package tiled
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type jsonMap struct {
+	Orientation string        `json:"orientation"`
+	Infinite    bool          `json:"infinite"`
+	Width       int           `json:"width"`
+	Height      int           `json:"height"`
+	TileWidth   int           `json:"tilewidth"`
+	TileHeight  int           `json:"tileheight"`
+	Tilesets    []jsonTileset `json:"tilesets"`
+	Layers      []jsonLayer   `json:"layers"`
+}
+
+type jsonTileset struct {
+	FirstGID uint32     `json:"firstgid"`
+	Source   string     `json:"source"`
+	Tiles    []jsonTile `json:"tiles"`
+}
+
+type jsonTile struct {
+	ID         uint32         `json:"id"`
+	Properties []jsonProperty `json:"properties"`
+}
+
+type jsonProperty struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+type jsonLayer struct {
+	Type        string          `json:"type"`
+	Name        string          `json:"name"`
+	Encoding    string          `json:"encoding"`
+	Compression string          `json:"compression"`
+	Data        json.RawMessage `json:"data"`
+	Chunks      json.RawMessage `json:"chunks"`
+
+	// Only for the group layers.
+	Layers []jsonLayer `json:"layers"`
+}
+
+func decodeTMJ(data []byte) (*tiledMap, error) {
+	var j jsonMap
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		orientation: j.Orientation,
+		infinite:    j.Infinite,
+		width:       j.Width,
+		height:      j.Height,
+		tileWidth:   j.TileWidth,
+		tileHeight:  j.TileHeight,
+	}
+	for _, jts := range j.Tilesets {
+		ts := tileset{firstGID: jts.FirstGID, source: jts.Source}
+		ts.addJSONTiles(jts.Tiles)
+		m.tilesets = append(m.tilesets, ts)
+	}
+	if err := m.addJSONLayers(j.Layers); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func decodeTSJ(data []byte, ts *tileset) error {
+	var j jsonTileset
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	ts.addJSONTiles(j.Tiles)
+	return nil
+}
+
+func (ts *tileset) addJSONTiles(tiles []jsonTile) {
+	for _, t := range tiles {
+		if len(t.Properties) == 0 {
+			continue
+		}
+		if ts.tiles == nil {
+			ts.tiles = make(map[uint32][]property)
+		}
+		props := make([]property, len(t.Properties))
+		for i, p := range t.Properties {
+			props[i] = property{name: p.Name, value: jsonPropertyValue(p.Value)}
+		}
+		ts.tiles[t.ID] = props
+	}
+}
+
+func jsonPropertyValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (m *tiledMap) addJSONLayers(layers []jsonLayer) error {
+	for _, l := range layers {
+		switch l.Type {
+		case "tilelayer":
+			gids, err := decodeJSONData(l)
+			if err != nil {
+				return fmt.Errorf("layer %q: %w", l.Name, err)
+			}
+			m.layers = append(m.layers, tileLayer{name: l.Name, gids: gids})
+		case "group":
+			if err := m.addJSONLayers(l.Layers); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeJSONData(l jsonLayer) ([]uint32, error) {
+	if len(l.Chunks) != 0 {
+		return nil, fmt.Errorf("chunked layer data is not supported")
+	}
+	switch l.Encoding {
+	case "", "csv":
+		var gids []uint32
+		if err := json.Unmarshal(l.Data, &gids); err != nil {
+			return nil, fmt.Errorf("decode data: %w", err)
+		}
+		return gids, nil
+	case "base64":
+		var s string
+		if err := json.Unmarshal(l.Data, &s); err != nil {
+			return nil, fmt.Errorf("decode data: %w", err)
+		}
+		return decodeBase64(s, l.Compression)
+	default:
+		return nil, fmt.Errorf("unsupported %q data encoding", l.Encoding)
+	}
+}
@@ -0,0 +1,263 @@
+package tiled
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type xmlMap struct {
+	Orientation string
+	Infinite    bool
+	Width       int
+	Height      int
+	TileWidth   int
+	TileHeight  int
+	Tilesets    []xmlTileset
+
+	// Layers are collected in the document order,
+	// the layers inside the groups are included.
+	Layers []xmlLayer
+}
+
+func (x *xmlMap) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if start.Name.Local != "map" {
+		return fmt.Errorf("expected a map element, found %s", start.Name.Local)
+	}
+	for _, attr := range start.Attr {
+		var err error
+		switch attr.Name.Local {
+		case "orientation":
+			x.Orientation = attr.Value
+		case "infinite":
+			x.Infinite = attr.Value == "1"
+		case "width":
+			x.Width, err = strconv.Atoi(attr.Value)
+		case "height":
+			x.Height, err = strconv.Atoi(attr.Value)
+		case "tilewidth":
+			x.TileWidth, err = strconv.Atoi(attr.Value)
+		case "tileheight":
+			x.TileHeight, err = strconv.Atoi(attr.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("map %s attribute: %w", attr.Name.Local, err)
+		}
+	}
+
+	// The encoding/xml struct tags can't preserve the order of
+	// the layers and groups, so the elements are traversed manually.
+	return decodeXMLLayers(d, &x.Layers, func(start xml.StartElement) error {
+		if start.Name.Local != "tileset" {
+			return d.Skip()
+		}
+		var ts xmlTileset
+		if err := d.DecodeElement(&ts, &start); err != nil {
+			return err
+		}
+		x.Tilesets = append(x.Tilesets, ts)
+		return nil
+	})
+}
+
+// decodeXMLLayers collects the layer elements until the current element ends.
+// The groups are traversed recursively.
+// All other child elements are passed to the visitOther callback.
+func decodeXMLLayers(d *xml.Decoder, dst *[]xmlLayer, visitOther func(start xml.StartElement) error) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "layer":
+				var l xmlLayer
+				if err := d.DecodeElement(&l, &tok); err != nil {
+					return err
+				}
+				*dst = append(*dst, l)
+			case "group":
+				if err := decodeXMLLayers(d, dst, func(xml.StartElement) error { return d.Skip() }); err != nil {
+					return err
+				}
+			default:
+				if err := visitOther(tok); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type xmlTileset struct {
+	FirstGID uint32    `xml:"firstgid,attr"`
+	Source   string    `xml:"source,attr"`
+	Tiles    []xmlTile `xml:"tile"`
+}
+
+type xmlTile struct {
+	ID         uint32        `xml:"id,attr"`
+	Properties []xmlProperty `xml:"properties>property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type xmlLayer struct {
+	Name string  `xml:"name,attr"`
+	Data xmlData `xml:"data"`
+}
+
+type xmlData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	Chunks []struct{} `xml:"chunk"`
+	Text   string     `xml:",chardata"`
+}
+
+func decodeTMX(data []byte) (*tiledMap, error) {
+	var x xmlMap
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return nil, err
+	}
+
+	m := &tiledMap{
+		orientation: x.Orientation,
+		infinite:    x.Infinite,
+		width:       x.Width,
+		height:      x.Height,
+		tileWidth:   x.TileWidth,
+		tileHeight:  x.TileHeight,
+	}
+	for _, xts := range x.Tilesets {
+		ts := tileset{firstGID: xts.FirstGID, source: xts.Source}
+		ts.addXMLTiles(xts.Tiles)
+		m.tilesets = append(m.tilesets, ts)
+	}
+
+	for _, l := range x.Layers {
+		gids, err := decodeXMLData(l.Data)
+		if err != nil {
+			return nil, fmt.Errorf("layer %q: %w", l.Name, err)
+		}
+		m.layers = append(m.layers, tileLayer{name: l.Name, gids: gids})
+	}
+
+	return m, nil
+}
+
+func decodeTSX(data []byte, ts *tileset) error {
+	var x xmlTileset
+	if err := xml.Unmarshal(data, &x); err != nil {
+		return err
+	}
+	ts.addXMLTiles(x.Tiles)
+	return nil
+}
+
+func (ts *tileset) addXMLTiles(tiles []xmlTile) {
+	for _, t := range tiles {
+		if len(t.Properties) == 0 {
+			continue
+		}
+		if ts.tiles == nil {
+			ts.tiles = make(map[uint32][]property)
+		}
+		props := make([]property, len(t.Properties))
+		for i, p := range t.Properties {
+			props[i] = property{name: p.Name, value: p.Value}
+		}
+		ts.tiles[t.ID] = props
+	}
+}
+
+func decodeXMLData(data xmlData) ([]uint32, error) {
+	if len(data.Chunks) != 0 {
+		return nil, fmt.Errorf("chunked layer data is not supported")
+	}
+	switch data.Encoding {
+	case "":
+		gids := make([]uint32, len(data.Tiles))
+		for i, t := range data.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	case "csv":
+		return decodeCSV(data.Text)
+	case "base64":
+		return decodeBase64(data.Text, data.Compression)
+	default:
+		return nil, fmt.Errorf("unsupported %q data encoding", data.Encoding)
+	}
+}
+
+func decodeCSV(s string) ([]uint32, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	gids := make([]uint32, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(p), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("decode csv data: %w", err)
+		}
+		gids[i] = uint32(v)
+	}
+	return gids, nil
+}
+
+func decodeBase64(s, compression string) ([]uint32, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("decode base64 data: %w", err)
+	}
+
+	var r io.ReadCloser
+	switch compression {
+	case "":
+		// Not compressed.
+	case "zlib":
+		r, err = zlib.NewReader(bytes.NewReader(raw))
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(raw))
+	default:
+		return nil, fmt.Errorf("unsupported %q data compression", compression)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decompress %s data: %w", compression, err)
+	}
+	if r != nil {
+		raw, err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decompress %s data: %w", compression, err)
+		}
+	}
+
+	if len(raw)%4 != 0 {
+		return nil, fmt.Errorf("decode base64 data: %d bytes is not a multiple of 4", len(raw))
+	}
+	gids := make([]uint32, len(raw)/4)
+	for i := range gids {
+		gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+	return gids, nil
+}
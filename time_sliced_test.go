@@ -0,0 +1,117 @@
+package pathing_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/quasilyte/pathing"
+)
+
+type timeSlicedPathfinder interface {
+	BuildLongPath(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer, dst *pathing.LongGridPath) pathing.BuildLongPathResult
+	Start(g *pathing.Grid, from, to pathing.GridCoord, l pathing.GridLayer)
+	Step(maxNodes int) bool
+	Result(dst *pathing.LongGridPath) pathing.BuildLongPathResult
+}
+
+func TestTimeSlicedMatchesLongPath(t *testing.T) {
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	pathfinders := []struct {
+		name string
+		impl timeSlicedPathfinder
+	}{
+		{"astar", pathing.NewAStar(pathing.AStarConfig{})},
+		{"astar_diagonal", pathing.NewAStar(pathing.AStarConfig{Diagonal: true})},
+		{"bfs", pathing.NewGreedyBFS(pathing.GreedyBFSConfig{})},
+		{"bfs_diagonal", pathing.NewGreedyBFS(pathing.GreedyBFSConfig{Diagonal: true})},
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, pf := range pathfinders {
+		var want pathing.LongGridPath
+		var have pathing.LongGridPath
+		for i := 0; i < 100; i++ {
+			g, numCols, numRows := testRandomGrid(rng, 60)
+			from := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			to := pathing.GridCoord{X: rng.Intn(numCols), Y: rng.Intn(numRows)}
+			wantResult := pf.impl.BuildLongPath(g, from, to, l, &want)
+
+			pf.impl.Start(g, from, to, l)
+			maxNodes := rng.Intn(20) + 1
+			numSteps := 0
+			for !pf.impl.Step(maxNodes) {
+				numSteps++
+				// An intermediate result is always a valid partial path.
+				partial := pf.impl.Result(&have)
+				if !partial.Partial {
+					t.Fatalf("%s: test%d: %v=>%v: unfinished search result is not partial", pf.name, i, from, to)
+				}
+				checkLongPath(t, g, l, from, partial, &have)
+			}
+			if !pf.impl.Step(maxNodes) {
+				t.Fatalf("%s: test%d: Step after the search is done returned false", pf.name, i)
+			}
+			haveResult := pf.impl.Result(&have)
+			if haveResult != wantResult {
+				t.Fatalf("%s: test%d: %v=>%v result mismatch (%d steps):\nhave: %+v\nwant: %+v", pf.name, i, from, to, numSteps, haveResult, wantResult)
+			}
+			if have.String() != want.String() {
+				t.Fatalf("%s: test%d: %v=>%v path mismatch:\nhave: %v\nwant: %v", pf.name, i, from, to, have, want)
+			}
+		}
+	}
+}
+
+func TestMaxExpandedNodes(t *testing.T) {
+	parseResult := testParseGrid(t, []string{
+		"A.........",
+		"xxxxxxxxx.",
+		"$.........",
+	})
+	l := pathing.MakeGridLayer([8]uint8{1, 0, 2, 3, 0, 0, 0, 0})
+	g := parseResult.grid
+	from := parseResult.start
+	to := parseResult.dest
+
+	pathfinders := []struct {
+		name    string
+		limited timeSlicedPathfinder
+		full    timeSlicedPathfinder
+	}{
+		{
+			"astar",
+			pathing.NewAStar(pathing.AStarConfig{MaxExpandedNodes: 5}),
+			pathing.NewAStar(pathing.AStarConfig{}),
+		},
+		{
+			"bfs",
+			pathing.NewGreedyBFS(pathing.GreedyBFSConfig{MaxExpandedNodes: 5}),
+			pathing.NewGreedyBFS(pathing.GreedyBFSConfig{}),
+		},
+	}
+
+	var path pathing.LongGridPath
+	for _, pf := range pathfinders {
+		result := pf.full.BuildLongPath(g, from, to, l, &path)
+		if result.Partial || result.Cost != 20 {
+			t.Fatalf("%s: unexpected full search result: %+v", pf.name, result)
+		}
+
+		result = pf.limited.BuildLongPath(g, from, to, l, &path)
+		if !result.Partial {
+			t.Fatalf("%s: expected a partial result, got %+v", pf.name, result)
+		}
+		checkLongPath(t, g, l, from, result, &path)
+		if path.Len() > 5 {
+			t.Fatalf("%s: the path %s is too long for 5 expanded nodes", pf.name, path.String())
+		}
+	}
+
+	// Result before Start is an empty partial path.
+	astar := pathing.NewAStar(pathing.AStarConfig{})
+	result := astar.Result(&path)
+	if !result.Partial || path.Len() != 0 {
+		t.Fatalf("unexpected result for a search that was never started: %+v", result)
+	}
+}